@@ -2,9 +2,9 @@ package app
 
 import (
 	"api/internal/config"
-	"api/internal/domain"
 	"api/internal/domain/repository"
 	"api/internal/handler"
+	"api/internal/metrics"
 	repo "api/internal/repository"
 	"api/internal/usecase"
 	"log"
@@ -19,16 +19,13 @@ type AppContext struct {
 	UseCases     *usecase.UseCases
 	Handlers     *handler.Handlers
 	Middleware   *Middleware
-	Services     []domain.ServiceData
+	Metrics      *metrics.Metrics
+	ServiceStore repository.ServiceStore
 }
 
-func Initialize() *AppContext {
-	cfg, err := config.LoadConfig()
-	if err != nil {
-		log.Fatal(err)
-	}
-
+func Initialize(cfg *config.Config) *AppContext {
 	var logger *zap.Logger
+	var err error
 	if cfg.IsDev() {
 		logger, err = zap.NewDevelopment()
 	} else {
@@ -38,43 +35,22 @@ func Initialize() *AppContext {
 		log.Fatalf("failed to initialize zap logger: %v", err)
 	}
 
-	// 開発環境ではキャッシュを無効化してファイル変更を即座に反映
-	if cfg.IsDev() {
-		domain.SetCacheEnabled(false)
-		logger.Info("開発環境: サービスデータのキャッシュを無効化しました")
-	}
-
-	// サービスデータの読み込み
-	services, err := domain.LoadServiceData(cfg.GetDataDir())
-	if err != nil {
-		logger.Error("サービスデータの読み込みに失敗しました", zap.Error(err))
-	} else {
-		logger.Info("サービスデータを読み込みました",
-			zap.Int("サービス数", len(services)),
-			zap.String("データディレクトリ", cfg.GetDataDir()))
-
-		// 各サービスの詳細をデバッグレベルでログに出力
-		for _, service := range services {
-			logger.Debug("サービス詳細",
-				zap.String("ID", service.ID),
-				zap.String("名前", service.Name),
-				zap.Int("セグメント数", len(service.ParsedSegments)))
-		}
-	}
-
 	busStopRepository := repo.NewBusStopRepositoryImpl(cfg)
-	serviceRepository := repo.NewServiceRepositoryImpl(cfg, logger)
+	// サービスデータは ServiceStore が持ち、usecase の Reload で読み込む
+	serviceStore := repo.NewServiceStoreImpl(cfg, logger)
 
 	repositories := repository.Repositories{
 		BusStop: busStopRepository,
-		Service: serviceRepository,
+		Service: serviceStore,
 	}
 
-	useCases := usecase.NewUseCases(&repositories, logger)
+	useCases := usecase.NewUseCases(&repositories, cfg, logger)
+
+	handlers := handler.NewHandlers(useCases, cfg.Location)
 
-	handlers := handler.NewHandlers(useCases)
+	appMetrics := metrics.New(useCases.BusStop, cfg.Location)
 
-	middleware := NewMiddleware(logger)
+	middleware := NewMiddleware(logger, useCases.BusStop, appMetrics)
 
 	return &AppContext{
 		Config:       cfg,
@@ -83,6 +59,7 @@ func Initialize() *AppContext {
 		UseCases:     useCases,
 		Handlers:     handlers,
 		Middleware:   middleware,
-		Services:     services,
+		Metrics:      appMetrics,
+		ServiceStore: serviceStore,
 	}
 }
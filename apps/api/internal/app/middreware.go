@@ -2,11 +2,27 @@ package app
 
 import (
 	"api/internal/domain"
+	"api/internal/metrics"
+	"api/internal/reqctx"
+	"api/internal/usecase"
 	"api/pkg/oapi"
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
 
@@ -15,78 +31,352 @@ import (
 
 type Middleware struct {
 	log *zap.Logger
+	// busStop はアクセスログに出すデータセットのバージョンの取得に使います
+	busStop usecase.BusStopUseCase
+	metrics *metrics.Metrics
 }
 
-func NewMiddleware(logger *zap.Logger) *Middleware {
+func NewMiddleware(logger *zap.Logger, busStop usecase.BusStopUseCase, m *metrics.Metrics) *Middleware {
 	return &Middleware{
-		log: logger,
+		log:     logger,
+		busStop: busStop,
+		metrics: m,
 	}
 }
 
+// validRequestID はクライアントから受け取った X-Request-Id をそのまま使ってよいかの判定に使います
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// RequestIDMiddleware はリクエスト ID を決めて context とレスポンスヘッダーに設定します。
+// クライアントが X-Request-Id を送ってきた場合はその値を引き継ぎます
+func (m *Middleware) RequestIDMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		id := c.Request().Header.Get(echo.HeaderXRequestID)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+		c.Response().Header().Set(echo.HeaderXRequestID, id)
+		c.SetRequest(c.Request().WithContext(reqctx.WithRequestID(c.Request().Context(), id)))
+		return next(c)
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
+
+// MetricsMiddleware はルート（テンプレート）ごとのレスポンス時間を記録します。
+// エラーレスポンスのステータスを記録するため AccessLogMiddleware より外側で使います
+func (m *Middleware) MetricsMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+		err := next(c)
+		route := c.Path()
+		if route == "" {
+			route = "unmatched"
+		}
+		m.metrics.RequestDuration.
+			WithLabelValues(route, c.Request().Method, strconv.Itoa(c.Response().Status)).
+			Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+// AccessLogMiddleware はリクエストごとに 1 行のアクセスログを出力します
+func (m *Middleware) AccessLogMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+		if err := next(c); err != nil {
+			// エラーレスポンスを書き出してからステータスを記録する
+			c.Error(err)
+		}
+
+		req, res := c.Request(), c.Response()
+		fields := []zap.Field{
+			zap.String("request_id", reqctx.RequestID(req.Context())),
+			zap.String("method", req.Method),
+			zap.String("route", c.Path()),
+			zap.String("uri", req.RequestURI),
+			zap.Int("status", res.Status),
+			zap.Duration("latency", time.Since(start)),
+			zap.Int64("bytes_out", res.Size),
+			zap.String("remote_ip", c.RealIP()),
+			zap.String("user_agent", req.UserAgent()),
+			zap.String("dataset_version", m.busStop.DatasetVersion(req.Context())),
+		}
+		if res.Status >= http.StatusInternalServerError {
+			m.log.Error("access", fields...)
+		} else {
+			m.log.Info("access", fields...)
+		}
+		return nil
+	}
+}
+
+// ErrorHandlingMiddleware はハンドラーが返したエラーを共通のエラーレスポンスに変換します
 func (m *Middleware) ErrorHandlingMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		err := next(c)
 		if err == nil {
 			return nil
 		}
+		return m.writeError(c, err)
+	}
+}
 
-		var notFoundErr *domain.NotFoundError
-		if errors.As(err, &notFoundErr) {
-			m.log.Warn("not found error", zap.Error(err))
-			return c.JSON(http.StatusNotFound, map[string]string{
-				"code":    "NotFound",
-				"message": "Resource not found",
-			})
-		}
+// HTTPErrorHandler は ErrorHandlingMiddleware の外側（Recover などのミドルウェア）で
+// 発生したエラーも同じ形式で返すための echo.HTTPErrorHandler です
+func (m *Middleware) HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+	if err := m.writeError(c, err); err != nil {
+		m.log.Error("failed to write error response", zap.Error(err))
+	}
+}
 
-		var svcErr *domain.ServiceError
-		if errors.As(err, &svcErr) {
-			m.log.Error("service error", zap.String("code", svcErr.Code), zap.String("message", svcErr.Message))
-			return c.JSON(http.StatusInternalServerError, svcErr)
-		}
+// writeError は err を domain.Error に変換し、ログに出力してエラーレスポンスを返します
+func (m *Middleware) writeError(c echo.Context, err error) error {
+	domainErr := toDomainError(err)
 
-		// Handle OpenAPI validation errors (echo.HTTPError)
-		var httpErr *echo.HTTPError
-		if errors.As(err, &httpErr) {
-			m.log.Warn("OpenAPI request validation error",
-				zap.Int("status", httpErr.Code),
-				zap.Any("validator_message_payload", httpErr.Message), // Log the raw payload from the validator
-				zap.String("original_error_string", err.Error()))
-
-			var errorMessageString string
-			if msgStr, ok := httpErr.Message.(string); ok {
-				errorMessageString = msgStr
-			} else {
-				errorMessageString = fmt.Sprintf("%v", httpErr.Message) // Fallback if not a string
-			}
+	fields := []zap.Field{
+		zap.Int("status", domainErr.Status),
+		zap.String("code", string(domainErr.Code)),
+		zap.String("field", domainErr.Field),
+		zap.String("path", c.Request().URL.Path),
+		zap.String("request_id", requestID(c)),
+		zap.Error(err),
+	}
+	if domainErr.Status >= http.StatusInternalServerError {
+		m.log.Error("internal error", fields...)
+	} else {
+		m.log.Warn("request error", fields...)
+	}
 
-			// Return a structured JSON error response
-			responseBody := map[string]string{
-				"code":    http.StatusText(httpErr.Code), // e.g., "Bad Request"
-				"message": errorMessageString,
-			}
-			return c.JSON(httpErr.Code, responseBody)
+	m.metrics.Errors.WithLabelValues(string(domainErr.Code)).Inc()
+
+	lang := preferredLanguage(c.Request().Header.Get("Accept-Language"))
+	res := oapi.ErrorsErrorResponse{
+		Status:    int32(domainErr.Status),
+		Code:      oapi.ErrorsErrorCode(domainErr.Code),
+		Message:   domainErr.Code.Message(lang),
+		RequestId: requestID(c),
+	}
+	if domainErr.Field != "" {
+		res.Field = &domainErr.Field
+	}
+	c.Response().Header().Set("Content-Language", lang)
+	if c.Request().Method == http.MethodHead {
+		return c.NoContent(domainErr.Status)
+	}
+	return c.JSON(domainErr.Status, res)
+}
+
+// dateParameters は DateISO 型のクエリパラメータ
+var dateParameters = map[string]bool{"date": true, "from": true, "to": true}
+
+// toDomainError は handler / echo / OpenAPI バリデーターのエラーを domain.Error に揃えます
+func toDomainError(err error) *domain.Error {
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		return domainErr
+	}
+
+	var httpErr *echo.HTTPError
+	if !errors.As(err, &httpErr) {
+		return domain.NewInternalError(err)
+	}
+
+	switch {
+	case httpErr.Code == http.StatusNotFound:
+		return domain.NewError(httpErr.Code, domain.ErrorCodeNotFound, "", err)
+	case httpErr.Code == http.StatusUnauthorized:
+		return domain.NewError(httpErr.Code, domain.ErrorCodeUnauthorized, "", err)
+	case httpErr.Code == http.StatusMethodNotAllowed:
+		return domain.NewError(httpErr.Code, domain.ErrorCodeMethodNotAllowed, "", err)
+	case httpErr.Code >= http.StatusInternalServerError:
+		return domain.NewError(httpErr.Code, domain.ErrorCodeInternalServerError, "", err)
+	}
+
+	// OpenAPI バリデーターとパラメータのバインドのエラーは、原因のパラメータを echo.HTTPError.Internal に持つ
+	name, in := "", ""
+	var requestErr *openapi3filter.RequestError
+	var bindErr *oapi.InvalidParamFormatError
+	switch {
+	case errors.As(err, &requestErr) && requestErr.Parameter != nil:
+		name, in = requestErr.Parameter.Name, requestErr.Parameter.In
+	case errors.As(err, &bindErr):
+		name, in = bindErr.ParamName, bindErr.In
+	default:
+		return domain.NewError(httpErr.Code, domain.ErrorCodeBadRequest, "", err)
+	}
+
+	code := domain.ErrorCodeInvalidParameter
+	if in == openapi3.ParameterInQuery && dateParameters[name] {
+		code = domain.ErrorCodeInvalidDate
+	}
+	return domain.NewError(httpErr.Code, code, in+"."+name, err)
+}
+
+// preferredLanguage は Accept-Language のうち対応している最初の言語を返します
+func preferredLanguage(acceptLanguage string) string {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		lang, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if domain.SupportsLanguage(lang) {
+			return lang
 		}
+	}
+	return domain.DefaultLanguage
+}
+
+// requestID は RequestIDMiddleware が設定したリクエスト ID を返します
+func requestID(c echo.Context) string {
+	if id := reqctx.RequestID(c.Request().Context()); id != "" {
+		return id
+	}
+	return c.Response().Header().Get(echo.HeaderXRequestID)
+}
+
+// registerFormatsOnce は loadSwagger が初めて呼ばれたときに kin-openapi のグローバルな設定を 1 度だけ行います
+var registerFormatsOnce sync.Once
 
-		m.log.Error("unexpected error", zap.Error(err))
-		// Ensure this default fallback also returns a structured JSON error
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"code":    "InternalServerError",
-			"message": "An unexpected internal error occurred.", // Slightly more user-friendly message
+// loadSwagger は検証に使う OpenAPI 仕様を読み込みます
+func loadSwagger() (*openapi3.T, error) {
+	registerFormatsOnce.Do(func() {
+		// kin-openapi 標準の date 形式は正規表現のみで 2026-02-31 なども通すため、
+		// ハンドラーでの time.Parse と同じ基準で検証する
+		openapi3.DefineStringFormatCallback("date", func(v string) error {
+			_, err := time.Parse(time.DateOnly, v)
+			return err
 		})
+		openapi3filter.RegisterBodyDecoder(echo.MIMETextHTML, openapi3filter.FileBodyDecoder)
+	})
+
+	swagger, err := oapi.GetSwagger()
+	if err != nil {
+		return nil, err
+	}
+	// servers にはホスト名が含まれ、そのままでは他のホストへのリクエストがすべて
+	// 「ルートなし」になるため、パスだけで照合する
+	swagger.Servers = nil
+	return swagger, nil
+}
+
+// AdminAuthMiddleware は Authorization: Bearer <token> を確かめる運用向けエンドポイント用のミドルウェアです。
+// token が空の場合は認証せずに通します
+func (m *Middleware) AdminAuthMiddleware(token string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if token == "" {
+			return next
+		}
+		return func(c echo.Context) error {
+			got, ok := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, "Bearer")
+				return echo.NewHTTPError(http.StatusUnauthorized)
+			}
+			return next(c)
+		}
 	}
 }
 
+// OpenAPIMiddleware はリクエストのパラメータを OpenAPI 仕様で検証します
 func (m *Middleware) OpenAPIMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
-	swagger, err := oapi.GetSwagger()
+	swagger, err := loadSwagger()
 	if err != nil {
 		m.log.Error("failed to get swagger", zap.Error(err))
 		return func(c echo.Context) error {
-			return c.JSON(http.StatusInternalServerError, map[string]string{
-				"code":    "InternalServerError",
-				"message": "Internal server error",
-			})
+			return domain.NewInternalError(err)
 		}
 	}
-	return OAPIMiddleware.OapiRequestValidator(swagger)(next)
+	return OAPIMiddleware.OapiRequestValidatorWithOptions(swagger, &OAPIMiddleware.Options{
+		// /healthz や /metrics など API 仕様の外にある運用向けのエンドポイントは検証しない
+		Skipper: func(c echo.Context) bool {
+			return !strings.HasPrefix(c.Request().URL.Path, "/api/")
+		},
+	})(next)
+}
+
+// ResponseValidationMiddleware はハンドラーのレスポンスを OpenAPI 仕様で検証し、
+// 仕様と合わないレスポンスを 500 エラーに置き換えます。
+// oapi.ServerInterface のモデルと実際のレスポンスのずれを開発・テスト中に検出するためのもので、
+// レスポンスをバッファするため本番では使いません
+func (m *Middleware) ResponseValidationMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	swagger, err := loadSwagger()
+	if err == nil {
+		var router routers.Router
+		router, err = gorillamux.NewRouter(swagger)
+		if err == nil {
+			return m.validateResponse(router, next)
+		}
+	}
+	m.log.Error("failed to prepare response validation", zap.Error(err))
+	return func(c echo.Context) error {
+		return domain.NewInternalError(err)
+	}
+}
+
+func (m *Middleware) validateResponse(router routers.Router, next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		route, pathParams, err := router.FindRoute(c.Request())
+		if err != nil {
+			// 仕様にないルートはルーター・リクエスト検証に任せる
+			return next(c)
+		}
+
+		res := c.Response()
+		writer := res.Writer
+		rec := &responseRecorder{header: writer.Header()}
+		res.Writer = rec
+		err = next(c)
+		res.Writer = writer
+		if err != nil && !res.Committed {
+			return err
+		}
+
+		input := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: &openapi3filter.RequestValidationInput{
+				Request:    c.Request(),
+				PathParams: pathParams,
+				Route:      route,
+			},
+			Status:  rec.status,
+			Header:  res.Header(),
+			Options: &openapi3filter.Options{IncludeResponseStatus: true},
+		}
+		input.SetBodyBytes(rec.body.Bytes())
+
+		res.Committed = false
+		res.Size = 0
+		if verr := openapi3filter.ValidateResponse(c.Request().Context(), input); verr != nil {
+			res.Header().Del(echo.HeaderContentType)
+			return m.writeError(c, domain.NewInternalError(fmt.Errorf("response does not match the OpenAPI spec: %w", verr)))
+		}
+		res.WriteHeader(rec.status)
+		_, werr := res.Write(rec.body.Bytes())
+		return werr
+	}
+}
+
+// responseRecorder は検証が終わるまでレスポンスを書き出さずに保持します
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) Header() http.Header { return r.header }
+
+func (r *responseRecorder) WriteHeader(status int) { r.status = status }
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.body.Write(b)
 }
@@ -0,0 +1,298 @@
+package app
+
+import (
+	"api/internal/domain"
+	"api/internal/metrics"
+	"api/internal/reqctx"
+	"api/internal/usecase"
+	"api/pkg/oapi"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// validatorError は OpenAPI バリデーターがパラメータの検証に失敗したときのエラーです
+func validatorError(name, in string) error {
+	return &echo.HTTPError{
+		Code:     http.StatusBadRequest,
+		Message:  "parameter has an error",
+		Internal: &openapi3filter.RequestError{Parameter: &openapi3.Parameter{Name: name, In: in}, Err: errors.New("invalid")},
+	}
+}
+
+// bindError は oapi-codegen のパラメータのバインドに失敗したときのエラーです
+func bindError(name, in string) error {
+	return echo.NewHTTPError(http.StatusBadRequest, "Invalid format for parameter").
+		SetInternal(&oapi.InvalidParamFormatError{ParamName: name, In: in, Err: errors.New("invalid")})
+}
+
+func TestToDomainError(t *testing.T) {
+	notFound := domain.NewNotFoundError(domain.ErrorCodeBusStopNotFound, "path.id")
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   domain.ErrorCode
+		wantField  string
+	}{
+		{"domain error", notFound, http.StatusNotFound, domain.ErrorCodeBusStopNotFound, "path.id"},
+		{"route not found", echo.ErrNotFound, http.StatusNotFound, domain.ErrorCodeNotFound, ""},
+		{"unauthorized", echo.NewHTTPError(http.StatusUnauthorized), http.StatusUnauthorized, domain.ErrorCodeUnauthorized, ""},
+		{"method not allowed", echo.ErrMethodNotAllowed, http.StatusMethodNotAllowed, domain.ErrorCodeMethodNotAllowed, ""},
+		{"echo 5xx", echo.ErrServiceUnavailable, http.StatusServiceUnavailable, domain.ErrorCodeInternalServerError, ""},
+		{"validator date", validatorError("date", "query"), http.StatusBadRequest, domain.ErrorCodeInvalidDate, "query.date"},
+		{"validator path id", validatorError("id", "path"), http.StatusBadRequest, domain.ErrorCodeInvalidParameter, "path.id"},
+		{"validator group_id", validatorError("group_id", "query"), http.StatusBadRequest, domain.ErrorCodeInvalidParameter, "query.group_id"},
+		{"validator header", validatorError("If-None-Match", "header"), http.StatusBadRequest, domain.ErrorCodeInvalidParameter, "header.If-None-Match"},
+		{"validator without parameter", &echo.HTTPError{Code: http.StatusBadRequest, Internal: &openapi3filter.RequestError{Err: errors.New("body")}}, http.StatusBadRequest, domain.ErrorCodeBadRequest, ""},
+		{"bind from", bindError("from", "query"), http.StatusBadRequest, domain.ErrorCodeInvalidDate, "query.from"},
+		{"bind path id", bindError("id", "path"), http.StatusBadRequest, domain.ErrorCodeInvalidParameter, "path.id"},
+		{"plain bad request", echo.NewHTTPError(http.StatusBadRequest, `parameter "date" in query`), http.StatusBadRequest, domain.ErrorCodeBadRequest, ""},
+		{"unknown error", errors.New("boom"), http.StatusInternalServerError, domain.ErrorCodeInternalServerError, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := toDomainError(tt.err)
+			if got.Status != tt.wantStatus || got.Code != tt.wantCode || got.Field != tt.wantField {
+				t.Errorf("toDomainError() = %d %s %q, want %d %s %q", got.Status, got.Code, got.Field, tt.wantStatus, tt.wantCode, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestPreferredLanguage(t *testing.T) {
+	tests := map[string]string{
+		"":                          "ja",
+		"en":                        "en",
+		"EN":                        "en",
+		"en-US,en;q=0.9":            "en",
+		"ja-JP,ja;q=0.9,en;q=0.8":   "ja",
+		"fr-FR, en;q=0.5":           "en",
+		"fr, de":                    "ja",
+		"*":                         "ja",
+		" en-GB ; q=0.7 , ja;q=0.3": "en",
+	}
+	for header, want := range tests {
+		if got := preferredLanguage(header); got != want {
+			t.Errorf("preferredLanguage(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestWriteError_Envelope(t *testing.T) {
+	m := NewMiddleware(zap.NewNop(), nil, metrics.New(nil, time.UTC))
+	tests := []struct {
+		name   string
+		method string
+		err    error
+		lang   string
+		want   map[string]any
+	}{
+		{
+			name:   "with field",
+			method: http.MethodGet,
+			err:    domain.NewBadRequestError(domain.ErrorCodeInvalidDate, "query.date", nil),
+			lang:   "en-US",
+			want: map[string]any{
+				"status":    float64(400),
+				"code":      "InvalidDate",
+				"message":   "The date must be in YYYY-MM-DD format.",
+				"requestId": "req-1",
+				"field":     "query.date",
+			},
+		},
+		{
+			name:   "without field",
+			method: http.MethodGet,
+			err:    errors.New("boom"),
+			lang:   "",
+			want: map[string]any{
+				"status":    float64(500),
+				"code":      "InternalServerError",
+				"message":   "サーバー内部でエラーが発生しました。",
+				"requestId": "req-1",
+			},
+		},
+		{
+			name:   "head",
+			method: http.MethodHead,
+			err:    echo.ErrNotFound,
+			lang:   "ja",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/bus-stops", nil)
+			req.Header.Set("Accept-Language", tt.lang)
+			req = req.WithContext(reqctx.WithRequestID(req.Context(), "req-1"))
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+
+			if err := m.writeError(c, tt.err); err != nil {
+				t.Fatal(err)
+			}
+			wantLang := "ja"
+			if tt.lang == "en-US" {
+				wantLang = "en"
+			}
+			if got := rec.Header().Get("Content-Language"); got != wantLang {
+				t.Errorf("Content-Language = %q, want %q", got, wantLang)
+			}
+			if tt.want == nil {
+				if rec.Code != http.StatusNotFound || rec.Body.Len() != 0 {
+					t.Errorf("HEAD: status = %d, body = %q", rec.Code, rec.Body.String())
+				}
+				return
+			}
+
+			if int(tt.want["status"].(float64)) != rec.Code {
+				t.Errorf("status = %d, want %v", rec.Code, tt.want["status"])
+			}
+			var got map[string]any
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("body = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// versionUseCase はアクセスログに出すデータセットのバージョンだけを返す BusStopUseCase です
+type versionUseCase struct {
+	usecase.BusStopUseCase
+}
+
+func (versionUseCase) DatasetVersion(context.Context) string { return "v1" }
+
+// newLoggedEcho は RequestIDMiddleware・AccessLogMiddleware・ErrorHandlingMiddleware を main と同じ順に使う echo と、
+// ログの記録先を返します
+func newLoggedEcho() (*echo.Echo, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	m := NewMiddleware(zap.New(core), versionUseCase{}, metrics.New(nil, time.UTC))
+
+	e := echo.New()
+	e.HTTPErrorHandler = m.HTTPErrorHandler
+	e.Use(m.RequestIDMiddleware, m.AccessLogMiddleware, m.ErrorHandlingMiddleware)
+	e.GET("/api/bus-stops/:id", func(c echo.Context) error {
+		switch c.Param("id") {
+		case "1":
+			return c.JSON(http.StatusOK, map[string]int{"id": 1})
+		case "500":
+			return errors.New("boom")
+		}
+		return domain.NewNotFoundError(domain.ErrorCodeBusStopNotFound, "path.id")
+	})
+	return e, logs
+}
+
+var generatedRequestID = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		wantSame bool
+	}{
+		{"valid", "abc-123.X_y", true},
+		{"max length", strings.Repeat("a", 128), true},
+		{"missing", "", false},
+		{"invalid characters", "bad id!", false},
+		{"too long", strings.Repeat("a", 129), false},
+		{"header injection", "abc\r\nSet-Cookie: x=1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, logs := newLoggedEcho()
+			req := httptest.NewRequest(http.MethodGet, "/api/bus-stops/9", nil)
+			if tt.header != "" {
+				req.Header[echo.HeaderXRequestID] = []string{tt.header}
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			id := rec.Header().Get(echo.HeaderXRequestID)
+			if tt.wantSame && id != tt.header {
+				t.Errorf("X-Request-Id = %q, want %q", id, tt.header)
+			}
+			if !tt.wantSame && !generatedRequestID.MatchString(id) {
+				t.Errorf("X-Request-Id = %q, want a generated ID", id)
+			}
+
+			var body struct {
+				RequestID string `json:"requestId"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.RequestID != id {
+				t.Errorf("requestId = %q, want %q", body.RequestID, id)
+			}
+			for _, entry := range logs.All() {
+				if got := entry.ContextMap()["request_id"]; got != id {
+					t.Errorf("%q log: request_id = %v, want %q", entry.Message, got, id)
+				}
+			}
+		})
+	}
+}
+
+func TestAccessLogMiddleware(t *testing.T) {
+	tests := []struct {
+		target     string
+		wantStatus int
+		wantLevel  zapcore.Level
+	}{
+		{"/api/bus-stops/1?date=2026-09-28", http.StatusOK, zapcore.InfoLevel},
+		{"/api/bus-stops/9", http.StatusNotFound, zapcore.InfoLevel},
+		{"/api/bus-stops/500", http.StatusInternalServerError, zapcore.ErrorLevel},
+	}
+	for _, tt := range tests {
+		e, logs := newLoggedEcho()
+		req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		req.Header.Set(echo.HeaderXRequestID, "req-1")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != tt.wantStatus {
+			t.Fatalf("GET %s: status = %d, want %d", tt.target, rec.Code, tt.wantStatus)
+		}
+
+		access := logs.FilterMessage("access").All()
+		if len(access) != 1 {
+			t.Fatalf("GET %s: %d access log entries, want 1", tt.target, len(access))
+		}
+		entry := access[0]
+		fields := entry.ContextMap()
+		want := map[string]any{
+			"request_id":      "req-1",
+			"method":          http.MethodGet,
+			"route":           "/api/bus-stops/:id",
+			"uri":             tt.target,
+			"status":          int64(tt.wantStatus),
+			"dataset_version": "v1",
+		}
+		for key, value := range want {
+			if fields[key] != value {
+				t.Errorf("GET %s: %s = %v (%T), want %v", tt.target, key, fields[key], fields[key], value)
+			}
+		}
+		if entry.Level != tt.wantLevel {
+			t.Errorf("GET %s: level = %s, want %s", tt.target, entry.Level, tt.wantLevel)
+		}
+	}
+}
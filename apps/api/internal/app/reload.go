@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Reload はサービスデータとバス停データをファイルから読み込み直します。
+// 検証に失敗した場合はどちらも差し替えず、以前のデータを使い続けます
+func (a *AppContext) Reload(ctx context.Context) error {
+	return a.UseCases.BusStop.Reload(ctx)
+}
+
+// ReloadOnSignal は sig を受け取るたびに Reload します
+func (a *AppContext) ReloadOnSignal(sig ...os.Signal) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sig...)
+	go func() {
+		for range ch {
+			a.Logger.Info("シグナルを受け取りました。データを読み込み直します")
+			if err := a.Reload(context.Background()); err != nil {
+				a.Logger.Error("データの読み込み直しに失敗しました。以前のデータを使い続けます", zap.Error(err))
+			}
+		}
+	}()
+}
+
+// ReloadOnChange は ctx が終わるまで interval ごとに DataPath 以下の JSON ファイルを確かめ、
+// 追加・変更・削除があれば Reload します。開発環境で data/ の編集をすぐに反映するためのものです
+func (a *AppContext) ReloadOnChange(ctx context.Context, interval time.Duration) {
+	dir := a.Config.GetDataDir()
+	a.Logger.Info("開発環境: data/ の変更を監視し、変更があれば読み込み直します", zap.String("dir", dir))
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		last := dataFingerprint(dir)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			current := dataFingerprint(dir)
+			if current == last {
+				continue
+			}
+			last = current
+			a.Logger.Info("data/ の変更を検出しました。データを読み込み直します")
+			if err := a.Reload(ctx); err != nil {
+				a.Logger.Error("データの読み込み直しに失敗しました。以前のデータを使い続けます", zap.Error(err))
+			}
+		}
+	}()
+}
+
+// dataFingerprint は dir 以下の JSON ファイルのパス・サイズ・更新時刻を並べた文字列を返します。
+// 読めないファイルやディレクトリは飛ばします
+func dataFingerprint(dir string) string {
+	var b strings.Builder
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		fmt.Fprintf(&b, "%s %d %d\n", path, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	return b.String()
+}
@@ -0,0 +1,82 @@
+package app
+
+import (
+	"api/internal/config"
+	"api/internal/usecase"
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// reloadCountingUseCase は Reload の呼び出し回数を数える BusStopUseCase です
+type reloadCountingUseCase struct {
+	usecase.BusStopUseCase
+	reloads atomic.Int32
+}
+
+func (u *reloadCountingUseCase) Reload(context.Context) error {
+	u.reloads.Add(1)
+	return nil
+}
+
+func TestReloadOnChange(t *testing.T) {
+	dir := t.TempDir()
+	services := filepath.Join(dir, "services")
+	if err := os.MkdirAll(services, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(name, data string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(services, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.json", "{}")
+
+	busStop := &reloadCountingUseCase{}
+	a := &AppContext{
+		Config:   &config.Config{DataPath: dir},
+		Logger:   zap.NewNop(),
+		UseCases: &usecase.UseCases{BusStop: busStop},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a.ReloadOnChange(ctx, 10*time.Millisecond)
+
+	waitReloads := func(want int32) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for busStop.reloads.Load() < want && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		if got := busStop.reloads.Load(); got != want {
+			t.Fatalf("reloads = %d, want %d", got, want)
+		}
+	}
+
+	// JSON 以外の変更では読み込み直さない
+	write("notes.txt", "memo")
+	time.Sleep(50 * time.Millisecond)
+	waitReloads(0)
+
+	write("a.json", `{"id": "a"}`)
+	waitReloads(1)
+	write("b.json", "{}")
+	waitReloads(2)
+	if err := os.Remove(filepath.Join(services, "b.json")); err != nil {
+		t.Fatal(err)
+	}
+	waitReloads(3)
+
+	// ctx が終わったら監視をやめる
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+	write("a.json", `{"id": "a", "name": "changed"}`)
+	time.Sleep(50 * time.Millisecond)
+	waitReloads(3)
+}
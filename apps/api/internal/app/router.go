@@ -0,0 +1,40 @@
+package app
+
+import (
+	"api/pkg/oapi"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// NewEcho はミドルウェアと API・運用向けのルートを登録した echo を返します
+func (a *AppContext) NewEcho() *echo.Echo {
+	e := echo.New()
+	e.HTTPErrorHandler = a.Middleware.HTTPErrorHandler
+
+	e.Use(a.Middleware.RequestIDMiddleware)
+	e.Use(a.Middleware.MetricsMiddleware)
+	e.Use(a.Middleware.AccessLogMiddleware)
+	e.Use(middleware.Recover())
+	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
+		AllowOrigins:  a.Config.AllowedOrigins,
+		AllowHeaders:  []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, "If-None-Match"},
+		ExposeHeaders: []string{echo.HeaderXRequestID, "ETag"},
+		AllowMethods:  []string{echo.GET, echo.HEAD, echo.PUT, echo.PATCH, echo.POST, echo.DELETE},
+	}))
+	if a.Config.ValidateResponses {
+		e.Use(a.Middleware.ResponseValidationMiddleware)
+	}
+	e.Use(a.Middleware.OpenAPIMiddleware)
+	e.Use(a.Middleware.ErrorHandlingMiddleware)
+
+	oapi.RegisterHandlers(e, NewServer(a.Handlers))
+
+	e.GET("/healthz", a.Handlers.Health.Liveness)
+	e.GET("/readyz", a.Handlers.Health.Readiness)
+	// ADMIN_TOKEN を設定した場合は Authorization: Bearer が必要（本番環境では設定が必須）
+	e.GET("/metrics", echo.WrapHandler(promhttp.HandlerFor(a.Metrics.Registry, promhttp.HandlerOpts{})),
+		a.Middleware.AdminAuthMiddleware(a.Config.AdminToken))
+	return e
+}
@@ -0,0 +1,189 @@
+package app
+
+import (
+	"api/internal/config"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/labstack/echo/v4"
+)
+
+// newTestEcho は data/ の実データを読み込み、レスポンス検証を有効にした echo を返します
+func newTestEcho(t *testing.T) *echo.Echo {
+	t.Helper()
+	loc, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{
+		Enviroment:         "test",
+		DataPath:           "../../data",
+		BusStopsFile:       "bus_stops.json",
+		BusStopGroupsFile:  "bus_stop_groups.json",
+		ServeArchived:      true,
+		ValidateResponses:  true,
+		TimetableCacheSize: 16,
+		DayIndexCacheSize:  8,
+		Timezone:           "Asia/Tokyo",
+		Location:           loc,
+		RepositoryBackend:  "file",
+	}
+	return Initialize(cfg).NewEcho()
+}
+
+func serve(e *echo.Echo, method, target string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+// errorBody はエラーレスポンスのうちテストで確かめる項目です
+type errorBody struct {
+	Status    int    `json:"status"`
+	Code      string `json:"code"`
+	Field     string `json:"field"`
+	RequestID string `json:"requestId"`
+}
+
+func TestAPI_Success(t *testing.T) {
+	e := newTestEcho(t)
+	targets := []string{
+		"/api/bus-stops",
+		"/api/bus-stops?group_id=3",
+		"/api/bus-stops/1",
+		"/api/bus-stops/1/timetable?date=2026-09-28",
+		"/api/bus-stops/1/timetable?date=2026-05-23&includeHistorical=true",
+		"/api/bus-stops/1/timetable/print?date=2026-09-28",
+		"/api/bus-stops/1/timetable/print?from=2026-09-28&to=2026-10-04",
+		"/api/bus-stops/groups",
+		"/api/bus-stops/groups/3",
+		"/api/bus-stops/groups/3/timetable?date=2026-09-28",
+		"/api/bus-stops/groups/3/timetable/print?date=2026-09-28",
+	}
+
+	// 仕様のすべての操作を呼んでいるか確かめる
+	swagger, err := loadSwagger()
+	if err != nil {
+		t.Fatal(err)
+	}
+	router, err := gorillamux.NewRouter(swagger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	called := map[string]bool{}
+
+	for _, target := range targets {
+		rec := serve(e, http.MethodGet, target, nil)
+		if rec.Code != http.StatusOK {
+			t.Errorf("GET %s: status = %d, body = %s", target, rec.Code, rec.Body.String())
+			continue
+		}
+		wantType := echo.MIMEApplicationJSON
+		if strings.Contains(target, "/print") {
+			wantType = echo.MIMETextHTML
+		}
+		if got := rec.Header().Get(echo.HeaderContentType); !strings.HasPrefix(got, wantType) {
+			t.Errorf("GET %s: Content-Type = %q, want %s", target, got, wantType)
+		}
+		if rec.Header().Get(echo.HeaderXRequestID) == "" {
+			t.Errorf("GET %s: no X-Request-Id", target)
+		}
+
+		route, _, err := router.FindRoute(httptest.NewRequest(http.MethodGet, target, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		called[route.Operation.OperationID] = true
+	}
+
+	for path, item := range swagger.Paths.Map() {
+		for method, op := range item.Operations() {
+			if !called[op.OperationID] {
+				t.Errorf("%s %s (%s) is not covered", method, path, op.OperationID)
+			}
+		}
+	}
+}
+
+func TestAPI_NotModified(t *testing.T) {
+	e := newTestEcho(t)
+	for _, target := range []string{
+		"/api/bus-stops/1/timetable?date=2026-09-28",
+		"/api/bus-stops/groups/3/timetable?date=2026-09-28",
+	} {
+		first := serve(e, http.MethodGet, target, nil)
+		etag := first.Header().Get("ETag")
+		if first.Code != http.StatusOK || etag == "" {
+			t.Fatalf("GET %s: status = %d, ETag = %q", target, first.Code, etag)
+		}
+
+		rec := serve(e, http.MethodGet, target, http.Header{"If-None-Match": {etag}})
+		if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+			t.Errorf("GET %s with If-None-Match: status = %d, body = %q", target, rec.Code, rec.Body.String())
+		}
+		if got := rec.Header().Get("ETag"); got != etag {
+			t.Errorf("GET %s with If-None-Match: ETag = %q, want %q", target, got, etag)
+		}
+
+		// 別の日付の ETag では 304 にしない
+		other := strings.Replace(target, "2026-09-28", "2026-09-29", 1)
+		if rec := serve(e, http.MethodGet, other, http.Header{"If-None-Match": {etag}}); rec.Code != http.StatusOK {
+			t.Errorf("GET %s with another date's ETag: status = %d", other, rec.Code)
+		}
+	}
+}
+
+func TestAPI_Errors(t *testing.T) {
+	e := newTestEcho(t)
+	tests := []struct {
+		target     string
+		wantStatus int
+		wantCode   string
+		wantField  string
+	}{
+		{"/api/bus-stops/1/timetable?date=2026-02-31", http.StatusBadRequest, "InvalidDate", "query.date"},
+		{"/api/bus-stops/groups/3/timetable?date=2026-02-31", http.StatusBadRequest, "InvalidDate", "query.date"},
+		{"/api/bus-stops/1/timetable?date=20260928", http.StatusBadRequest, "InvalidDate", "query.date"},
+		{"/api/bus-stops/1/timetable/print?date=2026-02-31", http.StatusBadRequest, "InvalidDate", "query.date"},
+		{"/api/bus-stops/groups/3/timetable/print?from=2026-02-30&to=2026-03-01", http.StatusBadRequest, "InvalidDate", "query.from"},
+		{"/api/bus-stops/1/timetable/print?from=2026-09-28", http.StatusBadRequest, "InvalidPeriod", "query.to"},
+		{"/api/bus-stops/abc", http.StatusBadRequest, "InvalidParameter", "path.id"},
+		{"/api/bus-stops?group_id=x", http.StatusBadRequest, "InvalidParameter", "query.group_id"},
+		{"/api/bus-stops/1/timetable?includeHistorical=maybe", http.StatusBadRequest, "InvalidParameter", "query.includeHistorical"},
+		{"/api/bus-stops/9999", http.StatusNotFound, "BusStopNotFound", "path.id"},
+		{"/api/bus-stops/9999/timetable?date=2026-09-28", http.StatusNotFound, "BusStopNotFound", "path.id"},
+		{"/api/bus-stops/9999/timetable/print?date=2026-09-28", http.StatusNotFound, "BusStopNotFound", "path.id"},
+		{"/api/bus-stops/groups/9999", http.StatusNotFound, "BusStopGroupNotFound", "path.id"},
+		{"/api/bus-stops/groups/9999/timetable?date=2026-09-28", http.StatusNotFound, "BusStopGroupNotFound", "path.id"},
+		{"/api/bus-stops/groups/9999/timetable/print?date=2026-09-28", http.StatusNotFound, "BusStopGroupNotFound", "path.id"},
+		{"/api/bus-stops?group_id=9999", http.StatusNotFound, "BusStopGroupNotFound", "query.group_id"},
+		{"/api/unknown", http.StatusNotFound, "NotFound", ""},
+	}
+	for _, tt := range tests {
+		rec := serve(e, http.MethodGet, tt.target, nil)
+		if rec.Code != tt.wantStatus {
+			t.Errorf("GET %s: status = %d, want %d (body %s)", tt.target, rec.Code, tt.wantStatus, rec.Body.String())
+			continue
+		}
+		var body errorBody
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Errorf("GET %s: %v (body %s)", tt.target, err, rec.Body.String())
+			continue
+		}
+		if body.Status != tt.wantStatus || body.Code != tt.wantCode || body.Field != tt.wantField {
+			t.Errorf("GET %s: body = %+v, want %s (%s)", tt.target, body, tt.wantCode, tt.wantField)
+		}
+		if body.RequestID == "" || body.RequestID != rec.Header().Get(echo.HeaderXRequestID) {
+			t.Errorf("GET %s: requestId = %q, X-Request-Id = %q", tt.target, body.RequestID, rec.Header().Get(echo.HeaderXRequestID))
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// Serve は設定したタイムアウトで e を起動し、ctx が終わるまで待ちます。
+// ctx が終わったら新しい接続の受け付けをやめ、処理中のリクエストを ShutdownTimeout まで待ってから
+// ログを書き出して戻ります
+func (a *AppContext) Serve(ctx context.Context, e *echo.Echo) error {
+	cfg := a.Config
+	e.Server.ReadTimeout = cfg.ReadTimeout
+	e.Server.ReadHeaderTimeout = cfg.ReadHeaderTimeout
+	e.Server.WriteTimeout = cfg.WriteTimeout
+	e.Server.IdleTimeout = cfg.IdleTimeout
+	e.Server.MaxHeaderBytes = cfg.MaxHeaderBytes
+
+	errCh := make(chan error, 1)
+	go func() {
+		a.Logger.Info("サーバーを起動します", zap.String("addr", cfg.GetAddr()))
+		if err := e.Start(cfg.GetAddr()); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+		close(errCh)
+	}()
+
+	select {
+	case err, ok := <-errCh:
+		if ok {
+			_ = a.Logger.Sync()
+			return err
+		}
+	case <-ctx.Done():
+		a.Logger.Info("シャットダウンします。処理中のリクエストの完了を待ちます",
+			zap.Duration("timeout", cfg.ShutdownTimeout))
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	err := e.Shutdown(shutdownCtx)
+	if err != nil {
+		a.Logger.Error("処理中のリクエストを待ちきれずに終了します", zap.Error(err))
+	} else {
+		a.Logger.Info("シャットダウンしました")
+	}
+	// 標準エラー出力への Sync は失敗することがあるため結果は無視する
+	_ = a.Logger.Sync()
+	return err
+}
@@ -0,0 +1,82 @@
+package app
+
+import (
+	"api/internal/config"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+func TestServe_WaitsForInFlightRequests(t *testing.T) {
+	const shutdownTimeout = 2 * time.Second
+	a := &AppContext{
+		Config: &config.Config{ShutdownTimeout: shutdownTimeout},
+		Logger: zap.NewNop(),
+	}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+	e.GET("/slow", func(c echo.Context) error {
+		close(started)
+		<-release
+		return c.String(http.StatusOK, "done")
+	})
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Listener = listener
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	served := make(chan error, 1)
+	go func() { served <- a.Serve(ctx, e) }()
+
+	type result struct {
+		body string
+		err  error
+	}
+	responses := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String() + "/slow")
+		if err != nil {
+			responses <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		responses <- result{string(body), err}
+	}()
+	<-started
+
+	// リクエストの処理中に止める
+	cancel()
+	select {
+	case err := <-served:
+		t.Fatalf("Serve returned before the in-flight request finished: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+	res := <-responses
+	if res.err != nil || res.body != "done" {
+		t.Errorf("in-flight request: body = %q, err = %v", res.body, res.err)
+	}
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("Serve() = %v", err)
+		}
+	case <-time.After(shutdownTimeout):
+		t.Fatal("Serve did not return within ShutdownTimeout")
+	}
+}
@@ -31,6 +31,16 @@ func (s *Server) BusStopServiceGetBusStopTimetable(ctx echo.Context, id int32, p
 	return s.Handlers.BusStop.GetBusStopTimetable(ctx, id, params)
 }
 
+// BusStopGroupsServiceGetBusStopGroupPrintableTimetable implements oapi.ServerInterface.
+func (s *Server) BusStopGroupsServiceGetBusStopGroupPrintableTimetable(ctx echo.Context, id int32, params oapi.BusStopGroupsServiceGetBusStopGroupPrintableTimetableParams) error {
+	return s.Handlers.BusStop.GetBusStopGroupPrintableTimetable(ctx, id, params)
+}
+
+// BusStopServiceGetBusStopPrintableTimetable implements oapi.ServerInterface.
+func (s *Server) BusStopServiceGetBusStopPrintableTimetable(ctx echo.Context, id int32, params oapi.BusStopServiceGetBusStopPrintableTimetableParams) error {
+	return s.Handlers.BusStop.GetBusStopPrintableTimetable(ctx, id, params)
+}
+
 // BusStopServiceGetAllBusStops implements oapi.ServerInterface.
 func (s *Server) BusStopServiceGetAllBusStops(ctx echo.Context, params oapi.BusStopServiceGetAllBusStopsParams) error {
 	return s.Handlers.BusStop.GetBusStops(ctx, params.GroupId)
@@ -2,12 +2,7 @@ package config
 
 import (
 	"fmt"
-	"log"
-	"os"
-	"strconv"
-	"strings"
-
-	"github.com/joho/godotenv"
+	"time"
 )
 
 type Config struct {
@@ -18,6 +13,32 @@ type Config struct {
 	BusStopsFile      string
 	BusStopGroupsFile string
 	AllowedOrigins    []string
+	// ServeArchived が false のとき services/archived を読み込まず、過去日付でも現行のサービスだけを返す
+	ServeArchived bool
+	// ValidateResponses が true のときハンドラーのレスポンスを OpenAPI 仕様で検証し、合わなければ 500 を返す。
+	// 開発・テスト環境では既定で有効
+	ValidateResponses bool
+	// TimetableCacheSize は計算済みの時刻表をメモリに保持する件数の上限（バス停・グループそれぞれ）。0 でキャッシュしない
+	TimetableCacheSize int
+	// DayIndexCacheSize は日ごとの運行するセグメントの索引を保持する日数の上限
+	DayIndexCacheSize int
+	// ReadTimeout / ReadHeaderTimeout / WriteTimeout / IdleTimeout は HTTP サーバーのタイムアウト
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// MaxHeaderBytes はリクエストヘッダーの最大サイズ（バイト）
+	MaxHeaderBytes int
+	// ShutdownTimeout は SIGTERM を受け取ってから処理中のリクエストの完了を待つ時間。
+	// Cloud Run は SIGTERM の 10 秒後に強制終了するため、それより短くする
+	ShutdownTimeout time.Duration
+	// Timezone は「今日」や曜日の判定に使うタイムゾーン（IANA 名）。Location はその読み込み結果
+	Timezone string
+	Location *time.Location
+	// RepositoryBackend はバス停・サービスデータの読み込み元。現在は "file"（DataPath の JSON）のみ
+	RepositoryBackend string
+	// AdminToken を設定すると /metrics に Authorization: Bearer <AdminToken> が必要になる。本番環境では必須で、それ以外の環境で空の場合は認証なしで公開する
+	AdminToken string
 }
 
 func (c *Config) GetAddr() string {
@@ -25,7 +46,7 @@ func (c *Config) GetAddr() string {
 }
 
 func (c *Config) IsDev() bool {
-	return c.Enviroment == "dev" || c.Enviroment == "development"
+	return isDev(c.Enviroment)
 }
 
 func (c *Config) IsProd() bool {
@@ -44,58 +65,52 @@ func (c *Config) GetDataDir() string {
 	return c.DataPath
 }
 
-func LoadConfig() (*Config, error) {
-	err := godotenv.Load()
-	if err != nil {
-		log.Printf("⚠️  No .env file found or error loading .env: %v", err)
-	} else {
-		log.Println("✅ .env file loaded successfully")
-	}
+func isDev(env string) bool {
+	return env == "dev" || env == "development"
+}
+
+// environments は API_ENV に指定できる値
+var environments = []string{"dev", "development", "test", "prod", "production"}
 
-	env := getEnv("API_ENV", "prod")
-	allowedOrigins := []string{}
-	if env == "dev" || env == "development" {
-		allowedOrigins = []string{
-			// nextjs in development
-			"http://web:3000",
-			"http://localhost:3000",
-			// nextjs preview (production build)
-			"http://localhost:3001",
-			// swagger ui in development
-			"http://swagger:8080",
-			"http://localhost:8080",
-		}
-	} else {
-		originsStr := getEnv("CORS_ALLOWED_ORIGINS", "")
-		if originsStr != "" {
-			allowedOrigins = strings.Split(originsStr, ",")
-		}
-	}
-	log.Printf("Allowed Origins: %v", allowedOrigins)
+// repositoryBackends は REPOSITORY_BACKEND に指定できる値
+var repositoryBackends = []string{"file"}
 
-	return &Config{
-		Enviroment:        env,
-		Host:              getEnv("HOST", "localhost"),
-		Port:              getEnvAsInt("PORT", 8080),
-		DataPath:          getEnv("DATA_PATH", "./data"),
-		BusStopsFile:      getEnv("BUS_STOPS_FILE", "bus_stops.json"),
-		BusStopGroupsFile: getEnv("BUS_STOP_GROUPS_FILE", "bus_stop_groups.json"),
-		AllowedOrigins:    allowedOrigins,
-	}, nil
+// devAllowedOrigins は開発環境で CORS_ALLOWED_ORIGINS を指定しなかったときに許可するオリジン
+var devAllowedOrigins = []string{
+	// nextjs in development
+	"http://web:3000",
+	"http://localhost:3000",
+	// nextjs preview (production build)
+	"http://localhost:3001",
+	// swagger ui in development
+	"http://swagger:8080",
+	"http://localhost:8080",
 }
 
-func getEnv(key, defaultVal string) string {
-	if val, exists := os.LookupEnv(key); exists {
-		return val
-	}
-	return defaultVal
-}
+// minAdminTokenLength は ADMIN_TOKEN に求める最低の長さ
+const minAdminTokenLength = 16
 
-func getEnvAsInt(key string, defaultVal int) int {
-	if val, exists := os.LookupEnv(key); exists {
-		if intVal, err := strconv.Atoi(val); err == nil {
-			return intVal
-		}
-	}
-	return defaultVal
+// fields は設定項目の一覧です。環境変数名を小文字にしたものが設定ファイルのキーになります。
+// def は API_ENV の値を受け取り、既定値を文字列で返します
+var fields = []field{
+	{env: "API_ENV", def: constant("prod"), set: setString(func(c *Config) *string { return &c.Enviroment }), check: oneOf(environments)},
+	{env: "HOST", def: constant("localhost"), set: setString(func(c *Config) *string { return &c.Host }), check: notEmpty},
+	{env: "PORT", def: constant("8080"), set: setInt(func(c *Config) *int { return &c.Port }, 1, 65535)},
+	{env: "DATA_PATH", def: constant("./data"), set: setString(func(c *Config) *string { return &c.DataPath }), check: isDir},
+	{env: "BUS_STOPS_FILE", def: constant("bus_stops.json"), set: setString(func(c *Config) *string { return &c.BusStopsFile }), check: notEmpty},
+	{env: "BUS_STOP_GROUPS_FILE", def: constant("bus_stop_groups.json"), set: setString(func(c *Config) *string { return &c.BusStopGroupsFile }), check: notEmpty},
+	{env: "CORS_ALLOWED_ORIGINS", def: defaultAllowedOrigins, set: setOrigins},
+	{env: "SERVE_ARCHIVED", def: constant("true"), set: setBool(func(c *Config) *bool { return &c.ServeArchived })},
+	{env: "VALIDATE_RESPONSES", def: defaultValidateResponses, set: setBool(func(c *Config) *bool { return &c.ValidateResponses })},
+	{env: "TIMETABLE_CACHE_SIZE", def: constant("512"), set: setInt(func(c *Config) *int { return &c.TimetableCacheSize }, 0, 1<<20)},
+	{env: "DAY_INDEX_CACHE_SIZE", def: constant("64"), set: setInt(func(c *Config) *int { return &c.DayIndexCacheSize }, 1, 1<<16)},
+	{env: "READ_TIMEOUT", def: constant("10s"), set: setDuration(func(c *Config) *time.Duration { return &c.ReadTimeout })},
+	{env: "READ_HEADER_TIMEOUT", def: constant("5s"), set: setDuration(func(c *Config) *time.Duration { return &c.ReadHeaderTimeout })},
+	{env: "WRITE_TIMEOUT", def: constant("30s"), set: setDuration(func(c *Config) *time.Duration { return &c.WriteTimeout })},
+	{env: "IDLE_TIMEOUT", def: constant("120s"), set: setDuration(func(c *Config) *time.Duration { return &c.IdleTimeout })},
+	{env: "MAX_HEADER_BYTES", def: constant("1048576"), set: setInt(func(c *Config) *int { return &c.MaxHeaderBytes }, 1024, 64<<20)},
+	{env: "SHUTDOWN_TIMEOUT", def: constant("8s"), set: setDuration(func(c *Config) *time.Duration { return &c.ShutdownTimeout })},
+	{env: "TIMEZONE", def: constant("Asia/Tokyo"), set: setTimezone},
+	{env: "REPOSITORY_BACKEND", def: constant("file"), set: setString(func(c *Config) *string { return &c.RepositoryBackend }), check: oneOf(repositoryBackends)},
+	{env: "ADMIN_TOKEN", def: constant(""), secret: true, set: setString(func(c *Config) *string { return &c.AdminToken }), check: minLengthIfSet(minAdminTokenLength)},
 }
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// envMap は os.LookupEnv の代わりに使う環境変数です
+func envMap(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		val, ok := env[key]
+		return val, ok
+	}
+}
+
+// testAdminToken は本番環境の既定値で読み込むときに渡す ADMIN_TOKEN です
+const testAdminToken = "0123456789abcdef"
+
+func TestLoad_Defaults(t *testing.T) {
+	dir := t.TempDir()
+	loaded, err := load("", nil, envMap(map[string]string{"DATA_PATH": dir, "ADMIN_TOKEN": testAdminToken}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := loaded.Config
+	if cfg.Enviroment != "prod" || cfg.Port != 8080 || cfg.TimetableCacheSize != 512 || cfg.DayIndexCacheSize != 64 {
+		t.Errorf("defaults = %+v", cfg)
+	}
+	if cfg.ValidateResponses || len(cfg.AllowedOrigins) != 0 {
+		t.Errorf("prod defaults: ValidateResponses = %t, AllowedOrigins = %v", cfg.ValidateResponses, cfg.AllowedOrigins)
+	}
+	if cfg.ShutdownTimeout != 8*time.Second || cfg.Location.String() != "Asia/Tokyo" || cfg.RepositoryBackend != "file" {
+		t.Errorf("defaults = %+v", cfg)
+	}
+
+	dev, err := load("", nil, envMap(map[string]string{"DATA_PATH": dir, "API_ENV": "development"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !dev.ValidateResponses || !slices.Equal(dev.AllowedOrigins, devAllowedOrigins) {
+		t.Errorf("dev defaults: ValidateResponses = %t, AllowedOrigins = %v", dev.ValidateResponses, dev.AllowedOrigins)
+	}
+}
+
+func TestLoad_RequiresAdminTokenInProd(t *testing.T) {
+	dir := t.TempDir()
+	// API_ENV を指定しない場合も本番環境として扱う
+	prodEnvs := []map[string]string{
+		{"DATA_PATH": dir},
+		{"DATA_PATH": dir, "API_ENV": "prod"},
+		{"DATA_PATH": dir, "API_ENV": "production"},
+	}
+	for _, env := range prodEnvs {
+		_, err := load("", nil, envMap(env))
+		if err == nil || !strings.Contains(err.Error(), "ADMIN_TOKEN") {
+			t.Errorf("API_ENV=%q without ADMIN_TOKEN: err = %v, want ADMIN_TOKEN error", env["API_ENV"], err)
+		}
+	}
+	for _, env := range []string{"development", "test"} {
+		if _, err := load("", nil, envMap(map[string]string{"DATA_PATH": dir, "API_ENV": env})); err != nil {
+			t.Errorf("API_ENV=%q without ADMIN_TOKEN: %v", env, err)
+		}
+	}
+}
+
+func TestLoad_FileUnderEnv(t *testing.T) {
+	dir := t.TempDir()
+	tests := map[string]string{
+		"config.yaml": `
+port: 9000
+timetable_cache_size: 16
+cors_allowed_origins:
+  - https://a.example.com
+  - https://b.example.com
+`,
+		"config.toml": `
+port = 9000
+timetable_cache_size = 16
+cors_allowed_origins = ["https://a.example.com", "https://b.example.com"]
+`,
+	}
+	for name, data := range tests {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		fileValues, err := readFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := load(path, fileValues, envMap(map[string]string{"DATA_PATH": dir, "PORT": "9100", "ADMIN_TOKEN": testAdminToken}))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if loaded.Port != 9100 || loaded.sources["PORT"] != SourceEnv {
+			t.Errorf("%s: port = %d (%s), want 9100 from env", name, loaded.Port, loaded.sources["PORT"])
+		}
+		if loaded.TimetableCacheSize != 16 || loaded.sources["TIMETABLE_CACHE_SIZE"] != SourceFile {
+			t.Errorf("%s: timetable_cache_size = %d (%s), want 16 from file", name, loaded.TimetableCacheSize, loaded.sources["TIMETABLE_CACHE_SIZE"])
+		}
+		if want := []string{"https://a.example.com", "https://b.example.com"}; !slices.Equal(loaded.AllowedOrigins, want) {
+			t.Errorf("%s: AllowedOrigins = %v, want %v", name, loaded.AllowedOrigins, want)
+		}
+	}
+}
+
+func TestLoad_ReportsAllErrors(t *testing.T) {
+	_, err := load("config.yaml", map[string]string{"prot": "8080", "timezone": "Mars/Olympus"}, envMap(map[string]string{
+		"DATA_PATH":            t.TempDir(),
+		"PORT":                 "80a",
+		"TIMETABLE_CACHE_SIZE": "-1",
+		"SHUTDOWN_TIMEOUT":     "8",
+		"CORS_ALLOWED_ORIGINS": "https://ok.example.com,ftp://ng.example.com",
+		"ADMIN_TOKEN":          "short",
+	}))
+	if err == nil {
+		t.Fatal("want error")
+	}
+	for _, want := range []string{`"prot"`, "PORT", "TIMETABLE_CACHE_SIZE", "SHUTDOWN_TIMEOUT", "ftp://ng.example.com", "ADMIN_TOKEN", "timezone（config.yaml）"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not mention %s:\n%v", want, err)
+		}
+	}
+}
+
+func TestLoaded_PrintRedactsSecrets(t *testing.T) {
+	loaded, err := load("", nil, envMap(map[string]string{"DATA_PATH": t.TempDir(), "ADMIN_TOKEN": "0123456789abcdef-secret"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := loaded.Print(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "secret") {
+		t.Errorf("printed config contains the admin token:\n%s", out)
+	}
+	if !strings.Contains(out, `admin_token: "********"  # env`) || !strings.Contains(out, `port: "8080"  # default`) {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
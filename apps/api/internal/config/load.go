@@ -0,0 +1,346 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// field は 1 つの設定項目です
+type field struct {
+	env    string
+	secret bool
+	def    func(env string) string
+	set    func(c *Config, val string) error
+	check  func(val string) error
+}
+
+// key は設定ファイルでのキーを返します
+func (f field) key() string {
+	return strings.ToLower(f.env)
+}
+
+// Source は設定値がどこから来たかを表します
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+)
+
+// Loaded は読み込んだ設定と、項目ごとの値の出どころです
+type Loaded struct {
+	*Config
+	// File は読み込んだ設定ファイルのパス。指定がなければ空です
+	File    string
+	sources map[string]Source
+	values  map[string]string
+}
+
+// Load は既定値・設定ファイル・環境変数の順に重ねて設定を読み込み、検証します。
+// file が空なら環境変数 CONFIG_FILE のファイルを使い、それも空なら設定ファイルは読みません。
+// 不正な値はすべてまとめてエラーとして返します
+func Load(file string) (*Loaded, error) {
+	if err := godotenv.Load(); err != nil {
+		log.Printf("⚠️  No .env file found or error loading .env: %v", err)
+	} else {
+		log.Println("✅ .env file loaded successfully")
+	}
+
+	if file == "" {
+		file = os.Getenv("CONFIG_FILE")
+	}
+	fileValues := map[string]string{}
+	if file != "" {
+		var err error
+		if fileValues, err = readFile(file); err != nil {
+			return nil, err
+		}
+	}
+	return load(file, fileValues, os.LookupEnv)
+}
+
+func load(file string, fileValues map[string]string, lookupEnv func(string) (string, bool)) (*Loaded, error) {
+	var errs []error
+	known := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		known[f.key()] = true
+	}
+	for key := range fileValues {
+		if !known[key] {
+			errs = append(errs, fmt.Errorf("%s: 未知の設定項目 %q です", file, key))
+		}
+	}
+
+	loaded := &Loaded{
+		Config:  &Config{},
+		File:    file,
+		sources: make(map[string]Source, len(fields)),
+		values:  make(map[string]string, len(fields)),
+	}
+
+	// 既定値が API_ENV によって変わる項目があるため、先に API_ENV を決める
+	lookup := func(f field) (string, Source, bool) {
+		if val, ok := lookupEnv(f.env); ok {
+			return val, SourceEnv, true
+		}
+		if val, ok := fileValues[f.key()]; ok {
+			return val, SourceFile, true
+		}
+		return "", SourceDefault, false
+	}
+	env := "prod"
+	if val, _, ok := lookup(fields[0]); ok {
+		env = val
+	}
+
+	for _, f := range fields {
+		val, source, ok := lookup(f)
+		if !ok {
+			val = f.def(env)
+		}
+		loaded.sources[f.env] = source
+		loaded.values[f.env] = val
+
+		where := f.env
+		if source == SourceFile {
+			where = fmt.Sprintf("%s（%s）", f.key(), file)
+		}
+		if f.check != nil {
+			if err := f.check(val); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", where, err))
+				continue
+			}
+		}
+		if err := f.set(loaded.Config, val); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", where, err))
+		}
+	}
+
+	// 本番環境で /metrics を認証なしで公開しないよう、ADMIN_TOKEN を必須にする
+	if loaded.IsProd() && loaded.AdminToken == "" {
+		errs = append(errs, errors.New("ADMIN_TOKEN: 本番環境（API_ENV=prod / production）では必須です"))
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("設定が不正です:\n%w", err)
+	}
+	return loaded, nil
+}
+
+// readFile は YAML（.yaml / .yml）または TOML（.toml）の設定ファイルを読み、キーごとの値を文字列で返します
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("設定ファイルを読めません: %w", err)
+	}
+
+	raw := map[string]any{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return nil, fmt.Errorf("%s: 設定ファイルは .yaml / .yml / .toml のいずれかにしてください", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	values := make(map[string]string, len(raw))
+	for key, v := range raw {
+		val, err := scalarString(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, key, err)
+		}
+		values[strings.ToLower(key)] = val
+	}
+	return values, nil
+}
+
+// scalarString は設定ファイルの値を環境変数と同じ形式の文字列にします。リストはカンマ区切りにします
+func scalarString(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool, int, int64, uint64, float64:
+		return fmt.Sprint(v), nil
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			s, err := scalarString(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = s
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return "", fmt.Errorf("値には文字列・数値・真偽値・それらのリストのみ使えます（%T）", v)
+	}
+}
+
+// Print は有効な設定を「キー: 値  # 出どころ」の形式で書き出します。秘密の値は伏せます
+func (l *Loaded) Print(w io.Writer) error {
+	if l.File != "" {
+		if _, err := fmt.Fprintf(w, "# config file: %s\n", l.File); err != nil {
+			return err
+		}
+	}
+	for _, f := range fields {
+		val := l.values[f.env]
+		if f.secret && val != "" {
+			val = "********"
+		}
+		if _, err := fmt.Fprintf(w, "%s: %q  # %s\n", f.key(), val, l.sources[f.env]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func constant(val string) func(string) string {
+	return func(string) string { return val }
+}
+
+func defaultAllowedOrigins(env string) string {
+	if isDev(env) {
+		return strings.Join(devAllowedOrigins, ",")
+	}
+	return ""
+}
+
+func defaultValidateResponses(env string) string {
+	return strconv.FormatBool(isDev(env) || env == "test")
+}
+
+func setString(ptr func(*Config) *string) func(*Config, string) error {
+	return func(c *Config, val string) error {
+		*ptr(c) = val
+		return nil
+	}
+}
+
+func setInt(ptr func(*Config) *int, min, max int) func(*Config, string) error {
+	return func(c *Config, val string) error {
+		n, err := strconv.Atoi(strings.TrimSpace(val))
+		if err != nil {
+			return fmt.Errorf("%q は整数ではありません", val)
+		}
+		if n < min || n > max {
+			return fmt.Errorf("%d は %d 以上 %d 以下にしてください", n, min, max)
+		}
+		*ptr(c) = n
+		return nil
+	}
+}
+
+func setBool(ptr func(*Config) *bool) func(*Config, string) error {
+	return func(c *Config, val string) error {
+		b, err := strconv.ParseBool(strings.TrimSpace(val))
+		if err != nil {
+			return fmt.Errorf("%q は真偽値（true / false）ではありません", val)
+		}
+		*ptr(c) = b
+		return nil
+	}
+}
+
+func setDuration(ptr func(*Config) *time.Duration) func(*Config, string) error {
+	return func(c *Config, val string) error {
+		d, err := time.ParseDuration(strings.TrimSpace(val))
+		if err != nil {
+			return fmt.Errorf("%q は時間（例: 10s, 1m30s）ではありません", val)
+		}
+		if d <= 0 {
+			return fmt.Errorf("%s は正の時間にしてください", d)
+		}
+		*ptr(c) = d
+		return nil
+	}
+}
+
+func setOrigins(c *Config, val string) error {
+	c.AllowedOrigins = []string{}
+	var errs []error
+	for _, origin := range strings.Split(val, ",") {
+		origin = strings.TrimSpace(origin)
+		if origin == "" {
+			continue
+		}
+		if origin != "*" {
+			u, err := url.Parse(origin)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || (u.Path != "" && u.Path != "/") {
+				errs = append(errs, fmt.Errorf("%q はオリジン（例: https://example.com）ではありません", origin))
+				continue
+			}
+		}
+		c.AllowedOrigins = append(c.AllowedOrigins, origin)
+	}
+	return errors.Join(errs...)
+}
+
+func setTimezone(c *Config, val string) error {
+	loc, err := time.LoadLocation(val)
+	if err != nil {
+		return fmt.Errorf("%q はタイムゾーン（例: Asia/Tokyo）ではありません", val)
+	}
+	c.Timezone = val
+	c.Location = loc
+	return nil
+}
+
+func notEmpty(val string) error {
+	if strings.TrimSpace(val) == "" {
+		return errors.New("空にできません")
+	}
+	return nil
+}
+
+func isDir(val string) error {
+	if err := notEmpty(val); err != nil {
+		return err
+	}
+	info, err := os.Stat(val)
+	if err != nil {
+		return fmt.Errorf("ディレクトリ %q がありません", val)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%q はディレクトリではありません", val)
+	}
+	return nil
+}
+
+func oneOf(allowed []string) func(string) error {
+	return func(val string) error {
+		if !slices.Contains(allowed, val) {
+			return fmt.Errorf("%q は %s のいずれかにしてください", val, strings.Join(allowed, " / "))
+		}
+		return nil
+	}
+}
+
+func minLengthIfSet(n int) func(string) error {
+	return func(val string) error {
+		if val != "" && len(val) < n {
+			return fmt.Errorf("%d 文字以上にしてください", n)
+		}
+		return nil
+	}
+}
@@ -1,5 +1,10 @@
 package domain
 
+import (
+	"errors"
+	"fmt"
+)
+
 type BusStop struct {
 	ID   int32    `json:"id"`
 	Name string   `json:"name"`
@@ -12,3 +17,83 @@ type BusStopGroup struct {
 	Name     string    `json:"name"`
 	BusStops []BusStop `json:"busStops"`
 }
+
+// BusStopData は bus_stops.json と bus_stop_groups.json から読み込んだバス停のデータです
+type BusStopData struct {
+	BusStops []BusStop
+	Groups   []BusStopGroup
+}
+
+// Validate はバス停データの整合性を検証し、見つかった問題をすべてまとめたエラーを返します
+func (d *BusStopData) Validate() error {
+	var errs []error
+
+	stops := make(map[int32]BusStop, len(d.BusStops))
+	for i, stop := range d.BusStops {
+		if _, ok := stops[stop.ID]; ok {
+			errs = append(errs, fmt.Errorf("busStops[%d]: ID %d が重複しています", i, stop.ID))
+		}
+		stops[stop.ID] = stop
+		if stop.Name == "" {
+			errs = append(errs, fmt.Errorf("busStops[%d]: name が空です", i))
+		}
+		if stop.Lat != nil && (*stop.Lat < -90 || *stop.Lat > 90) {
+			errs = append(errs, fmt.Errorf("busStops[%d]: lat %v が範囲外です", i, *stop.Lat))
+		}
+		if stop.Lng != nil && (*stop.Lng < -180 || *stop.Lng > 180) {
+			errs = append(errs, fmt.Errorf("busStops[%d]: lng %v が範囲外です", i, *stop.Lng))
+		}
+	}
+
+	groups := make(map[int32]bool, len(d.Groups))
+	for i, group := range d.Groups {
+		if groups[group.ID] {
+			errs = append(errs, fmt.Errorf("groups[%d]: ID %d が重複しています", i, group.ID))
+		}
+		groups[group.ID] = true
+		if group.Name == "" {
+			errs = append(errs, fmt.Errorf("groups[%d]: name が空です", i))
+		}
+		for j, stop := range group.BusStops {
+			if _, ok := stops[stop.ID]; !ok {
+				errs = append(errs, fmt.Errorf("groups[%d].busStops[%d]: バス停 %d が bus_stops にありません", i, j, stop.ID))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidateStopReferences はサービスの発着停留所がすべてバス停データにあるかを検証します。
+// 現行のサービスが無い停留所を参照していればエラーを返します。
+// アーカイブ済みのサービスは廃止した停留所を参照していることがあるため、エラーにはせずに除きます。
+// valid は除いた後のサービス、skipped は除いたアーカイブ済みのサービスごとの理由です
+func ValidateStopReferences(services []ServiceData, busStops *BusStopData) (valid []ServiceData, skipped []error, err error) {
+	stops := make(map[int32]bool, len(busStops.BusStops))
+	for _, stop := range busStops.BusStops {
+		stops[stop.ID] = true
+	}
+
+	var errs []error
+	valid = make([]ServiceData, 0, len(services))
+	for _, service := range services {
+		var missing []error
+		for _, ref := range []ServiceStopRef{service.From, service.To} {
+			if !stops[ref.StopID] {
+				missing = append(missing, fmt.Errorf("%s: 停留所 %d（%s）が bus_stops にありません", service.ID, ref.StopID, ref.DisplayName))
+			}
+		}
+		switch {
+		case len(missing) == 0:
+			valid = append(valid, service)
+		case service.Archived:
+			skipped = append(skipped, errors.Join(missing...))
+		default:
+			errs = append(errs, missing...)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, nil, errors.Join(errs...)
+	}
+	return valid, skipped, nil
+}
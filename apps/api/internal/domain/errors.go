@@ -1,54 +1,144 @@
 package domain
 
-type ServiceError struct {
-	Code    string  `json:"code"`
-	Message string  `json:"message"`
-	Detail  *string `json:"detail,omitempty"`
-	err     error
-}
+import (
+	"net/http"
+)
+
+// ErrorCode は API のエラーレスポンスで返す機械可読なコードです。
+// クライアントが分岐に使うため、一度公開した値は変更しません
+type ErrorCode string
 
-func (e *ServiceError) Error() string {
-	return e.Message
+const (
+	ErrorCodeBadRequest           ErrorCode = "BadRequest"
+	ErrorCodeInvalidParameter     ErrorCode = "InvalidParameter"
+	ErrorCodeInvalidDate          ErrorCode = "InvalidDate"
+	ErrorCodeInvalidPeriod        ErrorCode = "InvalidPeriod"
+	ErrorCodeNotFound             ErrorCode = "NotFound"
+	ErrorCodeBusStopNotFound      ErrorCode = "BusStopNotFound"
+	ErrorCodeBusStopGroupNotFound ErrorCode = "BusStopGroupNotFound"
+	ErrorCodeUnauthorized         ErrorCode = "Unauthorized"
+	ErrorCodeMethodNotAllowed     ErrorCode = "MethodNotAllowed"
+	ErrorCodeInternalServerError  ErrorCode = "InternalServerError"
+)
+
+// errorMessages はエラーコードごとの利用者向けメッセージ（言語 → メッセージ）
+var errorMessages = map[ErrorCode]map[string]string{
+	ErrorCodeBadRequest: {
+		"ja": "リクエストが不正です。",
+		"en": "The request is invalid.",
+	},
+	ErrorCodeInvalidParameter: {
+		"ja": "パラメータの値が不正です。",
+		"en": "A parameter has an invalid value.",
+	},
+	ErrorCodeInvalidDate: {
+		"ja": "日付は YYYY-MM-DD 形式で指定してください。",
+		"en": "The date must be in YYYY-MM-DD format.",
+	},
+	ErrorCodeInvalidPeriod: {
+		"ja": "from と to には 366 日以内の正しい期間を指定してください。",
+		"en": "The 'from' and 'to' queries must form a valid period of at most 366 days.",
+	},
+	ErrorCodeNotFound: {
+		"ja": "指定されたリソースは存在しません。",
+		"en": "The requested resource does not exist.",
+	},
+	ErrorCodeBusStopNotFound: {
+		"ja": "指定されたバス停は存在しません。",
+		"en": "The requested bus stop does not exist.",
+	},
+	ErrorCodeBusStopGroupNotFound: {
+		"ja": "指定されたバス停グループは存在しません。",
+		"en": "The requested bus stop group does not exist.",
+	},
+	ErrorCodeUnauthorized: {
+		"ja": "認証が必要です。",
+		"en": "Authentication is required.",
+	},
+	ErrorCodeMethodNotAllowed: {
+		"ja": "このメソッドは使用できません。",
+		"en": "The method is not allowed for this resource.",
+	},
+	ErrorCodeInternalServerError: {
+		"ja": "サーバー内部でエラーが発生しました。",
+		"en": "An unexpected internal error occurred.",
+	},
 }
 
-func (e *ServiceError) Is(target error) bool {
-	if targetErr, ok := target.(*ServiceError); ok {
-		return e.Code == targetErr.Code
+// DefaultLanguage は Accept-Language で対応言語が指定されなかったときの言語
+const DefaultLanguage = "ja"
+
+// Message は指定した言語でのメッセージを返します。未対応の言語は DefaultLanguage で返します
+func (c ErrorCode) Message(lang string) string {
+	messages, ok := errorMessages[c]
+	if !ok {
+		messages = errorMessages[ErrorCodeInternalServerError]
 	}
-	return false
+	if m, ok := messages[lang]; ok {
+		return m
+	}
+	return messages[DefaultLanguage]
 }
 
-func (e *ServiceError) Unwrap() error {
-	return e.err
+// SupportsLanguage はエラーメッセージがその言語に対応しているかを返します
+func SupportsLanguage(lang string) bool {
+	_, ok := errorMessages[ErrorCodeInternalServerError][lang]
+	return ok
 }
 
-type NotFoundError struct {
-	Code    string  `json:"code"`
-	Message string  `json:"message"`
-	Detail  *string `json:"detail,omitempty"`
-	err     error
+// Error は domain / usecase / handler で共通して使うエラーです。
+// ErrorHandlingMiddleware が HTTP ステータスとエラーレスポンスに変換します
+type Error struct {
+	Status int       // HTTP ステータスコード
+	Code   ErrorCode // 機械可読なコード
+	Field  string    // 原因となったパラメータ（例: query.date）。ない場合は空
+	err    error
 }
 
-func (e *NotFoundError) Error() string {
-	return e.Message
+func (e *Error) Error() string {
+	msg := string(e.Code)
+	if e.Field != "" {
+		msg += " (" + e.Field + ")"
+	}
+	if e.err != nil {
+		msg += ": " + e.err.Error()
+	}
+	return msg
 }
 
-func (e *NotFoundError) Is(target error) bool {
-	if targetErr, ok := target.(*NotFoundError); ok {
+// Is はコードが同じエラーを同一とみなします（errors.Is(err, ErrInvalidDate) など）
+func (e *Error) Is(target error) bool {
+	if targetErr, ok := target.(*Error); ok {
 		return e.Code == targetErr.Code
 	}
 	return false
 }
 
-func (e *NotFoundError) Unwrap() error {
+func (e *Error) Unwrap() error {
 	return e.err
 }
 
-func NewNotFoundError(message string, detail *string, err error) *NotFoundError {
-	return &NotFoundError{
-		Code:    "NotFound",
-		Message: message,
-		Detail:  detail,
-		err:     err,
+// NewError は原因となったエラー err（nil 可）を包んだエラーを返します
+func NewError(status int, code ErrorCode, field string, err error) *Error {
+	return &Error{
+		Status: status,
+		Code:   code,
+		Field:  field,
+		err:    err,
 	}
 }
+
+// NewNotFoundError は 404 Not Found のエラーを返します
+func NewNotFoundError(code ErrorCode, field string) *Error {
+	return NewError(http.StatusNotFound, code, field, nil)
+}
+
+// NewBadRequestError は 400 Bad Request のエラーを返します
+func NewBadRequestError(code ErrorCode, field string, err error) *Error {
+	return NewError(http.StatusBadRequest, code, field, err)
+}
+
+// NewInternalError は想定外のエラーを 500 Internal Server Error として包みます
+func NewInternalError(err error) *Error {
+	return NewError(http.StatusInternalServerError, ErrorCodeInternalServerError, "", err)
+}
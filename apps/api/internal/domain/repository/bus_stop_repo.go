@@ -1,10 +1,13 @@
 package repository
 
-import "api/internal/domain"
+import (
+	"api/internal/domain"
+	"context"
+)
 
+// BusStopRepository はバス停データの読み込みを受け持ちます。
+// 読み込んだデータは usecase がサービスデータと一緒に保持し、リクエストごとの引き当てもそこから行います
 type BusStopRepository interface {
-	GetAllBusStops() ([]domain.BusStop, error)
-	GetAllBusStopGroups() ([]domain.BusStopGroup, error)
-	GetBusStopGroupByID(id int32) (*domain.BusStopGroup, error)
-	GetBusStopByID(id int32) (*domain.BusStop, error)
+	// LoadBusStopData はバス停データを読み込んで検証します
+	LoadBusStopData(ctx context.Context) (*domain.BusStopData, error)
 }
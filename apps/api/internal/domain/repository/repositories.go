@@ -2,5 +2,5 @@ package repository
 
 type Repositories struct {
 	BusStop BusStopRepository
-	Service ServiceRepository
+	Service ServiceStore
 }
@@ -1,7 +0,0 @@
-package repository
-
-import "api/internal/domain"
-
-type ServiceRepository interface {
-	LoadAllServices() ([]domain.ServiceData, error)
-}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"api/internal/domain"
+	"context"
+)
+
+// ServiceStore はサービスデータの読み込みと、読み込み済みのデータ（スナップショット）の保持を受け持ちます。
+// すべてのメソッドは複数の goroutine から同時に呼べます
+type ServiceStore interface {
+	// Load はサービスデータをファイルから読み込みます。Snapshot が返すデータは変わりません
+	Load(ctx context.Context) ([]domain.ServiceData, error)
+	// Replace は以後の Snapshot が返すデータを services に差し替え、新しいスナップショットを返します
+	Replace(services []domain.ServiceData) *domain.ServiceSnapshot
+	// Snapshot は現在のサービスデータを返します。まだ読み込んでいない場合は空のスナップショットです
+	Snapshot() *domain.ServiceSnapshot
+}
@@ -1,40 +1,44 @@
 package domain
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"api/pkg/schedule"
+
 	"github.com/rickar/cal/v2"
-	"github.com/rickar/cal/v2/jp"
 )
 
 // DayType 曜日の種類を定義
-type DayType string
+type DayType = schedule.DayType
 
 const (
-	DayTypeWeekday   DayType = "weekday"
-	DayTypeSaturday  DayType = "saturday"
-	DayTypeSunday    DayType = "sunday"
-	DayTypeWeekend   DayType = "weekend"
-	DayTypeHoliday   DayType = "holiday"
-	DayTypeMonday    DayType = "monday"
-	DayTypeTuesday   DayType = "tuesday"
-	DayTypeWednesday DayType = "wednesday"
-	DayTypeThursday  DayType = "thursday"
-	DayTypeFriday    DayType = "friday"
+	DayTypeWeekday   = schedule.DayTypeWeekday
+	DayTypeSaturday  = schedule.DayTypeSaturday
+	DayTypeSunday    = schedule.DayTypeSunday
+	DayTypeWeekend   = schedule.DayTypeWeekend
+	DayTypeHoliday   = schedule.DayTypeHoliday
+	DayTypeMonday    = schedule.DayTypeMonday
+	DayTypeTuesday   = schedule.DayTypeTuesday
+	DayTypeWednesday = schedule.DayTypeWednesday
+	DayTypeThursday  = schedule.DayTypeThursday
+	DayTypeFriday    = schedule.DayTypeFriday
 )
 
 // SegmentConditionType セグメント条件の種類を定義
-type SegmentConditionType string
+type SegmentConditionType = schedule.ConditionType
 
 const (
-	ConditionTypeDayType        SegmentConditionType = "dayType"
-	ConditionTypeSpecificDate   SegmentConditionType = "specificDate"
-	ConditionTypeSpecificPeriod SegmentConditionType = "specificPeriod"
+	ConditionTypeDayType        = schedule.ConditionTypeDayType
+	ConditionTypeSpecificDate   = schedule.ConditionTypeSpecificDate
+	ConditionTypeSpecificPeriod = schedule.ConditionTypeSpecificPeriod
 )
 
 // ServiceStopRef はバス停の参照情報を表します
@@ -44,18 +48,10 @@ type ServiceStopRef struct {
 }
 
 // ServiceValidityPeriod はサービスの有効期間を表します
-type ServiceValidityPeriod struct {
-	From string `json:"from"`
-	To   string `json:"to"`
-}
+type ServiceValidityPeriod = schedule.Period
 
 // SegmentCondition はセグメントの条件の基本情報を表します
-type SegmentCondition struct {
-	Type  SegmentConditionType `json:"type"`
-	Value string               `json:"value,omitempty"`
-	From  string               `json:"from,omitempty"`
-	To    string               `json:"to,omitempty"`
-}
+type SegmentCondition = schedule.Condition
 
 // SegmentConditionDayType は曜日タイプ条件を表します
 type SegmentConditionDayType struct {
@@ -119,70 +115,44 @@ type ServiceData struct {
 	ValidityPeriods []ServiceValidityPeriod `json:"validityPeriods"`
 	Segments        []json.RawMessage       `json:"segments"`
 	ParsedSegments  []interface{}           `json:"-"`
+	// Archived は services/archived から読み込んだ期限切れのサービスかどうか
+	Archived bool `json:"-"`
 }
 
-// serviceCache はサービスデータのキャッシュ
-var serviceCache []ServiceData
-var serviceCacheLocked bool = false
-var cacheEnabled bool = true
-
-// 日本の祝日カレンダー（グローバル変数）
-var japaneseCalendar *cal.Calendar
-
-// init 関数で日本の祝日カレンダーを初期化
-func init() {
-	japaneseCalendar = &cal.Calendar{}
-
-	// 日本の祝日を追加
-	japaneseCalendar.AddHoliday(
-		jp.NewYear,
-		jp.ComingOfAgeDay,
-		jp.NationalFoundationDay,
-		jp.TheEmperorsBirthday,
-		jp.VernalEquinoxDay,
-		jp.ShowaDay,
-		jp.ConstitutionMemorialDay,
-		jp.GreeneryDay,
-		jp.ChildrensDay,
-		jp.MarineDay,
-		jp.MountainDay,
-		jp.RespectForTheAgedDay,
-		jp.AutumnalEquinoxDay,
-		jp.SportsDay,
-		jp.CultureDay,
-		jp.LaborThanksgivingDay,
-	)
+// ServiceSnapshot はある時点で読み込まれていたサービスデータです。作成後は変更しないため、複数の goroutine から同時に読めます
+type ServiceSnapshot struct {
+	Services []ServiceData
+	// LoadedAt は読み込んだ時刻。まだ読み込んでいない場合はゼロ値です
+	LoadedAt time.Time
 }
 
 // IsHoliday は指定された日付が日本の祝日かどうかを判定します
 func IsHoliday(date time.Time) bool {
-	actual, observed, _ := japaneseCalendar.IsHoliday(date)
-	return actual || observed
+	return schedule.IsHoliday(date)
 }
 
 // IsWeekend は指定された曜日が週末（土日）かどうかを判定します
 func IsWeekend(dayType DayType) bool {
-	return dayType == DayTypeSaturday || dayType == DayTypeSunday
-}
-
-// SetCacheEnabled はキャッシュの有効/無効を設定します（開発環境ではfalseを推奨）
-func SetCacheEnabled(enabled bool) {
-	cacheEnabled = enabled
-	if !enabled {
-		// キャッシュを無効化する場合、既存のキャッシュもクリア
-		serviceCache = nil
-		serviceCacheLocked = false
-	}
+	return schedule.IsWeekend(dayType)
 }
 
-// LoadServiceData はJSONファイルからサービスデータを読み込みます
+// LoadServiceData は services ディレクトリの JSON ファイルからサービスデータを読み込みます
 func LoadServiceData(dataDir string) ([]ServiceData, error) {
+	return loadServicesFromDir(filepath.Join(dataDir, "services"), false)
+}
 
-	if cacheEnabled && serviceCacheLocked && len(serviceCache) > 0 {
-		return serviceCache, nil
+// LoadArchivedServiceData は services/archived からアーカイブ済みのサービスデータを読み込みます
+// archived ディレクトリがない場合は空のスライスを返します
+func LoadArchivedServiceData(dataDir string) ([]ServiceData, error) {
+	services, err := loadServicesFromDir(filepath.Join(dataDir, "services", "archived"), true)
+	if os.IsNotExist(err) {
+		return nil, nil
 	}
+	return services, err
+}
 
-	servicesDir := filepath.Join(dataDir, "services")
+// loadServicesFromDir はディレクトリ直下の JSON ファイルをサービスデータとして読み込みます
+func loadServicesFromDir(servicesDir string, archived bool) ([]ServiceData, error) {
 	files, err := os.ReadDir(servicesDir)
 	if err != nil {
 		return nil, err
@@ -204,6 +174,7 @@ func LoadServiceData(dataDir string) ([]ServiceData, error) {
 		if err := json.Unmarshal(data, &service); err != nil {
 			return nil, err
 		}
+		service.Archived = archived
 
 		for _, segmentRaw := range service.Segments {
 			var segmentBase ServiceSegment
@@ -231,130 +202,57 @@ func LoadServiceData(dataDir string) ([]ServiceData, error) {
 
 		services = append(services, service)
 	}
-
-	if cacheEnabled {
-		serviceCache = services
-		serviceCacheLocked = true
-	}
-
 	return services, nil
 }
 
-// IsValidForDate は指定された日付にサービスが有効かどうかを確認します
-func (s *ServiceData) IsValidForDate(date time.Time) bool {
-	if len(s.ValidityPeriods) == 0 {
-		return true
+// DatasetVersion はサービスデータとバス停データの内容から求めたバージョン（SHA-256 の先頭 12 桁）を返します。
+// 同じデータなら同じ値になるため、ログやキャッシュのキーに使えます
+func DatasetVersion(services []ServiceData, busStops *BusStopData) string {
+	h := sha256.New()
+	if busStops != nil {
+		if data, err := json.Marshal(busStops); err == nil {
+			h.Write(data)
+			h.Write([]byte{'\n'})
+		}
 	}
-
-	dateStr := date.Format("2006-01-02")
-	for _, period := range s.ValidityPeriods {
-		if period.From != "" && period.To != "" {
-			if dateStr >= period.From && dateStr <= period.To {
-				return true
-			}
-		} else if period.From != "" {
-			if dateStr >= period.From {
-				return true
-			}
-		} else if period.To != "" {
-			if dateStr <= period.To {
-				return true
-			}
+	for _, service := range services {
+		data, err := json.Marshal(service)
+		if err != nil {
+			continue
+		}
+		h.Write(data)
+		if service.Archived {
+			h.Write([]byte("archived"))
 		}
+		h.Write([]byte{'\n'})
 	}
-	return false
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}
+
+// IsValidForDate は指定された日付にサービスが有効かどうかを確認します
+func (s *ServiceData) IsValidForDate(date time.Time) bool {
+	return schedule.PeriodsInclude(s.ValidityPeriods, date)
 }
 
 // GetDayType は指定された日付の曜日タイプを返します
 func GetDayType(date time.Time) DayType {
-	// rickar/calを使用して祝日チェックを最初に行う
-	if IsHoliday(date) {
-		return DayTypeHoliday
-	}
-
-	weekday := date.Weekday()
-
-	switch weekday {
-	case time.Monday:
-		return DayTypeMonday
-	case time.Tuesday:
-		return DayTypeTuesday
-	case time.Wednesday:
-		return DayTypeWednesday
-	case time.Thursday:
-		return DayTypeThursday
-	case time.Friday:
-		return DayTypeFriday
-	case time.Saturday:
-		return DayTypeSaturday
-	case time.Sunday:
-		return DayTypeSunday
-	default:
-		return DayTypeWeekday // 念のため
-	}
+	return schedule.DayTypeOf(date)
 }
 
 // IsWeekday は指定された曜日が平日（月〜金）かどうかを判定します
 func IsWeekday(dayType DayType) bool {
-	return dayType == DayTypeMonday || dayType == DayTypeTuesday ||
-		dayType == DayTypeWednesday || dayType == DayTypeThursday || dayType == DayTypeFriday
+	return schedule.IsWeekday(dayType)
 }
 
 // IsSegmentValidForDate は指定された日付にこのセグメントが有効かどうかを判断します
 func IsSegmentValidForDate(condition SegmentCondition, date time.Time) bool {
-	dateStr := date.Format("2006-01-02")
-	dayType := GetDayType(date)
-
-	switch condition.Type {
-	case ConditionTypeDayType:
-		// 特別ケース: "weekday" は月〜金のどれかに一致するか（祝日除く）
-		if condition.Value == string(DayTypeWeekday) {
-			return IsWeekday(dayType) && dayType != DayTypeHoliday
-		}
-		// 特別ケース: "weekend" は土日のどれかに一致するか
-		if condition.Value == string(DayTypeWeekend) {
-			return IsWeekend(dayType)
-		}
-		// 特別ケース: "holiday" は祝日に一致するか
-		if condition.Value == string(DayTypeHoliday) {
-			return dayType == DayTypeHoliday
-		}
-		return condition.Value == string(dayType)
-
-	case ConditionTypeSpecificDate:
-		return dateStr == condition.Value
-
-	case ConditionTypeSpecificPeriod:
-		if condition.From != "" && condition.To != "" {
-			return dateStr >= condition.From && dateStr <= condition.To
-		} else if condition.From != "" {
-			return dateStr >= condition.From
-		} else if condition.To != "" {
-			return dateStr <= condition.To
-		}
-	}
-
-	// 後方互換性のため
-	if condition.Type == "" && condition.Value != "" {
-		if condition.Value == string(DayTypeWeekday) {
-			return IsWeekday(dayType) && dayType != DayTypeHoliday
-		}
-		if condition.Value == string(DayTypeWeekend) {
-			return IsWeekend(dayType)
-		}
-		if condition.Value == string(DayTypeHoliday) {
-			return dayType == DayTypeHoliday
-		}
-		return condition.Value == string(dayType)
-	}
-
-	return true
+	return condition.Applies(date)
 }
 
 // ErrInvalidDate は無効な日付形式エラーを表します
-var ErrInvalidDate = &ServiceError{
-	Code:    "InvalidDate",
-	Message: "The date format is invalid.",
+var ErrInvalidDate = &Error{
+	Status: http.StatusBadRequest,
+	Code:   ErrorCodeInvalidDate,
 }
 
 // ParseDateString は日付文字列をtime.Time型に変換します
@@ -376,7 +274,7 @@ func GetHolidaysInRange(startDate, endDate time.Time) []*cal.Holiday {
 
 	current := startDate
 	for !current.After(endDate) {
-		if actual, observed, h := japaneseCalendar.IsHoliday(current); (actual || observed) && h != nil {
+		if h := schedule.Holiday(current); h != nil {
 			holidays = append(holidays, h)
 		}
 		current = current.AddDate(0, 0, 1)
@@ -392,7 +290,7 @@ func GetNextHoliday(from time.Time) *cal.Holiday {
 	endDate := from.AddDate(1, 0, 0)
 
 	for !current.After(endDate) {
-		if actual, observed, h := japaneseCalendar.IsHoliday(current); (actual || observed) && h != nil {
+		if h := schedule.Holiday(current); h != nil {
 			return h
 		}
 		current = current.AddDate(0, 0, 1)
@@ -4,7 +4,10 @@ import (
 	"api/internal/domain"
 	"api/internal/dto"
 	"api/internal/usecase"
+	"api/pkg/board"
 	"api/pkg/oapi"
+	"bytes"
+	"errors"
 	"net/http"
 	"time"
 
@@ -13,21 +16,24 @@ import (
 
 type BusStopHandler struct {
 	busStopUsecase usecase.BusStopUseCase
+	// location は「今日」の判定に使うタイムゾーン
+	location *time.Location
 }
 
-func NewBusStopHandler(busStopUsecase usecase.BusStopUseCase) *BusStopHandler {
+func NewBusStopHandler(busStopUsecase usecase.BusStopUseCase, loc *time.Location) *BusStopHandler {
 	return &BusStopHandler{
 		busStopUsecase: busStopUsecase,
+		location:       loc,
 	}
 }
 
 func (h *BusStopHandler) GetBusStops(ctx echo.Context, groupID *int32) error {
-	busStops, err := h.busStopUsecase.GetBusStops(groupID)
+	busStops, err := h.busStopUsecase.GetBusStops(ctx.Request().Context(), groupID)
 	if err != nil {
-		return err
+		return withField(err, "query.group_id")
 	}
 
-	var models []oapi.ModelsBusStop
+	models := []oapi.ModelsBusStop{}
 	for _, busStop := range busStops {
 		model := dto.DomainBusStopToModelBusStop(busStop)
 		if model != nil {
@@ -39,12 +45,12 @@ func (h *BusStopHandler) GetBusStops(ctx echo.Context, groupID *int32) error {
 }
 
 func (h *BusStopHandler) GetBusStopGroups(ctx echo.Context) error {
-	busStopGroups, err := h.busStopUsecase.GetBusStopGroups()
+	busStopGroups, err := h.busStopUsecase.GetBusStopGroups(ctx.Request().Context())
 	if err != nil {
 		return err
 	}
 
-	var models []oapi.ModelsBusStopGroup
+	models := []oapi.ModelsBusStopGroup{}
 	for _, busStopGroup := range busStopGroups {
 		model := dto.DomainBusStopGroupToModelBusStopGroup(busStopGroup)
 		if model != nil {
@@ -56,140 +62,149 @@ func (h *BusStopHandler) GetBusStopGroups(ctx echo.Context) error {
 }
 
 func (h *BusStopHandler) GetBusStopDetails(ctx echo.Context, id int32) error {
-	busStop, err := h.busStopUsecase.GetBusStopByID(id)
+	busStop, err := h.busStopUsecase.GetBusStopByID(ctx.Request().Context(), id)
 	if err != nil {
-		if _, ok := err.(*domain.NotFoundError); ok {
-			return ctx.JSON(http.StatusNotFound, map[string]interface{}{
-				"code":    "NotFound",
-				"message": "BusStopNotFound",
-				"detail":  "The requested bus stop does not exist.",
-			})
-		}
-		return err
+		return withField(err, "path.id")
 	}
 
 	model := dto.DomainBusStopToModelBusStop(*busStop)
 	if model == nil {
-		return ctx.JSON(http.StatusNotFound, map[string]interface{}{
-			"code":    "NotFound",
-			"message": "BusStopNotFound",
-			"detail":  "The requested bus stop does not exist.",
-		})
+		return domain.NewNotFoundError(domain.ErrorCodeBusStopNotFound, "path.id")
 	}
 
 	return ctx.JSON(http.StatusOK, model)
 }
 
 func (h *BusStopHandler) GetBusStopTimetable(ctx echo.Context, id int32, params oapi.BusStopServiceGetBusStopTimetableParams) error {
-	_, err := h.busStopUsecase.GetBusStopByID(id)
+	_, err := h.busStopUsecase.GetBusStopByID(ctx.Request().Context(), id)
 	if err != nil {
-		if _, ok := err.(*domain.NotFoundError); ok {
-			return ctx.JSON(http.StatusNotFound, map[string]interface{}{
-				"code":    "NotFound",
-				"message": "BusStopNotFound",
-				"detail":  "The requested bus stop does not exist.",
-			})
-		}
-		return err
+		return withField(err, "path.id")
 	}
-	dateStr := ""
 
-	if params.Date != nil {
-		dateStr = params.Date.String()
-	}
+	params.Date = dateOrToday(params.Date, h.location)
 
-	if dateStr != "" {
-		t, err := time.Parse("2006-01-02", dateStr)
-		if err != nil {
-			return ctx.JSON(http.StatusBadRequest, map[string]interface{}{
-				"code":    "BadRequest",
-				"message": "InvalidDate",
-				"detail":  "The 'date' query must be in YYYY-MM-DD format.",
-			})
-		}
-		date := oapi.ScalarsDateISO{}
-		date.Time = t
-		params.Date = &date
-	} else {
-		now := time.Now()
-		date := oapi.ScalarsDateISO{}
-		date.Time = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-		params.Date = &date
-	}
+	rctx := ctx.Request().Context()
+	archived := usecase.UseArchived(params.Date.Time, params.IncludeHistorical, h.location)
+	etag := func(version string) string { return timetableETag(version, "stop", id, params.Date.Time, archived) }
+	return writeTimetable(ctx, etag, h.busStopUsecase.DatasetVersion(rctx), params.Date.Time, h.location, params.IfNoneMatch, func() (*oapi.ModelsBusStopTimetable, string, error) {
+		return h.busStopUsecase.GetBusStopTimetable(rctx, id, params.Date, params.IncludeHistorical)
+	})
+}
 
-	timetable, err := h.busStopUsecase.GetBusStopTimetable(id, params.Date)
+func (h *BusStopHandler) GetBusStopGroupsTimetable(ctx echo.Context, id int32, params oapi.BusStopGroupsServiceGetBusStopGroupsTimetableParams) error {
+	_, err := h.busStopUsecase.GetBusStopGroupByID(ctx.Request().Context(), id)
 	if err != nil {
-		return err
+		return withField(err, "path.id")
 	}
 
-	return ctx.JSON(http.StatusOK, timetable)
+	params.Date = dateOrToday(params.Date, h.location)
+
+	rctx := ctx.Request().Context()
+	archived := usecase.UseArchived(params.Date.Time, params.IncludeHistorical, h.location)
+	etag := func(version string) string { return timetableETag(version, "group", id, params.Date.Time, archived) }
+	return writeTimetable(ctx, etag, h.busStopUsecase.DatasetVersion(rctx), params.Date.Time, h.location, params.IfNoneMatch, func() (*oapi.ModelsBusStopGroupTimetable, string, error) {
+		return h.busStopUsecase.GetBusStopGroupTimetable(rctx, id, params.Date, params.IncludeHistorical)
+	})
 }
 
-func (h *BusStopHandler) GetBusStopGroupsTimetable(ctx echo.Context, id int32, params oapi.BusStopGroupsServiceGetBusStopGroupsTimetableParams) error {
-	_, err := h.busStopUsecase.GetBusStopGroupByID(id)
+func (h *BusStopHandler) GetBusStopGroupDetails(ctx echo.Context, id int32) error {
+	busStopGroup, err := h.busStopUsecase.GetBusStopGroupByID(ctx.Request().Context(), id)
 	if err != nil {
-		if _, ok := err.(*domain.NotFoundError); ok {
-			return ctx.JSON(http.StatusNotFound, map[string]interface{}{
-				"code":    "NotFound",
-				"message": "BusStopGroupNotFound",
-				"detail":  "The requested bus stop group does not exist.",
-			})
-		}
-		return err
+		return withField(err, "path.id")
+	}
+
+	model := dto.DomainBusStopGroupToModelBusStopGroup(*busStopGroup)
+	if model == nil {
+		return domain.NewNotFoundError(domain.ErrorCodeBusStopGroupNotFound, "path.id")
 	}
 
-	dateStr := ""
-	if params.Date != nil {
-		dateStr = params.Date.String()
+	return ctx.JSON(http.StatusOK, model)
+}
+
+// maxBoardDays は掲示用時刻表で指定できる期間の上限（日数）
+const maxBoardDays = 366
+
+// boardPeriod は date / from / to クエリから掲示用時刻表の期間を求めます。
+// いずれも省略時は今日 1 日分です。組み合わせが不正な場合は InvalidPeriod エラーを返します。
+func (h *BusStopHandler) boardPeriod(date, from, to *oapi.ScalarsDateISO) (start, end time.Time, err error) {
+	invalid := func(field string) (time.Time, time.Time, error) {
+		return time.Time{}, time.Time{}, domain.NewBadRequestError(domain.ErrorCodeInvalidPeriod, field, nil)
+	}
+	if date != nil && (from != nil || to != nil) {
+		return invalid("query.date")
 	}
-	if dateStr != "" {
-		t, err := time.Parse("2006-01-02", dateStr)
-		if err != nil {
-			return ctx.JSON(http.StatusBadRequest, map[string]interface{}{
-				"code":    "BadRequest",
-				"message": "InvalidDate",
-				"detail":  "The provided date is invalid. Please use YYYY-MM-DD format.",
-			})
+	if from == nil && to != nil {
+		return invalid("query.from")
+	}
+	if from != nil && to == nil {
+		return invalid("query.to")
+	}
+
+	if from != nil {
+		start, end = from.Time, to.Time
+		if end.Before(start) || end.Sub(start) >= maxBoardDays*24*time.Hour {
+			return invalid("query.to")
 		}
-		date := oapi.ScalarsDateISO{}
-		date.Time = t
-		params.Date = &date
-	} else {
-		now := time.Now()
-		date := oapi.ScalarsDateISO{}
-		date.Time = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-		params.Date = &date
+		return start, end, nil
+	}
+
+	d := dateOrToday(date, h.location)
+	return d.Time, d.Time, nil
+}
+
+// dateOrToday は date クエリの値を返します。省略時は loc での今日です。
+// 日付の形式は OpenAPIMiddleware とパラメータのバインドで検証済みです
+func dateOrToday(date *oapi.ScalarsDateISO, loc *time.Location) *oapi.ScalarsDateISO {
+	if date != nil {
+		return date
+	}
+	return &oapi.ScalarsDateISO{Time: usecase.Today(loc)}
+}
+
+// withField は err が domain.Error で原因のパラメータが未設定なら、field を設定したコピーを返します。
+// usecase が共有のエラー値を返しても書き換えないよう、元のエラーは変更しません
+func withField(err error, field string) error {
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) && domainErr.Field == "" {
+		withField := *domainErr
+		withField.Field = field
+		return &withField
+	}
+	return err
+}
+
+func writeBoard(ctx echo.Context, b *board.Board) error {
+	var buf bytes.Buffer
+	if err := b.WriteHTML(&buf); err != nil {
+		return err
 	}
+	return ctx.HTMLBlob(http.StatusOK, buf.Bytes())
+}
 
-	timetable, err := h.busStopUsecase.GetBusStopGroupTimetable(id, params.Date)
+func (h *BusStopHandler) GetBusStopPrintableTimetable(ctx echo.Context, id int32, params oapi.BusStopServiceGetBusStopPrintableTimetableParams) error {
+	start, end, err := h.boardPeriod(params.Date, params.From, params.To)
 	if err != nil {
 		return err
 	}
 
-	return ctx.JSON(http.StatusOK, timetable)
+	b, err := h.busStopUsecase.GetBusStopBoard(ctx.Request().Context(), id, start, end, params.IncludeHistorical)
+	if err != nil {
+		return withField(err, "path.id")
+	}
+
+	return writeBoard(ctx, b)
 }
 
-func (h *BusStopHandler) GetBusStopGroupDetails(ctx echo.Context, id int32) error {
-	busStopGroup, err := h.busStopUsecase.GetBusStopGroupByID(id)
+func (h *BusStopHandler) GetBusStopGroupPrintableTimetable(ctx echo.Context, id int32, params oapi.BusStopGroupsServiceGetBusStopGroupPrintableTimetableParams) error {
+	start, end, err := h.boardPeriod(params.Date, params.From, params.To)
 	if err != nil {
-		if _, ok := err.(*domain.NotFoundError); ok {
-			return ctx.JSON(http.StatusNotFound, map[string]interface{}{
-				"code":    "NotFound",
-				"message": "BusStopGroupNotFound",
-				"detail":  "The requested bus stop group does not exist.",
-			})
-		}
 		return err
 	}
 
-	model := dto.DomainBusStopGroupToModelBusStopGroup(*busStopGroup)
-	if model == nil {
-		return ctx.JSON(http.StatusNotFound, map[string]interface{}{
-			"code":    "NotFound",
-			"message": "BusStopGroupNotFound",
-			"detail":  "The requested bus stop group does not exist.",
-		})
+	b, err := h.busStopUsecase.GetBusStopGroupBoard(ctx.Request().Context(), id, start, end, params.IncludeHistorical)
+	if err != nil {
+		return withField(err, "path.id")
 	}
 
-	return ctx.JSON(http.StatusOK, model)
+	return writeBoard(ctx, b)
 }
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"api/internal/domain"
+	"api/internal/usecase"
+	"api/pkg/board"
+	"api/pkg/oapi"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// stubBusStopUseCase は必要なメソッドだけを差し替えた BusStopUseCase です。
+// 差し替えていないメソッドを呼ぶと panic します
+type stubBusStopUseCase struct {
+	usecase.BusStopUseCase
+	getBoard      func(id int32, from, to time.Time) (*board.Board, error)
+	getGroupBoard func(id int32, from, to time.Time) (*board.Board, error)
+	status        usecase.DatasetStatus
+}
+
+func (s *stubBusStopUseCase) DatasetStatus(context.Context) usecase.DatasetStatus {
+	return s.status
+}
+
+func (s *stubBusStopUseCase) GetBusStopBoard(_ context.Context, id int32, from, to time.Time, _ *bool) (*board.Board, error) {
+	return s.getBoard(id, from, to)
+}
+
+func (s *stubBusStopUseCase) GetBusStopGroupBoard(_ context.Context, id int32, from, to time.Time, _ *bool) (*board.Board, error) {
+	return s.getGroupBoard(id, from, to)
+}
+
+func isoDate(s string) *oapi.ScalarsDateISO {
+	d, err := time.ParseInLocation("2006-01-02", s, time.Local)
+	if err != nil {
+		panic(err)
+	}
+	return &oapi.ScalarsDateISO{Time: d}
+}
+
+func newTestContext(target string) (echo.Context, *httptest.ResponseRecorder) {
+	rec := httptest.NewRecorder()
+	return echo.New().NewContext(httptest.NewRequest(http.MethodGet, target, nil), rec), rec
+}
+
+func TestGetBusStopPrintableTimetable_Period(t *testing.T) {
+	today := dateOrToday(nil, time.Local).Time
+	tests := []struct {
+		name             string
+		params           oapi.BusStopServiceGetBusStopPrintableTimetableParams
+		wantFrom, wantTo time.Time
+	}{
+		{"date", oapi.BusStopServiceGetBusStopPrintableTimetableParams{Date: isoDate("2026-04-29")}, isoDate("2026-04-29").Time, isoDate("2026-04-29").Time},
+		{"from/to", oapi.BusStopServiceGetBusStopPrintableTimetableParams{From: isoDate("2026-04-01"), To: isoDate("2026-07-31")}, isoDate("2026-04-01").Time, isoDate("2026-07-31").Time},
+		{"today", oapi.BusStopServiceGetBusStopPrintableTimetableParams{}, today, today},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotFrom, gotTo time.Time
+			h := NewBusStopHandler(&stubBusStopUseCase{getBoard: func(id int32, from, to time.Time) (*board.Board, error) {
+				gotFrom, gotTo = from, to
+				b := board.ForDate("八王子駅", from, nil)
+				return &b, nil
+			}}, time.Local)
+
+			ctx, rec := newTestContext("/api/bus-stops/1/timetable/print")
+			if err := h.GetBusStopPrintableTimetable(ctx, 1, tt.params); err != nil {
+				t.Fatal(err)
+			}
+			if !gotFrom.Equal(tt.wantFrom) || !gotTo.Equal(tt.wantTo) {
+				t.Errorf("period = %s〜%s, want %s〜%s", gotFrom, gotTo, tt.wantFrom, tt.wantTo)
+			}
+			if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get(echo.HeaderContentType), echo.MIMETextHTML) {
+				t.Errorf("status = %d, Content-Type = %q", rec.Code, rec.Header().Get(echo.HeaderContentType))
+			}
+			if !strings.Contains(rec.Body.String(), "八王子駅") {
+				t.Errorf("body does not contain the stop name:\n%s", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetBusStopPrintableTimetable_InvalidPeriod(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    oapi.BusStopServiceGetBusStopPrintableTimetableParams
+		wantField string
+	}{
+		{"date with from", oapi.BusStopServiceGetBusStopPrintableTimetableParams{Date: isoDate("2026-04-29"), From: isoDate("2026-04-01")}, "query.date"},
+		{"to without from", oapi.BusStopServiceGetBusStopPrintableTimetableParams{To: isoDate("2026-04-01")}, "query.from"},
+		{"from without to", oapi.BusStopServiceGetBusStopPrintableTimetableParams{From: isoDate("2026-04-01")}, "query.to"},
+		{"to before from", oapi.BusStopServiceGetBusStopPrintableTimetableParams{From: isoDate("2026-04-02"), To: isoDate("2026-04-01")}, "query.to"},
+		{"too long", oapi.BusStopServiceGetBusStopPrintableTimetableParams{From: isoDate("2026-04-01"), To: isoDate("2027-04-02")}, "query.to"},
+	}
+	h := NewBusStopHandler(&stubBusStopUseCase{getBoard: func(int32, time.Time, time.Time) (*board.Board, error) {
+		t.Error("usecase called with an invalid period")
+		return nil, nil
+	}}, time.Local)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := newTestContext("/api/bus-stops/1/timetable/print")
+			err := h.GetBusStopPrintableTimetable(ctx, 1, tt.params)
+			var domainErr *domain.Error
+			if !errors.As(err, &domainErr) || domainErr.Code != domain.ErrorCodeInvalidPeriod || domainErr.Field != tt.wantField {
+				t.Errorf("err = %v, want InvalidPeriod (%s)", err, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestGetBusStopGroupPrintableTimetable_NotFound(t *testing.T) {
+	h := NewBusStopHandler(&stubBusStopUseCase{getGroupBoard: func(int32, time.Time, time.Time) (*board.Board, error) {
+		return nil, domain.NewNotFoundError(domain.ErrorCodeBusStopGroupNotFound, "")
+	}}, time.Local)
+
+	ctx, _ := newTestContext("/api/bus-stops/groups/99/timetable/print")
+	err := h.GetBusStopGroupPrintableTimetable(ctx, 99, oapi.BusStopGroupsServiceGetBusStopGroupPrintableTimetableParams{Date: isoDate("2026-04-29")})
+	var domainErr *domain.Error
+	if !errors.As(err, &domainErr) || domainErr.Code != domain.ErrorCodeBusStopGroupNotFound || domainErr.Field != "path.id" {
+		t.Errorf("err = %v, want BusStopGroupNotFound (path.id)", err)
+	}
+}
+
+func TestWithField_DoesNotModifyErr(t *testing.T) {
+	shared := domain.NewNotFoundError(domain.ErrorCodeBusStopNotFound, "")
+
+	err := withField(shared, "path.id")
+	var domainErr *domain.Error
+	if !errors.As(err, &domainErr) || domainErr.Field != "path.id" || domainErr.Code != domain.ErrorCodeBusStopNotFound {
+		t.Errorf("withField() = %v, want BusStopNotFound (path.id)", err)
+	}
+	if shared.Field != "" {
+		t.Errorf("original error was modified: Field = %q", shared.Field)
+	}
+
+	withOwn := domain.NewBadRequestError(domain.ErrorCodeInvalidPeriod, "query.to", nil)
+	if got := withField(withOwn, "path.id"); got != error(withOwn) {
+		t.Errorf("withField() = %v, want the error unchanged", got)
+	}
+	plain := errors.New("boom")
+	if got := withField(plain, "path.id"); got != plain {
+		t.Errorf("withField() = %v, want the error unchanged", got)
+	}
+}
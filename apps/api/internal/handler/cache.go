@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// todayMaxAge は今日の時刻表をキャッシュしてよい秒数。
+	// 日付を省略したリクエストは日付が変わると別の日の時刻表になるため短くする
+	todayMaxAge = 60
+	// otherDayMaxAge は今日以外の時刻表をキャッシュしてよい秒数。
+	// データを更新したときは、この秒数が過ぎた後の ETag の再検証で反映される
+	otherDayMaxAge = 5 * 60
+)
+
+// timetableETag はデータのバージョンとリクエストのパラメータから時刻表の ETag を返します。
+// kind はエンドポイントの種類（"stop" / "group"）、archived はアーカイブ済みサービスを使うかどうかです
+func timetableETag(version, kind string, id int32, date time.Time, archived bool) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s/%d/%s/%t", kind, id, date.Format("2006-01-02"), archived)))
+	return fmt.Sprintf(`"%s-%s"`, version, hex.EncodeToString(sum[:8]))
+}
+
+// timetableCacheControl は date の時刻表の Cache-Control を返します
+func timetableCacheControl(date, now time.Time) string {
+	if date.Format("2006-01-02") != now.Format("2006-01-02") {
+		return fmt.Sprintf("public, max-age=%d", otherDayMaxAge)
+	}
+	// 日付が変わった後まで今日の時刻表を使い回さないようにする
+	midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+	maxAge := min(todayMaxAge, int(midnight.Sub(now).Seconds()))
+	return fmt.Sprintf("public, max-age=%d", maxAge)
+}
+
+// setCacheHeaders は時刻表のレスポンスに ETag と Cache-Control を設定します。
+// loc は date が今日かどうかの判定に使うタイムゾーンです
+func setCacheHeaders(ctx echo.Context, etag string, date time.Time, loc *time.Location) {
+	header := ctx.Response().Header()
+	header.Set("ETag", etag)
+	header.Set("Cache-Control", timetableCacheControl(date, time.Now().In(loc)))
+}
+
+// notModified は If-None-Match のいずれかが etag と一致するかを返します。
+// If-None-Match では弱い比較を使うため W/ は無視します
+func notModified(ifNoneMatch *string, etag string) bool {
+	if ifNoneMatch == nil {
+		return false
+	}
+	for _, tag := range strings.Split(*ifNoneMatch, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// writeTimetable は ETag と Cache-Control を付けて時刻表を返します。
+// etag はデータのバージョンから ETag を返します。If-None-Match が現在のバージョン version の ETag と
+// 一致すれば本文を計算せずに 304 Not Modified を返します。
+// 本文の ETag には timetable が本文と一緒に返すバージョンを使うため、途中で読み込み直しがあっても食い違いません
+func writeTimetable[T any](ctx echo.Context, etag func(version string) string, version string, date time.Time, loc *time.Location, ifNoneMatch *string, timetable func() (T, string, error)) error {
+	if current := etag(version); notModified(ifNoneMatch, current) {
+		setCacheHeaders(ctx, current, date, loc)
+		return ctx.NoContent(http.StatusNotModified)
+	}
+
+	body, bodyVersion, err := timetable()
+	if err != nil {
+		return err
+	}
+
+	setCacheHeaders(ctx, etag(bodyVersion), date, loc)
+	return ctx.JSON(http.StatusOK, body)
+}
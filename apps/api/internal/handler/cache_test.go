@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNotModified(t *testing.T) {
+	const etag = `"v1-0123456789abcdef"`
+	header := func(s string) *string { return &s }
+	tests := []struct {
+		name        string
+		ifNoneMatch *string
+		want        bool
+	}{
+		{"no header", nil, false},
+		{"match", header(etag), true},
+		{"weak", header(`W/` + etag), true},
+		{"list", header(`"v0-ffff", ` + etag), true},
+		{"weak in list", header(`"v0-ffff",W/` + etag + ` `), true},
+		{"any", header("*"), true},
+		{"other version", header(`"v0-0123456789abcdef"`), false},
+		{"unquoted", header(`v1-0123456789abcdef`), false},
+		{"empty", header(""), false},
+	}
+	for _, tt := range tests {
+		if got := notModified(tt.ifNoneMatch, etag); got != tt.want {
+			t.Errorf("%s: notModified() = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTimetableCacheControl(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2026, 9, 28, 0, 0, 0, 0, loc)
+	tests := []struct {
+		name string
+		date time.Time
+		now  time.Time
+		want string
+	}{
+		{"other day", day.AddDate(0, 0, 1), day.Add(12 * time.Hour), "public, max-age=300"},
+		{"past day", day.AddDate(0, 0, -1), day.Add(12 * time.Hour), "public, max-age=300"},
+		{"today", day, day.Add(12 * time.Hour), "public, max-age=60"},
+		{"today just before midnight", day, day.Add(24*time.Hour - 30*time.Second), "public, max-age=30"},
+		{"today at the last second", day, day.Add(24*time.Hour - 500*time.Millisecond), "public, max-age=0"},
+	}
+	for _, tt := range tests {
+		if got := timetableCacheControl(tt.date, tt.now); got != tt.want {
+			t.Errorf("%s: timetableCacheControl() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTimetableETag(t *testing.T) {
+	date := time.Date(2026, 9, 28, 0, 0, 0, 0, time.UTC)
+	etag := timetableETag("v1", "stop", 1, date, false)
+	if etag != timetableETag("v1", "stop", 1, date, false) {
+		t.Error("ETag is not stable")
+	}
+	for name, other := range map[string]string{
+		"version":  timetableETag("v2", "stop", 1, date, false),
+		"kind":     timetableETag("v1", "group", 1, date, false),
+		"id":       timetableETag("v1", "stop", 2, date, false),
+		"date":     timetableETag("v1", "stop", 1, date.AddDate(0, 0, 1), false),
+		"archived": timetableETag("v1", "stop", 1, date, true),
+	} {
+		if other == etag {
+			t.Errorf("ETag does not change with the %s", name)
+		}
+	}
+}
+
+func TestWriteTimetable_ETagFromBodyVersion(t *testing.T) {
+	date := time.Date(2026, 9, 28, 0, 0, 0, 0, time.UTC)
+	etag := func(version string) string { return timetableETag(version, "stop", 1, date, false) }
+	// ETag を作った後、本文を作る前に v1 から v2 へ読み込み直された
+	body := func() (map[string]string, string, error) { return map[string]string{"name": "v2"}, "v2", nil }
+
+	ctx, rec := newTestContext("/api/bus-stops/1/timetable")
+	if err := writeTimetable(ctx, etag, "v1", date, time.UTC, nil, body); err != nil {
+		t.Fatal(err)
+	}
+	if got := rec.Header().Get("ETag"); rec.Code != http.StatusOK || got != etag("v2") {
+		t.Errorf("status = %d, ETag = %q, want %q from the body's version", rec.Code, got, etag("v2"))
+	}
+
+	// 現在のバージョンの ETag なら本文を作らずに 304
+	ifNoneMatch := etag("v1")
+	ctx, rec = newTestContext("/api/bus-stops/1/timetable")
+	err := writeTimetable(ctx, etag, "v1", date, time.UTC, &ifNoneMatch, func() (map[string]string, string, error) {
+		t.Error("timetable computed for a matching If-None-Match")
+		return nil, "", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusNotModified || rec.Header().Get("ETag") != ifNoneMatch {
+		t.Errorf("status = %d, ETag = %q, want 304 with %q", rec.Code, rec.Header().Get("ETag"), ifNoneMatch)
+	}
+}
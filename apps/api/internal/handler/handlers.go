@@ -1,13 +1,19 @@
 package handler
 
-import "api/internal/usecase"
+import (
+	"api/internal/usecase"
+	"time"
+)
 
 type Handlers struct {
 	BusStop *BusStopHandler
+	Health  *HealthHandler
 }
 
-func NewHandlers(useCases *usecase.UseCases) *Handlers {
+// NewHandlers はハンドラーを返します。loc は「今日」の判定に使うタイムゾーンです
+func NewHandlers(useCases *usecase.UseCases, loc *time.Location) *Handlers {
 	return &Handlers{
-		BusStop: NewBusStopHandler(useCases.BusStop),
+		BusStop: NewBusStopHandler(useCases.BusStop, loc),
+		Health:  NewHealthHandler(useCases.BusStop, loc),
 	}
 }
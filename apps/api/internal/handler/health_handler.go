@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"api/internal/usecase"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type HealthHandler struct {
+	busStopUsecase usecase.BusStopUseCase
+	location       *time.Location
+}
+
+func NewHealthHandler(busStopUsecase usecase.BusStopUseCase, loc *time.Location) *HealthHandler {
+	return &HealthHandler{
+		busStopUsecase: busStopUsecase,
+		location:       loc,
+	}
+}
+
+// healthResponse は /healthz と /readyz のレスポンス
+type healthResponse struct {
+	Status         string   `json:"status"`
+	DatasetVersion string   `json:"datasetVersion,omitempty"`
+	Services       int      `json:"services"`
+	ValidUntil     string   `json:"validUntil,omitempty"`
+	Problems       []string `json:"problems,omitempty"`
+}
+
+// Liveness はプロセスが応答できるかだけを返します
+func (h *HealthHandler) Liveness(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Readiness はサービスデータが読み込まれていて、有効期間が切れていないときだけ 200 を返します
+func (h *HealthHandler) Readiness(ctx echo.Context) error {
+	status := h.busStopUsecase.DatasetStatus(ctx.Request().Context())
+	res := healthResponse{
+		Status:         "ok",
+		DatasetVersion: status.Version,
+		Services:       status.Services,
+		ValidUntil:     status.ValidUntil,
+	}
+
+	if status.Services == 0 {
+		res.Problems = append(res.Problems, "サービスデータが読み込まれていません")
+	}
+	if status.Expired(usecase.Today(h.location)) {
+		res.Problems = append(res.Problems, fmt.Sprintf("すべてのサービスの有効期間が終了しています（最終日: %s）", status.ValidUntil))
+	}
+
+	if len(res.Problems) > 0 {
+		res.Status = "unavailable"
+		return ctx.JSON(http.StatusServiceUnavailable, res)
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"api/internal/usecase"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestHealthHandler_Readiness(t *testing.T) {
+	// 実行環境のタイムゾーンと日付がずれやすいタイムゾーンで「今日」を判定する
+	loc := time.FixedZone("UTC+14", 14*60*60)
+	today := dateOrToday(nil, loc).Time
+	yesterday := today.AddDate(0, 0, -1).Format("2006-01-02")
+	tests := []struct {
+		name         string
+		status       usecase.DatasetStatus
+		wantStatus   int
+		wantProblems int
+	}{
+		{"valid", usecase.DatasetStatus{Version: "v1", Services: 3, ValidUntil: "2999-12-31"}, http.StatusOK, 0},
+		{"open-ended", usecase.DatasetStatus{Version: "v1", Services: 3}, http.StatusOK, 0},
+		{"last day is today", usecase.DatasetStatus{Version: "v1", Services: 3, ValidUntil: today.Format("2006-01-02")}, http.StatusOK, 0},
+		{"empty", usecase.DatasetStatus{Version: "v1"}, http.StatusServiceUnavailable, 1},
+		{"expired", usecase.DatasetStatus{Version: "v1", Services: 3, ValidUntil: yesterday}, http.StatusServiceUnavailable, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHealthHandler(&stubBusStopUseCase{status: tt.status}, loc)
+			ctx, rec := newTestContext("/readyz")
+			if err := h.Readiness(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+
+			var res healthResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+				t.Fatal(err)
+			}
+			wantBody := "ok"
+			if tt.wantStatus != http.StatusOK {
+				wantBody = "unavailable"
+			}
+			if res.Status != wantBody || len(res.Problems) != tt.wantProblems {
+				t.Errorf("body = %+v, want status %q with %d problem(s)", res, wantBody, tt.wantProblems)
+			}
+			if res.DatasetVersion != tt.status.Version || res.Services != tt.status.Services || res.ValidUntil != tt.status.ValidUntil {
+				t.Errorf("body = %+v, want the dataset status %+v", res, tt.status)
+			}
+		})
+	}
+
+	h := NewHealthHandler(&stubBusStopUseCase{status: usecase.DatasetStatus{ValidUntil: yesterday}}, loc)
+	ctx, rec := newTestContext("/readyz")
+	if err := h.Readiness(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var res healthResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusServiceUnavailable || len(res.Problems) != 2 || !slices.Contains(res.Problems, "サービスデータが読み込まれていません") {
+		t.Errorf("empty and expired: status = %d, problems = %q", rec.Code, res.Problems)
+	}
+}
+
+func TestHealthHandler_Liveness(t *testing.T) {
+	// データの状態によらず 200 を返す
+	h := NewHealthHandler(&stubBusStopUseCase{}, time.UTC)
+	ctx, rec := newTestContext("/healthz")
+	if err := h.Liveness(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || rec.Body.String() != "{\"status\":\"ok\"}\n" {
+		t.Errorf("status = %d, body = %q", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,88 @@
+// Package metrics は Prometheus の /metrics で公開するメトリクスを定義します
+package metrics
+
+import (
+	"api/internal/usecase"
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const namespace = "tutbus_api"
+
+// activeServiceDays は active_services を出す日数（今日から）
+const activeServiceDays = 7
+
+type Metrics struct {
+	Registry *prometheus.Registry
+	// RequestDuration はルート（テンプレート）ごとのレスポンス時間
+	RequestDuration *prometheus.HistogramVec
+	// Errors はエラーレスポンスの件数（エラーコード別）
+	Errors *prometheus.CounterVec
+}
+
+// New はメトリクスを登録したレジストリを返します。loc は active_services の「今日」の判定に使うタイムゾーンです
+func New(busStop usecase.BusStopUseCase, loc *time.Location) *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		RequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route template, method and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		Errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "errors_total",
+			Help:      "Error responses by error code.",
+		}, []string{"code"}),
+	}
+
+	m.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.RequestDuration,
+		m.Errors,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "dataset_age_seconds",
+			Help:      "Seconds since the service dataset was loaded.",
+		}, func() float64 {
+			status := busStop.DatasetStatus(context.Background())
+			if status.LoadedAt.IsZero() {
+				return 0
+			}
+			return time.Since(status.LoadedAt).Seconds()
+		}),
+		&activeServicesCollector{busStop: busStop, location: loc},
+	)
+	return m
+}
+
+// activeServicesCollector はスクレイプのたびに今日から activeServiceDays 日分の運行サービス数を数えます
+type activeServicesCollector struct {
+	busStop usecase.BusStopUseCase
+	// location は「今日」の判定に使うタイムゾーン
+	location *time.Location
+}
+
+var activeServicesDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "active_services"),
+	"Number of services running on the day, by offset from today (0 = today).",
+	[]string{"day_offset"}, nil,
+)
+
+func (c *activeServicesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- activeServicesDesc
+}
+
+func (c *activeServicesCollector) Collect(ch chan<- prometheus.Metric) {
+	today := usecase.Today(c.location)
+	for offset := 0; offset < activeServiceDays; offset++ {
+		count := c.busStop.CountActiveServices(context.Background(), today.AddDate(0, 0, offset))
+		ch <- prometheus.MustNewConstMetric(activeServicesDesc, prometheus.GaugeValue, float64(count), strconv.Itoa(offset))
+	}
+}
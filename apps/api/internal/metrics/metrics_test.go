@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"api/internal/usecase"
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// stubBusStopUseCase はメトリクスが使うメソッドだけを差し替えた BusStopUseCase です
+type stubBusStopUseCase struct {
+	usecase.BusStopUseCase
+	loadedAt time.Time
+	location *time.Location
+}
+
+func (s *stubBusStopUseCase) DatasetStatus(context.Context) usecase.DatasetStatus {
+	return usecase.DatasetStatus{LoadedAt: s.loadedAt}
+}
+
+// CountActiveServices は location での今日からの日数を運行サービス数として返します
+func (s *stubBusStopUseCase) CountActiveServices(_ context.Context, date time.Time) int {
+	return int(date.Sub(usecase.Today(s.location)).Hours() / 24)
+}
+
+func gather(t *testing.T, m *Metrics) map[string]*dto.MetricFamily {
+	t.Helper()
+	families, err := m.Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := map[string]*dto.MetricFamily{}
+	for _, f := range families {
+		byName[f.GetName()] = f
+	}
+	return byName
+}
+
+func TestMetrics_Gather(t *testing.T) {
+	// 実行環境のタイムゾーンと日付がずれやすいタイムゾーンで「今日」を判定する
+	loc := time.FixedZone("UTC+14", 14*60*60)
+	m := New(&stubBusStopUseCase{loadedAt: time.Now().Add(-time.Minute), location: loc}, loc)
+	m.RequestDuration.WithLabelValues("/api/bus-stops/:id", "GET", "200").Observe(0.01)
+	m.Errors.WithLabelValues("BusStopNotFound").Inc()
+	m.Errors.WithLabelValues("BusStopNotFound").Inc()
+
+	families := gather(t, m)
+	for _, name := range []string{
+		"tutbus_api_http_request_duration_seconds",
+		"tutbus_api_errors_total",
+		"tutbus_api_dataset_age_seconds",
+		"tutbus_api_active_services",
+		"go_goroutines",
+	} {
+		if families[name] == nil {
+			t.Errorf("%s is not registered", name)
+		}
+	}
+
+	if f := families["tutbus_api_http_request_duration_seconds"]; f != nil {
+		if got := f.GetMetric()[0].GetHistogram().GetSampleCount(); got != 1 {
+			t.Errorf("request duration sample count = %d, want 1", got)
+		}
+	}
+	if f := families["tutbus_api_errors_total"]; f != nil {
+		if got := f.GetMetric()[0].GetCounter().GetValue(); got != 2 {
+			t.Errorf("errors_total = %g, want 2", got)
+		}
+	}
+	if f := families["tutbus_api_dataset_age_seconds"]; f != nil {
+		if got := f.GetMetric()[0].GetGauge().GetValue(); got < 60 || got > 3600 {
+			t.Errorf("dataset_age_seconds = %g, want about 60", got)
+		}
+	}
+	if f := families["tutbus_api_active_services"]; f != nil {
+		if len(f.GetMetric()) != activeServiceDays {
+			t.Fatalf("active_services has %d series, want %d", len(f.GetMetric()), activeServiceDays)
+		}
+		for _, metric := range f.GetMetric() {
+			label := metric.GetLabel()[0].GetValue()
+			offset, err := strconv.Atoi(label)
+			if err != nil {
+				t.Fatalf("day_offset = %q", label)
+			}
+			if got := metric.GetGauge().GetValue(); got != float64(offset) {
+				t.Errorf("active_services{day_offset=%q} = %g, want %d", label, got, offset)
+			}
+		}
+	}
+}
+
+func TestMetrics_DatasetAgeBeforeLoad(t *testing.T) {
+	families := gather(t, New(&stubBusStopUseCase{location: time.UTC}, time.UTC))
+	if got := families["tutbus_api_dataset_age_seconds"].GetMetric()[0].GetGauge().GetValue(); got != 0 {
+		t.Errorf("dataset_age_seconds = %g before the first load, want 0", got)
+	}
+}
@@ -3,17 +3,19 @@ package repository
 import (
 	"api/internal/config"
 	"api/internal/domain"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 )
 
+// BusStopRepositoryImpl は DataPath の JSON ファイルからバス停データを読み込むリポジトリです
 type BusStopRepositoryImpl struct {
 	config *config.Config
 }
 
-func NewBusStopRepositoryImpl(cfg *config.Config) BusStopRepositoryImpl {
-	return BusStopRepositoryImpl{
+func NewBusStopRepositoryImpl(cfg *config.Config) *BusStopRepositoryImpl {
+	return &BusStopRepositoryImpl{
 		config: cfg,
 	}
 }
@@ -30,54 +32,19 @@ func loadData[T any](filePath string) ([]T, error) {
 	return items, nil
 }
 
-func (r BusStopRepositoryImpl) GetAllBusStops() ([]domain.BusStop, error) {
-	filePath := r.config.GetBusStopsFilePath()
-
-	return loadData[domain.BusStop](filePath)
-}
-
-func (r BusStopRepositoryImpl) GetAllBusStopGroups() ([]domain.BusStopGroup, error) {
-	filePath := r.config.GetBusStopGroupsFilePath()
-
-	return loadData[domain.BusStopGroup](filePath)
-}
-
-func (r BusStopRepositoryImpl) GetBusStopGroupByID(id int32) (*domain.BusStopGroup, error) {
-	busStopGroups, err := r.GetAllBusStopGroups()
+func (r *BusStopRepositoryImpl) LoadBusStopData(ctx context.Context) (*domain.BusStopData, error) {
+	busStops, err := loadData[domain.BusStop](r.config.GetBusStopsFilePath())
 	if err != nil {
 		return nil, err
 	}
-
-	for _, group := range busStopGroups {
-		if group.ID == id {
-			return &group, nil
-		}
-	}
-
-	detail := "The requested bus stop group does not exist."
-	return nil, &domain.NotFoundError{
-		Code:    "NotFound",
-		Message: "BusStopGroupNotFound",
-		Detail:  &detail,
-	}
-}
-
-func (r BusStopRepositoryImpl) GetBusStopByID(id int32) (*domain.BusStop, error) {
-	busStops, err := r.GetAllBusStops()
+	groups, err := loadData[domain.BusStopGroup](r.config.GetBusStopGroupsFilePath())
 	if err != nil {
 		return nil, err
 	}
 
-	for _, busStop := range busStops {
-		if busStop.ID == id {
-			return &busStop, nil
-		}
-	}
-
-	detail := "The requested bus stop does not exist."
-	return nil, &domain.NotFoundError{
-		Code:    "NotFound",
-		Message: "BusStopNotFound",
-		Detail:  &detail,
+	data := &domain.BusStopData{BusStops: busStops, Groups: groups}
+	if err := data.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid bus stop data: %w", err)
 	}
+	return data, nil
 }
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"api/internal/config"
+	"api/internal/domain"
+	"api/internal/reqctx"
+	"context"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ServiceStoreImpl は data/services のサービスデータを読み込み、スナップショットとして保持します。
+// スナップショットは Replace でまとめて差し替えるため、読み込み直しの途中のデータが見えることはありません
+type ServiceStoreImpl struct {
+	dataDir       string
+	serveArchived bool
+	log           *zap.Logger
+	snapshot      atomic.Pointer[domain.ServiceSnapshot]
+}
+
+func NewServiceStoreImpl(cfg *config.Config, log *zap.Logger) *ServiceStoreImpl {
+	s := &ServiceStoreImpl{
+		dataDir:       cfg.GetDataDir(),
+		serveArchived: cfg.ServeArchived,
+		log:           log,
+	}
+	s.snapshot.Store(&domain.ServiceSnapshot{})
+	return s
+}
+
+// Load は services/*.json と services/archived/*.json を読み込みます。
+// アーカイブ済みのサービスは Archived が true になり、使うかどうかは usecase が日付ごとに判断します。
+// 現行のサービスと同じ ID のアーカイブは読み込みません。SERVE_ARCHIVED=false の場合は現行のサービスのみです。
+func (s *ServiceStoreImpl) Load(ctx context.Context) ([]domain.ServiceData, error) {
+	log := reqctx.Logger(ctx, s.log)
+	services, err := domain.LoadServiceData(s.dataDir)
+	if err != nil {
+		log.Error("failed to load service data", zap.Error(err))
+		return nil, err
+	}
+	log.Info("service data loaded successfully from repository", zap.Int("count", len(services)))
+	for _, service := range services {
+		log.Debug("service loaded",
+			zap.String("id", service.ID),
+			zap.String("name", service.Name),
+			zap.Int("segments", len(service.ParsedSegments)))
+	}
+
+	if !s.serveArchived {
+		return services, nil
+	}
+	archived, err := domain.LoadArchivedServiceData(s.dataDir)
+	if err != nil {
+		log.Error("failed to load archived service data", zap.Error(err))
+		return nil, err
+	}
+	log.Info("archived service data loaded successfully from repository", zap.Int("count", len(archived)))
+
+	current := make(map[string]bool, len(services))
+	for _, service := range services {
+		current[service.ID] = true
+	}
+
+	all := make([]domain.ServiceData, 0, len(services)+len(archived))
+	all = append(all, services...)
+	for _, service := range archived {
+		if current[service.ID] {
+			log.Warn("archived service shadowed by current service", zap.String("id", service.ID))
+			continue
+		}
+		all = append(all, service)
+	}
+	return all, nil
+}
+
+func (s *ServiceStoreImpl) Replace(services []domain.ServiceData) *domain.ServiceSnapshot {
+	snapshot := &domain.ServiceSnapshot{
+		Services: services,
+		LoadedAt: time.Now(),
+	}
+	s.snapshot.Store(snapshot)
+	return snapshot
+}
+
+func (s *ServiceStoreImpl) Snapshot() *domain.ServiceSnapshot {
+	return s.snapshot.Load()
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"api/internal/config"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// writeServiceFile は dir/services（archived なら services/archived）にサービスデータを書き込みます
+func writeServiceFile(t *testing.T, dir, id, name string, archived bool) {
+	t.Helper()
+	servicesDir := filepath.Join(dir, "services")
+	if archived {
+		servicesDir = filepath.Join(servicesDir, "archived")
+	}
+	if err := os.MkdirAll(servicesDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	data := fmt.Sprintf(`{
+  "id": %q,
+  "name": %q,
+  "from": {"stopId": 1, "displayName": "八王子駅"},
+  "to": {"stopId": 2, "displayName": "大学"},
+  "direction": "inbound",
+  "validityPeriods": [],
+  "segments": [
+    {"segmentType": "fixed", "condition": {"type": "dayType", "value": "weekday"}, "times": [{"departure": "8:00", "arrival": "8:20"}]}
+  ]
+}`, id, name)
+	if err := os.WriteFile(filepath.Join(servicesDir, id+".json"), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestServiceStore(dir string, serveArchived bool) *ServiceStoreImpl {
+	return NewServiceStoreImpl(&config.Config{DataPath: dir, ServeArchived: serveArchived}, zap.NewNop())
+}
+
+func TestServiceStoreImpl_LoadDoesNotChangeSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	writeServiceFile(t, dir, "a", "A", false)
+	store := newTestServiceStore(dir, true)
+
+	if got := store.Snapshot(); got == nil || len(got.Services) != 0 || !got.LoadedAt.IsZero() {
+		t.Fatalf("initial snapshot = %+v, want empty", got)
+	}
+
+	services, err := store.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(store.Snapshot().Services) != 0 {
+		t.Fatal("Load changed the snapshot before Replace")
+	}
+
+	snapshot := store.Replace(services)
+	if store.Snapshot() != snapshot || len(snapshot.Services) != 1 || snapshot.LoadedAt.IsZero() {
+		t.Fatalf("snapshot after Replace = %+v", store.Snapshot())
+	}
+
+	// ファイルを変えても Replace するまでは以前のスナップショットのまま
+	writeServiceFile(t, dir, "b", "B", false)
+	if _, err := store.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if store.Snapshot() != snapshot {
+		t.Fatal("Load replaced the snapshot")
+	}
+}
+
+func TestServiceStoreImpl_LoadArchived(t *testing.T) {
+	dir := t.TempDir()
+	writeServiceFile(t, dir, "current", "現行", false)
+	writeServiceFile(t, dir, "current", "同じ ID のアーカイブ", true)
+	writeServiceFile(t, dir, "old", "アーカイブ", true)
+
+	tests := []struct {
+		serveArchived bool
+		want          []string
+	}{
+		{serveArchived: true, want: []string{"current:false", "old:true"}},
+		{serveArchived: false, want: []string{"current:false"}},
+	}
+	for _, tt := range tests {
+		services, err := newTestServiceStore(dir, tt.serveArchived).Load(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, service := range services {
+			got = append(got, fmt.Sprintf("%s:%t", service.ID, service.Archived))
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("serveArchived=%t: services = %v, want %v", tt.serveArchived, got, tt.want)
+		}
+	}
+}
+
+func TestServiceStoreImpl_ConcurrentSnapshotAndReplace(t *testing.T) {
+	dir := t.TempDir()
+	store := newTestServiceStore(dir, false)
+
+	const generations = 50
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+
+	// 読み込み直しの途中のデータ（世代の混ざったスナップショット）が見えないことを確かめる
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				snapshot := store.Snapshot()
+				for _, service := range snapshot.Services {
+					if service.Name != snapshot.Services[0].Name {
+						t.Errorf("snapshot mixes generations: %q and %q", snapshot.Services[0].Name, service.Name)
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	for g := 0; g < generations; g++ {
+		name := fmt.Sprintf("gen-%d", g)
+		for _, id := range []string{"a", "b", "c"} {
+			writeServiceFile(t, dir, id, name, false)
+		}
+		services, err := store.Load(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		store.Replace(services)
+	}
+	close(done)
+	wg.Wait()
+
+	if got := store.Snapshot().Services; len(got) != 3 || got[0].Name != fmt.Sprintf("gen-%d", generations-1) {
+		t.Errorf("final snapshot = %+v", got)
+	}
+}
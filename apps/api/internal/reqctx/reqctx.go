@@ -0,0 +1,29 @@
+// Package reqctx はリクエスト ID などリクエスト単位の値を context.Context で受け渡します
+package reqctx
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID はリクエスト ID を持つ context を返します
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID は ctx のリクエスト ID を返します。リクエスト外の処理では空です
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Logger は ctx のリクエスト ID をフィールドに付けたロガーを返します
+func Logger(ctx context.Context, log *zap.Logger) *zap.Logger {
+	if id := RequestID(ctx); id != "" {
+		return log.With(zap.String("request_id", id))
+	}
+	return log
+}
@@ -3,10 +3,15 @@ package usecase
 import (
 	"api/internal/domain"
 	"api/internal/domain/repository"
+	"api/internal/reqctx"
+	"api/pkg/board"
 	"api/pkg/oapi"
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap"
@@ -25,239 +30,347 @@ func normalizeTimeStr(t string) (string, error) {
 }
 
 type BusStopUseCase interface {
-	GetBusStops(groupID *int32) ([]domain.BusStop, error)
-	GetBusStopGroups() ([]domain.BusStopGroup, error)
-	GetBusStopByID(id int32) (*domain.BusStop, error)
-	GetBusStopGroupByID(id int32) (*domain.BusStopGroup, error)
-	GetBusStopTimetable(busStopID int32, date *oapi.ScalarsDateISO) (*oapi.ModelsBusStopTimetable, error)
-	GetBusStopGroupTimetable(groupID int32, date *oapi.ScalarsDateISO) (*oapi.ModelsBusStopGroupTimetable, error)
+	GetBusStops(ctx context.Context, groupID *int32) ([]domain.BusStop, error)
+	GetBusStopGroups(ctx context.Context) ([]domain.BusStopGroup, error)
+	GetBusStopByID(ctx context.Context, id int32) (*domain.BusStop, error)
+	GetBusStopGroupByID(ctx context.Context, id int32) (*domain.BusStopGroup, error)
+	// GetBusStopTimetable / GetBusStopGroupTimetable の結果はキャッシュされ、呼び出し元で共有されます。
+	// 返された時刻表は変更しないでください。version は時刻表を作ったデータのバージョンで、
+	// 読み込み直しと重なっても時刻表と同じデータのものです
+	GetBusStopTimetable(ctx context.Context, busStopID int32, date *oapi.ScalarsDateISO, includeHistorical *bool) (timetable *oapi.ModelsBusStopTimetable, version string, err error)
+	GetBusStopGroupTimetable(ctx context.Context, groupID int32, date *oapi.ScalarsDateISO, includeHistorical *bool) (timetable *oapi.ModelsBusStopGroupTimetable, version string, err error)
+	GetBusStopBoard(ctx context.Context, busStopID int32, from, to time.Time, includeHistorical *bool) (*board.Board, error)
+	GetBusStopGroupBoard(ctx context.Context, groupID int32, from, to time.Time, includeHistorical *bool) (*board.Board, error)
+	// Reload はサービスデータとバス停データをまとめて読み込み直します。失敗した場合は以前のデータを使い続けます
+	Reload(ctx context.Context) error
+	// DatasetVersion は読み込み済みのサービスデータとバス停データのバージョンを返します
+	DatasetVersion(ctx context.Context) string
+	// DatasetStatus は読み込み済みのサービスデータの状態を返します
+	DatasetStatus(ctx context.Context) DatasetStatus
+	// CountActiveServices は date に運行する（いずれかのセグメントが当てはまる）サービスの数を返します
+	CountActiveServices(ctx context.Context, date time.Time) int
+}
+
+// DatasetStatus は読み込み済みのサービスデータの状態です
+type DatasetStatus struct {
+	Version  string
+	LoadedAt time.Time
+	// Services は現行（アーカイブ以外）のサービス数
+	Services int
+	// ValidUntil は現行のサービスの有効期間の最終日（YYYY-MM-DD）。
+	// 終了日のない期間を含む場合やサービスがない場合は空です
+	ValidUntil string
+}
+
+// Expired は現行のサービスがすべて date より前に有効期間を終えているかを返します
+func (s DatasetStatus) Expired(date time.Time) bool {
+	return s.ValidUntil != "" && s.ValidUntil < date.Format("2006-01-02")
 }
 
 type busStopUseCase struct {
 	busStopRepo repository.BusStopRepository
-	serviceRepo repository.ServiceRepository
+	services    repository.ServiceStore
 	log         *zap.Logger
-	services    []domain.ServiceData
+	// index は読み込み済みのデータとその索引。Reload でまとめて差し替えます
+	index    atomic.Pointer[timetableIndex]
+	reloadMu sync.Mutex
+	// 計算済みの時刻表。キーにデータのバージョンを含み、データを読み込み直すと破棄します
+	stopTimetables  *lruCache[*oapi.ModelsBusStopTimetable]
+	groupTimetables *lruCache[*oapi.ModelsBusStopGroupTimetable]
+	cache           CacheOptions
+	// location は「今日」の判定に使うタイムゾーン
+	location *time.Location
 }
 
-func NewBusStopUseCase(busStopRepo repository.BusStopRepository, serviceRepo repository.ServiceRepository, l *zap.Logger) BusStopUseCase {
+// CacheOptions はメモリに保持するキャッシュの大きさです
+type CacheOptions struct {
+	// TimetableCacheSize は時刻表のキャッシュの件数上限（バス停・グループそれぞれ）で、0 以下ならキャッシュしません
+	TimetableCacheSize int
+	// DayIndexCacheSize は日ごとの運行するセグメントの索引を保持する日数の上限です
+	DayIndexCacheSize int
+}
+
+// NewBusStopUseCase はサービスデータとバス停データを読み込んだ usecase を返します。
+// loc は「今日」の判定に使うタイムゾーンです
+func NewBusStopUseCase(busStopRepo repository.BusStopRepository, services repository.ServiceStore, cache CacheOptions, loc *time.Location, l *zap.Logger) BusStopUseCase {
 	u := &busStopUseCase{
-		busStopRepo: busStopRepo,
-		serviceRepo: serviceRepo,
-		log:         l,
+		busStopRepo:     busStopRepo,
+		services:        services,
+		log:             l,
+		stopTimetables:  newLRUCache[*oapi.ModelsBusStopTimetable](cache.TimetableCacheSize),
+		groupTimetables: newLRUCache[*oapi.ModelsBusStopGroupTimetable](cache.TimetableCacheSize),
+		cache:           cache,
+		location:        loc,
 	}
+	u.index.Store(newTimetableIndex(services.Snapshot(), &domain.BusStopData{}, cache.DayIndexCacheSize))
 
-	services, err := u.serviceRepo.LoadAllServices()
-	if err != nil {
-		l.Error("failed to load service data at startup", zap.Error(err))
-	} else {
-		u.services = services
-		l.Info("service data loaded successfully via repository", zap.Int("count", len(services)))
+	if err := u.Reload(context.Background()); err != nil {
+		l.Error("failed to load data at startup", zap.Error(err))
 	}
 
 	return u
 }
 
-func (u *busStopUseCase) GetBusStops(groupID *int32) ([]domain.BusStop, error) {
-	if groupID != nil {
-		busStopGroup, err := u.busStopRepo.GetBusStopGroupByID(*groupID)
-		if err != nil {
-			u.log.Error("failed to get bus stop group by ID", zap.Error(err), zap.Int32("groupID", *groupID))
-			return nil, err
+// Reload はサービスデータとバス停データをまとめて読み込み直し、検証してから同時に差し替えます。
+// 失敗した場合は何も差し替えず、以前のデータを使い続けます
+func (u *busStopUseCase) Reload(ctx context.Context) error {
+	u.reloadMu.Lock()
+	defer u.reloadMu.Unlock()
+
+	services, err := u.services.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load service data: %w", err)
+	}
+	busStops, err := u.busStopRepo.LoadBusStopData(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load bus stop data: %w", err)
+	}
+	services, skipped, err := domain.ValidateStopReferences(services, busStops)
+	if err != nil {
+		return fmt.Errorf("service data does not match bus stop data: %w", err)
+	}
+	for _, err := range skipped {
+		u.logger(ctx).Warn("skipped archived service that refers to a missing bus stop", zap.Error(err))
+	}
+
+	index := newTimetableIndex(u.services.Replace(services), busStops, u.cache.DayIndexCacheSize)
+	u.index.Store(index)
+	u.stopTimetables.purge()
+	u.groupTimetables.purge()
+
+	u.logger(ctx).Info("data loaded",
+		zap.Int("services", len(services)),
+		zap.Int("bus_stops", len(busStops.BusStops)),
+		zap.Int("bus_stop_groups", len(busStops.Groups)),
+		zap.String("dataset_version", index.version))
+	return nil
+}
+
+// timetableCacheKey は時刻表のキャッシュのキーを返します
+func timetableCacheKey(index *timetableIndex, id int32, date time.Time, archived bool) string {
+	return fmt.Sprintf("%s/%d/%s/%t", index.version, id, date.Format("2006-01-02"), archived)
+}
+
+// logger はリクエスト ID を付けたロガーを返します
+func (u *busStopUseCase) logger(ctx context.Context) *zap.Logger {
+	return reqctx.Logger(ctx, u.log)
+}
+
+func (u *busStopUseCase) DatasetVersion(ctx context.Context) string {
+	return u.index.Load().version
+}
+
+func (u *busStopUseCase) DatasetStatus(ctx context.Context) DatasetStatus {
+	index := u.index.Load()
+	status := DatasetStatus{Version: index.version, LoadedAt: index.loadedAt}
+	openEnded := false
+	for _, service := range index.services {
+		if service.Archived {
+			continue
+		}
+		status.Services++
+		if len(service.ValidityPeriods) == 0 {
+			openEnded = true
+		}
+		for _, period := range service.ValidityPeriods {
+			if period.To == "" {
+				openEnded = true
+			} else if period.To > status.ValidUntil {
+				status.ValidUntil = period.To
+			}
 		}
+	}
+	if openEnded {
+		status.ValidUntil = ""
+	}
+	return status
+}
 
-		if len(busStopGroup.BusStops) == 0 {
-			u.log.Warn("no bus stops found in group", zap.Int32("groupID", *groupID))
+func (u *busStopUseCase) CountActiveServices(ctx context.Context, date time.Time) int {
+	archived := UseArchived(date, nil, u.location)
+	count := 0
+	for _, service := range u.index.Load().day(date).services {
+		if service.Archived && !archived {
+			continue
 		}
+		count++
+	}
+	return count
+}
 
-		return busStopGroup.BusStops, nil
-	} else {
-		busStops, err := u.busStopRepo.GetAllBusStops()
+func (u *busStopUseCase) GetBusStops(ctx context.Context, groupID *int32) ([]domain.BusStop, error) {
+	index := u.index.Load()
+	if groupID != nil {
+		busStopGroup, err := index.group(*groupID)
 		if err != nil {
-			u.log.Error("failed to get all bus stops", zap.Error(err))
+			u.logger(ctx).Warn("bus stop group not found", zap.Int32("groupID", *groupID))
 			return nil, err
 		}
 
-		if len(busStops) == 0 {
-			u.log.Warn("no bus stops found")
+		if len(busStopGroup.BusStops) == 0 {
+			u.logger(ctx).Warn("no bus stops found in group", zap.Int32("groupID", *groupID))
 		}
 
-		return busStops, nil
+		return busStopGroup.BusStops, nil
 	}
-}
 
-func (u *busStopUseCase) GetBusStopGroups() ([]domain.BusStopGroup, error) {
-	busStopGroups, err := u.busStopRepo.GetAllBusStopGroups()
-	if err != nil {
-		u.log.Error("failed to get bus stop groups", zap.Error(err))
-		return nil, err
+	if len(index.busStops.BusStops) == 0 {
+		u.logger(ctx).Warn("no bus stops found")
 	}
+	return index.busStops.BusStops, nil
+}
 
-	return busStopGroups, nil
+func (u *busStopUseCase) GetBusStopGroups(ctx context.Context) ([]domain.BusStopGroup, error) {
+	return u.index.Load().busStops.Groups, nil
 }
 
-func (u *busStopUseCase) GetBusStopByID(id int32) (*domain.BusStop, error) {
-	busStop, err := u.busStopRepo.GetBusStopByID(id)
+func (u *busStopUseCase) GetBusStopByID(ctx context.Context, id int32) (*domain.BusStop, error) {
+	busStop, err := u.index.Load().busStop(id)
 	if err != nil {
-		u.log.Error("failed to get bus stop by ID", zap.Error(err), zap.Int32("id", id))
+		u.logger(ctx).Warn("bus stop not found", zap.Int32("id", id))
 		return nil, err
 	}
 
 	return busStop, nil
 }
 
-func (u *busStopUseCase) GetBusStopGroupByID(id int32) (*domain.BusStopGroup, error) {
-	busStopGroup, err := u.busStopRepo.GetBusStopGroupByID(id)
+func (u *busStopUseCase) GetBusStopGroupByID(ctx context.Context, id int32) (*domain.BusStopGroup, error) {
+	busStopGroup, err := u.index.Load().group(id)
 	if err != nil {
-		u.log.Error("failed to get bus stop group by ID", zap.Error(err), zap.Int32("id", id))
+		u.logger(ctx).Warn("bus stop group not found", zap.Int32("id", id))
 		return nil, err
 	}
 
 	return busStopGroup, nil
 }
 
-func (u *busStopUseCase) loadServicesForBusStop(busStopID int32, date time.Time) ([]domain.ServiceData, error) {
-	var relevantServices []domain.ServiceData
-	for _, service := range u.services {
-		if (service.From.StopID == busStopID || service.To.StopID == busStopID) && service.IsValidForDate(date) {
-			relevantServices = append(relevantServices, service)
-		}
-	}
-
-	return relevantServices, nil
+// Today は loc での今日の 0 時を返します
+func Today(loc *time.Location) time.Time {
+	now := time.Now().In(loc)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
 }
 
-func (u *busStopUseCase) loadServicesForBusStopGroup(groupID int32, date time.Time) ([]domain.ServiceData, error) {
-	group, err := u.GetBusStopGroupByID(groupID)
-	if err != nil {
-		return nil, err
-	}
-
-	busStopIDs := make(map[int32]bool)
-	for _, stop := range group.BusStops {
-		busStopIDs[stop.ID] = true
+// UseArchived は date の時刻表にアーカイブ済みサービスを使うかどうかを返します。
+// includeHistorical が指定されていればそれに従い、省略時は loc での今日より前の日付のときだけ使います。
+func UseArchived(date time.Time, includeHistorical *bool, loc *time.Location) bool {
+	if includeHistorical != nil {
+		return *includeHistorical
 	}
-
-	var relevantServices []domain.ServiceData
-	for _, service := range u.services {
-		if (busStopIDs[service.From.StopID] || busStopIDs[service.To.StopID]) && service.IsValidForDate(date) {
-			relevantServices = append(relevantServices, service)
-		}
-	}
-
-	return relevantServices, nil
+	return date.Format("2006-01-02") < Today(loc).Format("2006-01-02")
 }
 
-func (u *busStopUseCase) createBusStopSegments(services []domain.ServiceData, busStopID int32, date time.Time) []oapi.ModelsBusStopSegment {
-	segments := make([]oapi.ModelsBusStopSegment, 0)
+// createBusStopSegments は index の day のうち busStopID を出発するセグメントを返します。
+// useArchived が false ならアーカイブ済みのサービスは除きます。
+// includesArchived はアーカイブ済みのサービスから作ったセグメントが含まれるかどうかです。
+func (u *busStopUseCase) createBusStopSegments(ctx context.Context, index *timetableIndex, day *dayIndex, busStopID int32, useArchived bool) (segments []oapi.ModelsBusStopSegment, includesArchived bool) {
+	segments = make([]oapi.ModelsBusStopSegment, 0)
 
-	for _, service := range services {
-		if service.From.StopID != busStopID {
+	for _, active := range day.byOrigin[busStopID] {
+		service := active.service
+		if service.Archived && !useArchived {
 			continue
 		}
 
-		destination, err := u.GetBusStopByID(service.To.StopID)
+		var archived *bool
+		if service.Archived {
+			archived = &service.Archived
+		}
+
+		destination, err := index.busStop(service.To.StopID)
 		if err != nil {
-			u.log.Error("failed to get destination bus stop",
+			u.logger(ctx).Error("failed to get destination bus stop",
 				zap.Error(err),
 				zap.Int32("destinationID", service.To.StopID))
 			continue
 		}
 
-		for _, segmentRaw := range service.ParsedSegments {
-			switch s := segmentRaw.(type) {
-			case *domain.ShuttleSegment:
-				if !domain.IsSegmentValidForDate(s.Condition, date) {
-					continue
-				}
-
-				fmtStart, err := normalizeTimeStr(s.StartTime)
-				if err != nil {
-					u.log.Error("failed to normalize start time",
-						zap.Error(err),
-						zap.String("raw", s.StartTime))
-					continue
-				}
-				fmtEnd, err := normalizeTimeStr(s.EndTime)
-				if err != nil {
-					u.log.Error("failed to normalize end time",
-						zap.Error(err),
-						zap.String("raw", s.EndTime))
-					continue
-				}
-
-				shuttleSegment := oapi.ModelsShuttleSegment{
-					SegmentType: oapi.Shuttle,
-					Destination: oapi.ModelsStopRef{
-						StopId:   destination.ID,
-						StopName: destination.Name,
-						Lat:      destination.Lat,
-						Lng:      destination.Lng,
-					},
-					StartTime: fmtStart,
-					EndTime:   fmtEnd,
-					IntervalRange: struct {
-						Max int32 `json:"max"`
-						Min int32 `json:"min"`
-					}{
-						Min: int32(s.IntervalRange.Min),
-						Max: int32(s.IntervalRange.Max),
-					},
-				}
-
-				var segment oapi.ModelsBusStopSegment
-				if err := segment.FromModelsShuttleSegment(shuttleSegment); err != nil {
-					u.log.Error("failed to create shuttle segment",
-						zap.Error(err),
-						zap.Int32("busStopID", busStopID),
-						zap.Int32("destinationID", service.To.StopID))
-					continue
-				}
-				segments = append(segments, segment)
+		switch s := active.segment.(type) {
+		case *domain.ShuttleSegment:
+			fmtStart, err := normalizeTimeStr(s.StartTime)
+			if err != nil {
+				u.logger(ctx).Error("failed to normalize start time",
+					zap.Error(err),
+					zap.String("raw", s.StartTime))
+				continue
+			}
+			fmtEnd, err := normalizeTimeStr(s.EndTime)
+			if err != nil {
+				u.logger(ctx).Error("failed to normalize end time",
+					zap.Error(err),
+					zap.String("raw", s.EndTime))
+				continue
+			}
 
-			case *domain.FixedSegment:
-				if !domain.IsSegmentValidForDate(s.Condition, date) {
-					continue
-				}
+			shuttleSegment := oapi.ModelsShuttleSegment{
+				SegmentType: oapi.Shuttle,
+				Destination: oapi.ModelsStopRef{
+					StopId:   destination.ID,
+					StopName: destination.Name,
+					Lat:      destination.Lat,
+					Lng:      destination.Lng,
+				},
+				StartTime: fmtStart,
+				EndTime:   fmtEnd,
+				Archived:  archived,
+				IntervalRange: struct {
+					Max int32 `json:"max"`
+					Min int32 `json:"min"`
+				}{
+					Min: int32(s.IntervalRange.Min),
+					Max: int32(s.IntervalRange.Max),
+				},
+			}
 
-				fixedSegment := oapi.ModelsFixedSegment{
-					SegmentType: oapi.Fixed,
-					Destination: oapi.ModelsStopRef{
-						StopId:   destination.ID,
-						StopName: destination.Name,
-						Lat:      destination.Lat,
-						Lng:      destination.Lng,
-					},
-					Times: make([]oapi.ModelsTimePair, len(s.Times)),
-				}
+			var segment oapi.ModelsBusStopSegment
+			if err := segment.FromModelsShuttleSegment(shuttleSegment); err != nil {
+				u.logger(ctx).Error("failed to create shuttle segment",
+					zap.Error(err),
+					zap.Int32("busStopID", busStopID),
+					zap.Int32("destinationID", service.To.StopID))
+				continue
+			}
+			segments = append(segments, segment)
+			includesArchived = includesArchived || service.Archived
+
+		case *domain.FixedSegment:
+			fixedSegment := oapi.ModelsFixedSegment{
+				SegmentType: oapi.Fixed,
+				Destination: oapi.ModelsStopRef{
+					StopId:   destination.ID,
+					StopName: destination.Name,
+					Lat:      destination.Lat,
+					Lng:      destination.Lng,
+				},
+				Times:    make([]oapi.ModelsTimePair, len(s.Times)),
+				Archived: archived,
+			}
 
-				for i, t := range s.Times {
-					fixedSegment.Times[i] = oapi.ModelsTimePair{
-						Departure: t.Departure,
-						Arrival:   t.Arrival,
-					}
+			for i, t := range s.Times {
+				fixedSegment.Times[i] = oapi.ModelsTimePair{
+					Departure: t.Departure,
+					Arrival:   t.Arrival,
 				}
+			}
 
-				var segment oapi.ModelsBusStopSegment
-				if err := segment.FromModelsFixedSegment(fixedSegment); err != nil {
-					u.log.Error("failed to create fixed segment",
-						zap.Error(err),
-						zap.Int32("busStopID", busStopID),
-						zap.Int32("destinationID", service.To.StopID))
-					continue
-				}
-				segments = append(segments, segment)
+			var segment oapi.ModelsBusStopSegment
+			if err := segment.FromModelsFixedSegment(fixedSegment); err != nil {
+				u.logger(ctx).Error("failed to create fixed segment",
+					zap.Error(err),
+					zap.Int32("busStopID", busStopID),
+					zap.Int32("destinationID", service.To.StopID))
+				continue
 			}
+			segments = append(segments, segment)
+			includesArchived = includesArchived || service.Archived
 		}
 	}
 
-	return segments
+	return segments, includesArchived
 }
 
-func convertToDateTime(date *oapi.ScalarsDateISO) (time.Time, error) {
+func convertToDateTime(date *oapi.ScalarsDateISO, loc *time.Location) (time.Time, error) {
 	if date == nil || date.IsZero() {
-		now := time.Now()
-		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()), nil
+		return Today(loc), nil
 	}
 
 	return time.Date(
@@ -269,24 +382,27 @@ func convertToDateTime(date *oapi.ScalarsDateISO) (time.Time, error) {
 	), nil
 }
 
-func (u *busStopUseCase) GetBusStopTimetable(busStopID int32, date *oapi.ScalarsDateISO) (*oapi.ModelsBusStopTimetable, error) {
-	dateTime, err := convertToDateTime(date)
+func (u *busStopUseCase) GetBusStopTimetable(ctx context.Context, busStopID int32, date *oapi.ScalarsDateISO, includeHistorical *bool) (*oapi.ModelsBusStopTimetable, string, error) {
+	dateTime, err := convertToDateTime(date, u.location)
 	if err != nil {
-		u.log.Error("failed to parse date", zap.Error(err))
-		return nil, err
+		u.logger(ctx).Error("failed to parse date", zap.Error(err))
+		return nil, "", err
 	}
 
-	busStop, err := u.GetBusStopByID(busStopID)
-	if err != nil {
-		return nil, err
+	// 読み込み直しと重なっても 1 つのリクエストでは同じデータを使う
+	index := u.index.Load()
+	useArchived := UseArchived(dateTime, includeHistorical, u.location)
+	key := timetableCacheKey(index, busStopID, dateTime, useArchived)
+	if timetable, ok := u.stopTimetables.get(key); ok {
+		return timetable, index.version, nil
 	}
 
-	services, err := u.loadServicesForBusStop(busStopID, dateTime)
+	busStop, err := index.busStop(busStopID)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	segments := u.createBusStopSegments(services, busStopID, dateTime)
+	segments, archived := u.createBusStopSegments(ctx, index, index.day(dateTime), busStopID, useArchived)
 
 	// データがないときは null ではなく空の配列を返す
 	if segments == nil {
@@ -302,38 +418,47 @@ func (u *busStopUseCase) GetBusStopTimetable(busStopID int32, date *oapi.Scalars
 		lon = oapi.ScalarsLongitude(*busStop.Lng)
 	}
 
-	return &oapi.ModelsBusStopTimetable{
-		Id:       busStopID,
-		Name:     busStop.Name,
-		Lat:      lat,
-		Lon:      lon,
-		Date:     *date,
-		Segments: segments,
-	}, nil
+	timetable := &oapi.ModelsBusStopTimetable{
+		Id:               busStopID,
+		Name:             busStop.Name,
+		Lat:              lat,
+		Lon:              lon,
+		Date:             *date,
+		Segments:         segments,
+		IncludesArchived: archived,
+	}
+	u.stopTimetables.add(key, timetable)
+	return timetable, index.version, nil
 }
 
-func (u *busStopUseCase) GetBusStopGroupTimetable(groupID int32, date *oapi.ScalarsDateISO) (*oapi.ModelsBusStopGroupTimetable, error) {
-	dateTime, err := convertToDateTime(date)
+func (u *busStopUseCase) GetBusStopGroupTimetable(ctx context.Context, groupID int32, date *oapi.ScalarsDateISO, includeHistorical *bool) (*oapi.ModelsBusStopGroupTimetable, string, error) {
+	dateTime, err := convertToDateTime(date, u.location)
 	if err != nil {
-		u.log.Error("failed to parse date", zap.Error(err))
-		return nil, err
+		u.logger(ctx).Error("failed to parse date", zap.Error(err))
+		return nil, "", err
 	}
 
-	group, err := u.GetBusStopGroupByID(groupID)
-	if err != nil {
-		return nil, err
+	index := u.index.Load()
+	useArchived := UseArchived(dateTime, includeHistorical, u.location)
+	key := timetableCacheKey(index, groupID, dateTime, useArchived)
+	if timetable, ok := u.groupTimetables.get(key); ok {
+		return timetable, index.version, nil
 	}
 
-	services, err := u.loadServicesForBusStopGroup(groupID, dateTime)
+	group, err := index.group(groupID)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
+	day := index.day(dateTime)
+
 	// 空の配列で初期化
 	segments := make([]oapi.ModelsBusStopSegment, 0)
+	archived := false
 	for _, busStop := range group.BusStops {
-		busStopSegments := u.createBusStopSegments(services, busStop.ID, dateTime)
+		busStopSegments, busStopArchived := u.createBusStopSegments(ctx, index, day, busStop.ID, useArchived)
 		segments = append(segments, busStopSegments...)
+		archived = archived || busStopArchived
 	}
 
 	// データがないときでも空の配列を確実に返す
@@ -341,10 +466,111 @@ func (u *busStopUseCase) GetBusStopGroupTimetable(groupID int32, date *oapi.Scal
 		segments = []oapi.ModelsBusStopSegment{}
 	}
 
-	return &oapi.ModelsBusStopGroupTimetable{
-		Id:       groupID,
-		Name:     group.Name,
-		Date:     *date,
-		Segments: segments,
-	}, nil
+	timetable := &oapi.ModelsBusStopGroupTimetable{
+		Id:               groupID,
+		Name:             group.Name,
+		Date:             *date,
+		Segments:         segments,
+		IncludesArchived: archived,
+	}
+	u.groupTimetables.add(key, timetable)
+	return timetable, index.version, nil
+}
+
+// stopName はバス停名を返します。バス停が見つからない場合はサービスデータの表示名を使います
+func (u *busStopUseCase) stopName(ctx context.Context, index *timetableIndex, ref domain.ServiceStopRef) string {
+	busStop, err := index.busStop(ref.StopID)
+	if err != nil {
+		u.logger(ctx).Warn("failed to get bus stop for board", zap.Error(err), zap.Int32("busStopID", ref.StopID))
+		return ref.DisplayName
+	}
+	return busStop.Name
+}
+
+// boardSegments は busStopIDs を出発するサービスのセグメントを掲示用時刻表の入力に変換します。
+// 日付・有効期間による絞り込みは board が行います。
+func (u *busStopUseCase) boardSegments(ctx context.Context, index *timetableIndex, busStopIDs map[int32]bool, archived bool) []board.Segment {
+	names := make(map[int32]string)
+	name := func(ref domain.ServiceStopRef) string {
+		if n, ok := names[ref.StopID]; ok {
+			return n
+		}
+		names[ref.StopID] = u.stopName(ctx, index, ref)
+		return names[ref.StopID]
+	}
+
+	var segments []board.Segment
+	for _, service := range index.services {
+		if service.Archived && !archived {
+			continue
+		}
+		if !busStopIDs[service.From.StopID] {
+			continue
+		}
+
+		for _, segmentRaw := range service.ParsedSegments {
+			segment := board.Segment{
+				Origin:      name(service.From),
+				Destination: name(service.To),
+				Validity:    service.ValidityPeriods,
+			}
+			switch s := segmentRaw.(type) {
+			case *domain.FixedSegment:
+				segment.Condition = s.Condition
+				for _, t := range s.Times {
+					segment.Departures = append(segment.Departures, t.Departure)
+				}
+			case *domain.ShuttleSegment:
+				segment.Condition = s.Condition
+				segment.Shuttle = &board.Shuttle{
+					Start:       s.StartTime,
+					End:         s.EndTime,
+					MinInterval: s.IntervalRange.Min,
+					MaxInterval: s.IntervalRange.Max,
+				}
+			default:
+				continue
+			}
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+// buildBoard は from と to が同じ日なら 1 日分、異なれば期間の掲示用時刻表を作ります
+func buildBoard(name string, from, to time.Time, segments []board.Segment) *board.Board {
+	var b board.Board
+	if from.Equal(to) {
+		b = board.ForDate(name, from, segments)
+	} else {
+		b = board.ForPeriod(name, from, to, segments)
+	}
+	return &b
+}
+
+func (u *busStopUseCase) GetBusStopBoard(ctx context.Context, busStopID int32, from, to time.Time, includeHistorical *bool) (*board.Board, error) {
+	index := u.index.Load()
+	busStop, err := index.busStop(busStopID)
+	if err != nil {
+		return nil, err
+	}
+
+	segments := u.boardSegments(ctx, index, map[int32]bool{busStopID: true}, UseArchived(from, includeHistorical, u.location))
+	return buildBoard(busStop.Name, from, to, segments), nil
+}
+
+func (u *busStopUseCase) GetBusStopGroupBoard(ctx context.Context, groupID int32, from, to time.Time, includeHistorical *bool) (*board.Board, error) {
+	index := u.index.Load()
+	group, err := index.group(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	busStopIDs := make(map[int32]bool)
+	for _, stop := range group.BusStops {
+		busStopIDs[stop.ID] = true
+	}
+
+	segments := u.boardSegments(ctx, index, busStopIDs, UseArchived(from, includeHistorical, u.location))
+	return buildBoard(group.Name, from, to, segments), nil
 }
@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"api/internal/config"
+	"api/internal/domain"
+	"api/internal/domain/repository"
+	repo "api/internal/repository"
+	"api/pkg/oapi"
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// countingBusStopRepository は読み込みの回数を数えるリポジトリです。
+// リクエストの処理がファイルを読み込まないことを確かめます
+type countingBusStopRepository struct {
+	repository.BusStopRepository
+	calls atomic.Int64
+}
+
+func (r *countingBusStopRepository) LoadBusStopData(ctx context.Context) (*domain.BusStopData, error) {
+	r.calls.Add(1)
+	return r.BusStopRepository.LoadBusStopData(ctx)
+}
+
+// newBenchmarkUseCase は data/ を読み込んだ usecase を返します。
+// 時刻表のキャッシュは無効にし、毎回時刻表を組み立てる処理を測ります
+func newBenchmarkUseCase(b *testing.B) (BusStopUseCase, *countingBusStopRepository) {
+	b.Helper()
+	cfg := &config.Config{
+		DataPath:          "../../data",
+		BusStopsFile:      "bus_stops.json",
+		BusStopGroupsFile: "bus_stop_groups.json",
+		ServeArchived:     true,
+	}
+	busStopRepo := &countingBusStopRepository{BusStopRepository: repo.NewBusStopRepositoryImpl(cfg)}
+	u := NewBusStopUseCase(busStopRepo, repo.NewServiceStoreImpl(cfg, zap.NewNop()),
+		CacheOptions{TimetableCacheSize: 0, DayIndexCacheSize: 64}, time.Local, zap.NewNop())
+	if u.DatasetStatus(context.Background()).Services == 0 {
+		b.Fatal("no services loaded from ../../data")
+	}
+	busStopRepo.calls.Store(0)
+	return u, busStopRepo
+}
+
+// benchmarkDates は時刻表を引く日付（平日・土曜・日曜を含む 1 週間）
+func benchmarkDates() []*oapi.ScalarsDateISO {
+	dates := make([]*oapi.ScalarsDateISO, 7)
+	start := time.Date(2026, 10, 19, 0, 0, 0, 0, time.Local)
+	for i := range dates {
+		dates[i] = &oapi.ScalarsDateISO{Time: start.AddDate(0, 0, i)}
+	}
+	return dates
+}
+
+// reportRepositoryCalls はリクエスト中のリポジトリ呼び出し（ファイルの読み込み）がないことを確かめます
+func reportRepositoryCalls(b *testing.B, r *countingBusStopRepository) {
+	b.Helper()
+	calls := r.calls.Load()
+	b.ReportMetric(float64(calls)/float64(b.N), "file-reads/op")
+	if calls != 0 {
+		b.Fatalf("requests read files %d times, want 0", calls)
+	}
+}
+
+func BenchmarkGetBusStopTimetable(b *testing.B) {
+	u, r := newBenchmarkUseCase(b)
+	ctx := context.Background()
+	dates := benchmarkDates()
+	stops, err := u.GetBusStops(ctx, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	r.calls.Store(0)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		stop := stops[i%len(stops)]
+		if _, _, err := u.GetBusStopTimetable(ctx, stop.ID, dates[i%len(dates)], nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+	reportRepositoryCalls(b, r)
+}
+
+func BenchmarkGetBusStopGroupTimetable(b *testing.B) {
+	u, r := newBenchmarkUseCase(b)
+	ctx := context.Background()
+	dates := benchmarkDates()
+	groups, err := u.GetBusStopGroups(ctx)
+	if err != nil {
+		b.Fatal(err)
+	}
+	r.calls.Store(0)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		group := groups[i%len(groups)]
+		if _, _, err := u.GetBusStopGroupTimetable(ctx, group.ID, dates[i%len(dates)], nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+	reportRepositoryCalls(b, r)
+}
+
+func BenchmarkCountActiveServices(b *testing.B) {
+	u, r := newBenchmarkUseCase(b)
+	ctx := context.Background()
+	dates := benchmarkDates()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		u.CountActiveServices(ctx, dates[i%len(dates)].Time)
+	}
+	b.StopTimer()
+	reportRepositoryCalls(b, r)
+}
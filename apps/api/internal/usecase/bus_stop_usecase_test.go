@@ -0,0 +1,245 @@
+package usecase
+
+import (
+	"api/internal/config"
+	repo "api/internal/repository"
+	"api/pkg/oapi"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// writeGeneration は世代 g のデータを dir に書き込みます。
+// 行き先のバス停名と発車時刻の両方に世代が入るため、時刻表から世代の混ざりを見分けられます
+func writeGeneration(t *testing.T, dir string, g int) {
+	t.Helper()
+	files := map[string]string{
+		"bus_stops.json": fmt.Sprintf(`[
+  {"id": 1, "name": "八王子駅", "lat": 35.65, "lng": 139.33},
+  {"id": 2, "name": "大学-%d", "lat": 35.62, "lng": 139.34}
+]`, g),
+		"bus_stop_groups.json": `[
+  {"id": 1, "name": "八王子駅", "busStops": [{"id": 1, "name": "八王子駅", "lat": 35.65, "lng": 139.33}]}
+]`,
+		filepath.Join("services", "hachioji-to-school.json"): fmt.Sprintf(`{
+  "id": "hachioji-to-school",
+  "name": "八王子駅 → 大学",
+  "from": {"stopId": 1, "displayName": "八王子駅"},
+  "to": {"stopId": 2, "displayName": "大学"},
+  "direction": "inbound",
+  "validityPeriods": [],
+  "segments": [
+    {"segmentType": "fixed", "condition": {"type": "dayType", "value": "weekday"}, "times": [{"departure": "8:%02d", "arrival": "9:00"}]}
+  ]
+}`, g%60),
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		// 書きかけのファイルを読まないよう、一時ファイルから置き換える
+		if err := os.WriteFile(path+".tmp", []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(path+".tmp", path); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestBusStopUseCase_ReloadWhileServing(t *testing.T) {
+	dir := t.TempDir()
+	writeGeneration(t, dir, 0)
+	cfg := &config.Config{
+		DataPath:          dir,
+		BusStopsFile:      "bus_stops.json",
+		BusStopGroupsFile: "bus_stop_groups.json",
+	}
+	u := NewBusStopUseCase(repo.NewBusStopRepositoryImpl(cfg), repo.NewServiceStoreImpl(cfg, zap.NewNop()),
+		CacheOptions{TimetableCacheSize: 8, DayIndexCacheSize: 8}, time.Local, zap.NewNop())
+
+	ctx := context.Background()
+	monday := &oapi.ScalarsDateISO{Time: time.Date(2026, 10, 19, 0, 0, 0, 0, time.Local)}
+	const generations = 30
+
+	// versionOf は世代ごとのデータのバージョン。読み込み直しの間に返された時刻表と
+	// バージョンの組 seen が、同じ世代のものかを最後に確かめる
+	versionOf := map[int]string{0: u.DatasetVersion(ctx)}
+	type versioned struct {
+		generation int
+		version    string
+	}
+	seen := make([][]versioned, 4)
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				timetable, version, err := u.GetBusStopTimetable(ctx, 1, monday, nil)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if len(timetable.Segments) != 1 {
+					t.Errorf("got %d segments, want 1", len(timetable.Segments))
+					return
+				}
+				segment, err := timetable.Segments[0].AsModelsFixedSegment()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				var g int
+				fmt.Sscanf(segment.Destination.StopName, "大学-%d", &g)
+				if want := fmt.Sprintf("8:%02d", g%60); segment.Times[0].Departure != want {
+					t.Errorf("destination %q came with departure %s, want %s: stops and services are out of sync",
+						segment.Destination.StopName, segment.Times[0].Departure, want)
+					return
+				}
+				seen[r] = append(seen[r], versioned{g, version})
+
+				u.CountActiveServices(ctx, monday.Time)
+				u.DatasetStatus(ctx)
+				if _, _, err := u.GetBusStopGroupTimetable(ctx, 1, monday, nil); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+
+	versions := map[string]bool{versionOf[0]: true}
+	for g := 1; g <= generations; g++ {
+		writeGeneration(t, dir, g)
+		if err := u.Reload(ctx); err != nil {
+			t.Fatal(err)
+		}
+		versionOf[g] = u.DatasetVersion(ctx)
+		versions[versionOf[g]] = true
+	}
+	close(done)
+	wg.Wait()
+
+	if len(versions) != generations+1 {
+		t.Errorf("got %d distinct dataset versions, want %d", len(versions), generations+1)
+	}
+	for _, pairs := range seen {
+		for _, p := range pairs {
+			if p.version != versionOf[p.generation] {
+				t.Errorf("timetable of generation %d came with version %s, want %s", p.generation, p.version, versionOf[p.generation])
+				break
+			}
+		}
+	}
+	timetable, _, err := u.GetBusStopTimetable(ctx, 1, monday, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	segment, _ := timetable.Segments[0].AsModelsFixedSegment()
+	if want := fmt.Sprintf("大学-%d", generations); segment.Destination.StopName != want {
+		t.Errorf("after reloads destination = %q, want %q", segment.Destination.StopName, want)
+	}
+}
+
+func TestUseArchived_UsesLocation(t *testing.T) {
+	// 時差が 25 時間あるため、east の今日は west では必ず明日以降になる
+	east := time.FixedZone("UTC+14", 14*60*60)
+	west := time.FixedZone("UTC-11", -11*60*60)
+	historical := true
+
+	tests := []struct {
+		name              string
+		date              time.Time
+		includeHistorical *bool
+		loc               *time.Location
+		want              bool
+	}{
+		{"today", Today(east), nil, east, false},
+		{"yesterday", Today(east).AddDate(0, 0, -1), nil, east, true},
+		{"today in another location", Today(west), nil, east, true},
+		{"tomorrow in another location", Today(east), nil, west, false},
+		{"includeHistorical", Today(east), &historical, east, true},
+	}
+	for _, tt := range tests {
+		if got := UseArchived(tt.date, tt.includeHistorical, tt.loc); got != tt.want {
+			t.Errorf("%s: UseArchived() = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
+
+// writeService は dir/services/<name> にサービスを書き込みます
+func writeService(t *testing.T, dir, name, id string, from, to int32) {
+	t.Helper()
+	data := fmt.Sprintf(`{
+  "id": %q,
+  "name": %q,
+  "from": {"stopId": %d, "displayName": "発"},
+  "to": {"stopId": %d, "displayName": "着"},
+  "direction": "inbound",
+  "validityPeriods": [{"from": "2020-04-01", "to": "2020-07-31"}],
+  "segments": [
+    {"segmentType": "fixed", "condition": {"type": "dayType", "value": "weekday"}, "times": [{"departure": "7:00", "arrival": "7:30"}]}
+  ]
+}`, id, id, from, to)
+	path := filepath.Join(dir, "services", name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBusStopUseCase_ReloadSkipsArchivedServiceWithMissingStop(t *testing.T) {
+	dir := t.TempDir()
+	writeGeneration(t, dir, 0)
+	// 廃止した停留所 99 を参照するアーカイブと、参照が正しいアーカイブ
+	writeService(t, dir, filepath.Join("archived", "retired-stop.json"), "retired-stop", 99, 2)
+	writeService(t, dir, filepath.Join("archived", "old-weekday.json"), "old-weekday", 1, 2)
+	cfg := &config.Config{
+		DataPath:          dir,
+		BusStopsFile:      "bus_stops.json",
+		BusStopGroupsFile: "bus_stop_groups.json",
+		ServeArchived:     true,
+	}
+	u := NewBusStopUseCase(repo.NewBusStopRepositoryImpl(cfg), repo.NewServiceStoreImpl(cfg, zap.NewNop()),
+		CacheOptions{DayIndexCacheSize: 8}, time.Local, zap.NewNop())
+
+	ctx := context.Background()
+	if got := u.DatasetStatus(ctx).Services; got != 1 {
+		t.Errorf("current services = %d, want 1", got)
+	}
+	// 2020-04-06（月）に運行するのは、期間の指定がない現行のサービスと参照の正しいアーカイブ
+	if got := u.CountActiveServices(ctx, time.Date(2020, 4, 6, 0, 0, 0, 0, time.Local)); got != 2 {
+		t.Errorf("services running on 2020-04-06 = %d, want 2", got)
+	}
+	if stops, err := u.GetBusStops(ctx, nil); err != nil || len(stops) != 2 {
+		t.Errorf("GetBusStops() = %d stops, %v, want the bus stops loaded", len(stops), err)
+	}
+
+	// 現行のサービスが無い停留所を参照していれば読み込み直しは失敗し、以前のデータを使い続ける
+	version := u.DatasetVersion(ctx)
+	writeService(t, dir, "current-retired-stop.json", "current-retired-stop", 99, 2)
+	if err := u.Reload(ctx); err == nil {
+		t.Error("Reload() succeeded with a current service that refers to a missing stop")
+	}
+	if got := u.DatasetVersion(ctx); got != version {
+		t.Errorf("dataset version = %s after a failed reload, want %s", got, version)
+	}
+}
@@ -0,0 +1,61 @@
+package usecase
+
+import "testing"
+
+func TestLRUCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := newLRUCache[int](2)
+	c.add("a", 1)
+	c.add("b", 2)
+	// a を使ったので、次に追い出されるのは b
+	if v, ok := c.get("a"); !ok || v != 1 {
+		t.Fatalf("get(a) = %d, %t", v, ok)
+	}
+	c.add("c", 3)
+
+	if _, ok := c.get("b"); ok {
+		t.Error("b should have been evicted")
+	}
+	for key, want := range map[string]int{"a": 1, "c": 3} {
+		if v, ok := c.get(key); !ok || v != want {
+			t.Errorf("get(%s) = %d, %t, want %d", key, v, ok, want)
+		}
+	}
+
+	// 既存のキーへの add は値を更新して最近使われたものにする
+	c.add("a", 10)
+	c.add("d", 4)
+	if _, ok := c.get("c"); ok {
+		t.Error("c should have been evicted")
+	}
+	if v, ok := c.get("a"); !ok || v != 10 {
+		t.Errorf("get(a) = %d, %t, want 10", v, ok)
+	}
+	if n := c.order.Len(); n != 2 || len(c.items) != 2 {
+		t.Errorf("cache holds %d entries (%d keys), want 2", n, len(c.items))
+	}
+}
+
+func TestLRUCache_Purge(t *testing.T) {
+	c := newLRUCache[int](4)
+	c.add("a", 1)
+	c.add("b", 2)
+	c.purge()
+	if _, ok := c.get("a"); ok {
+		t.Error("a remains after purge")
+	}
+	if c.order.Len() != 0 || len(c.items) != 0 {
+		t.Errorf("cache holds %d entries after purge", c.order.Len())
+	}
+	c.add("c", 3)
+	if v, ok := c.get("c"); !ok || v != 3 {
+		t.Errorf("get(c) after purge = %d, %t", v, ok)
+	}
+}
+
+func TestLRUCache_ZeroSizeKeepsNothing(t *testing.T) {
+	c := newLRUCache[int](0)
+	c.add("a", 1)
+	if _, ok := c.get("a"); ok {
+		t.Error("a cache of size 0 should keep nothing")
+	}
+}
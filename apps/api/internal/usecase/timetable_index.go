@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"api/internal/domain"
+	"time"
+)
+
+// timetableIndex はデータのバージョンごとに一度だけ作る、読み込み済みのデータと時刻表の検索用の索引です。
+// 作成後は変更しないため、複数の goroutine から同時に読めます
+type timetableIndex struct {
+	services []domain.ServiceData
+	version  string
+	loadedAt time.Time
+	// busStops は読み込んだ順のバス停とバス停グループ
+	busStops *domain.BusStopData
+	// stops / groups は ID からの引き当て
+	stops  map[int32]domain.BusStop
+	groups map[int32]domain.BusStopGroup
+	// days は日付（YYYY-MM-DD）ごとの運行するセグメント。必要になった日の分だけ作ります
+	days *lruCache[*dayIndex]
+}
+
+// activeSegment はある日に運行するセグメントとそのサービスです
+type activeSegment struct {
+	service *domain.ServiceData
+	segment interface{} // *domain.FixedSegment または *domain.ShuttleSegment
+}
+
+// dayIndex は 1 日分の運行するセグメントです。アーカイブ済みのサービスも含みます
+type dayIndex struct {
+	// byOrigin は出発する停留所 ID → セグメント（サービスとセグメントの定義順）
+	byOrigin map[int32][]activeSegment
+	// services はいずれかのセグメントが運行するサービス
+	services []*domain.ServiceData
+}
+
+// newTimetableIndex は索引を作ります。dayIndexCacheSize は日ごとの索引を保持する日数の上限です
+func newTimetableIndex(snapshot *domain.ServiceSnapshot, busStops *domain.BusStopData, dayIndexCacheSize int) *timetableIndex {
+	x := &timetableIndex{
+		services: snapshot.Services,
+		version:  domain.DatasetVersion(snapshot.Services, busStops),
+		loadedAt: snapshot.LoadedAt,
+		busStops: busStops,
+		stops:    make(map[int32]domain.BusStop, len(busStops.BusStops)),
+		groups:   make(map[int32]domain.BusStopGroup, len(busStops.Groups)),
+		days:     newLRUCache[*dayIndex](dayIndexCacheSize),
+	}
+	for _, stop := range busStops.BusStops {
+		x.stops[stop.ID] = stop
+	}
+	for _, group := range busStops.Groups {
+		x.groups[group.ID] = group
+	}
+	return x
+}
+
+// busStop は id のバス停を返します。ない場合は BusStopNotFound エラーを返します
+func (x *timetableIndex) busStop(id int32) (*domain.BusStop, error) {
+	busStop, ok := x.stops[id]
+	if !ok {
+		return nil, domain.NewNotFoundError(domain.ErrorCodeBusStopNotFound, "")
+	}
+	return &busStop, nil
+}
+
+// group は id のバス停グループを返します。ない場合は BusStopGroupNotFound エラーを返します
+func (x *timetableIndex) group(id int32) (*domain.BusStopGroup, error) {
+	group, ok := x.groups[id]
+	if !ok {
+		return nil, domain.NewNotFoundError(domain.ErrorCodeBusStopGroupNotFound, "")
+	}
+	return &group, nil
+}
+
+// day は date に運行するセグメントの索引を返します
+func (x *timetableIndex) day(date time.Time) *dayIndex {
+	key := date.Format("2006-01-02")
+	if d, ok := x.days.get(key); ok {
+		return d
+	}
+
+	d := &dayIndex{byOrigin: make(map[int32][]activeSegment)}
+	for i := range x.services {
+		service := &x.services[i]
+		if !service.IsValidForDate(date) {
+			continue
+		}
+
+		active := false
+		for _, segmentRaw := range service.ParsedSegments {
+			var condition domain.SegmentCondition
+			switch s := segmentRaw.(type) {
+			case *domain.FixedSegment:
+				condition = s.Condition
+			case *domain.ShuttleSegment:
+				condition = s.Condition
+			default:
+				continue
+			}
+			if !domain.IsSegmentValidForDate(condition, date) {
+				continue
+			}
+			d.byOrigin[service.From.StopID] = append(d.byOrigin[service.From.StopID], activeSegment{service: service, segment: segmentRaw})
+			active = true
+		}
+		if active {
+			d.services = append(d.services, service)
+		}
+	}
+
+	x.days.add(key, d)
+	return d
+}
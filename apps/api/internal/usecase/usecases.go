@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"api/internal/config"
 	"api/internal/domain/repository"
 
 	"go.uber.org/zap"
@@ -10,8 +11,11 @@ type UseCases struct {
 	BusStop BusStopUseCase
 }
 
-func NewUseCases(repos *repository.Repositories, logger *zap.Logger) *UseCases {
+func NewUseCases(repos *repository.Repositories, cfg *config.Config, logger *zap.Logger) *UseCases {
 	return &UseCases{
-		BusStop: NewBusStopUseCase(repos.BusStop, repos.Service, logger),
+		BusStop: NewBusStopUseCase(repos.BusStop, repos.Service, CacheOptions{
+			TimetableCacheSize: cfg.TimetableCacheSize,
+			DayIndexCacheSize:  cfg.DayIndexCacheSize,
+		}, cfg.Location, logger),
 	}
 }
@@ -2,35 +2,46 @@ package main
 
 import (
 	"api/internal/app"
-	"api/pkg/oapi"
+	"api/internal/config"
+	"context"
+	"flag"
 	"log"
-
-	"github.com/labstack/echo/v4"
-	"github.com/labstack/echo/v4/middleware"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	_ "time/tzdata"
 )
 
 func main() {
-	e := echo.New()
-	appCon := app.Initialize()
-
-	addr := appCon.Config.GetAddr()
+	configFile := flag.String("config", "", "設定ファイル（.yaml / .yml / .toml）のパス。環境変数 CONFIG_FILE でも指定できます")
+	printConfig := flag.Bool("print-config", false, "有効な設定を秘密の値を伏せて表示し、終了します")
+	flag.Parse()
 
-	server := app.NewServer(appCon.Handlers)
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *printConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
-	e.Use(middleware.Logger())
-	e.Use(middleware.Recover())
-	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: appCon.Config.AllowedOrigins,
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
-		AllowMethods: []string{echo.GET, echo.HEAD, echo.PUT, echo.PATCH, echo.POST, echo.DELETE},
-	}))
-	// e.Use(appCon.Middleware.OpenAPIMiddleware) // 一時的にコメントアウトして日付パラメータの処理問題を回避
-	e.Use(appCon.Middleware.ErrorHandlingMiddleware)
+	appCon := app.Initialize(cfg.Config)
+	e := appCon.NewEcho()
 
-	oapi.RegisterHandlers(e, server)
+	// SIGTERM / SIGINT でシャットダウンする
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
 
-	log.Println("Server running...")
-	if err := e.Start(addr); err != nil {
-		appCon.Logger.Sugar().Fatalf("failed to start server. %+v", err)
+	// kill -HUP でデータを読み込み直す。開発環境では data/ の変更も自動で読み込み直す
+	appCon.ReloadOnSignal(syscall.SIGHUP)
+	if cfg.IsDev() {
+		appCon.ReloadOnChange(ctx, time.Second)
+	}
+	if err := appCon.Serve(ctx, e); err != nil {
+		log.Fatalf("failed to serve. %+v", err)
 	}
 }
@@ -0,0 +1,443 @@
+// Package board は停留所に掲示する時刻表（時を行・分を列に並べた表）を組み立て、印刷用 HTML に出力します。
+// API の印刷用エンドポイントと timetable-gen の print サブコマンドの両方から使います。
+package board
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"api/pkg/schedule"
+)
+
+// Segment は時刻表の元になる 1 セグメントです。Departures（定時便）か Shuttle（シャトル運行）のどちらかを持ちます
+type Segment struct {
+	Origin      string
+	Destination string
+	Validity    []schedule.Period
+	Condition   schedule.Condition
+	Departures  []string // "H:MM"
+	Shuttle     *Shuttle
+}
+
+// Shuttle はシャトル運行の時間帯と運行間隔（分）です
+type Shuttle struct {
+	Start       string
+	End         string
+	MinInterval int
+	MaxInterval int
+}
+
+// Label は運行間隔の表示です（例: "～ 約3〜5分間隔 ～"）
+func (s Shuttle) Label() string {
+	if s.MinInterval == s.MaxInterval {
+		return fmt.Sprintf("～ 約%d分間隔 ～", s.MinInterval)
+	}
+	return fmt.Sprintf("～ 約%d〜%d分間隔 ～", s.MinInterval, s.MaxInterval)
+}
+
+// Board は 1 停留所（またはグループ）分の掲示用時刻表です
+type Board struct {
+	Stop     string
+	Subtitle string
+	Tables   []Table
+	Legends  []Legend
+}
+
+// Table は行き先・ダイヤごとの時刻表です
+type Table struct {
+	Origin      string // 出発停留所が複数あるときのみ
+	Destination string
+	DayLabel    string
+	Mark        string // 特別ダイヤの表全体に付ける記号
+	Columns     int    // 1 行の分の最大数
+	Rows        []Row
+}
+
+// Row は 1 時間分の発車時刻、またはシャトル運行の行です
+type Row struct {
+	Hour    int
+	Minutes []Minute
+	Shuttle *Shuttle
+}
+
+// Padding は表の列をそろえるための空セルです
+func (r Row) Padding(columns int) []struct{} {
+	if n := columns - len(r.Minutes); n > 0 {
+		return make([]struct{}, n)
+	}
+	return nil
+}
+
+// Minute は発車時刻の分と、特別ダイヤの記号です
+type Minute struct {
+	Minute string
+	Mark   string
+}
+
+// Legend は記号の凡例です
+type Legend struct {
+	Mark string
+	Text string
+}
+
+// 特別ダイヤに順に割り当てる記号
+var marks = []string{"◆", "●", "▲", "■", "★"}
+
+func markFor(i int) string {
+	if i < len(marks) {
+		return marks[i]
+	}
+	return "※" + strconv.Itoa(i+1)
+}
+
+var weekdayLabels = []string{"日", "月", "火", "水", "木", "金", "土"}
+
+// dayTypeLabels は dayType 条件の表示名と、表の並び順です
+var dayTypeLabels = []struct {
+	value schedule.DayType
+	label string
+}{
+	{schedule.DayTypeWeekday, "平日"},
+	{schedule.DayTypeMonday, "月曜"},
+	{schedule.DayTypeTuesday, "火曜"},
+	{schedule.DayTypeWednesday, "水曜"},
+	{schedule.DayTypeThursday, "木曜"},
+	{schedule.DayTypeFriday, "金曜"},
+	{schedule.DayTypeSaturday, "土曜"},
+	{schedule.DayTypeWeekend, "土日"},
+	{schedule.DayTypeSunday, "日曜"},
+	{schedule.DayTypeHoliday, "休日"},
+}
+
+// DayTypeLabel は dayType 条件の値の表示名（「平日」「土曜」など）を返します。未知の値はそのまま返します
+func DayTypeLabel(value string) string {
+	for _, d := range dayTypeLabels {
+		if string(d.value) == value {
+			return d.label
+		}
+	}
+	return value
+}
+
+// ConditionLabel は運行条件の表示名を返します。曜日の表示名は掲示用時刻表の見出しと同じで、
+// 日付・期間指定は年をまたいでも区別できるよう YYYY-MM-DD のまま表します
+func ConditionLabel(c schedule.Condition) string {
+	switch c.Type {
+	case schedule.ConditionTypeDayType:
+		return DayTypeLabel(c.Value)
+	case schedule.ConditionTypeSpecificDate:
+		return c.Value
+	case schedule.ConditionTypeSpecificPeriod:
+		if c.From == c.To {
+			return c.From
+		}
+		return c.From + "〜" + c.To
+	default:
+		return string(c.Type)
+	}
+}
+
+// dayGroup は条件ごとの表の見出しと並び順です。特別ダイヤ（日付・期間指定）は開始日順に並べます
+type dayGroup struct {
+	label   string
+	order   int
+	date    string
+	special bool
+}
+
+func conditionGroup(c schedule.Condition) dayGroup {
+	switch c.Type {
+	case schedule.ConditionTypeSpecificDate:
+		return dayGroup{label: shortDate(c.Value), order: len(dayTypeLabels), date: c.Value, special: true}
+	case schedule.ConditionTypeSpecificPeriod:
+		label := shortDate(c.From) + "〜" + shortDate(c.To)
+		if c.From == c.To {
+			label = shortDate(c.From)
+		}
+		return dayGroup{label: label, order: len(dayTypeLabels), date: c.From, special: true}
+	}
+	for i, d := range dayTypeLabels {
+		if string(d.value) == c.Value {
+			return dayGroup{label: d.label, order: i}
+		}
+	}
+	return dayGroup{label: c.Value, order: len(dayTypeLabels)}
+}
+
+func (g dayGroup) less(o dayGroup) bool {
+	if g.order != o.order {
+		return g.order < o.order
+	}
+	if g.date != o.date {
+		return g.date < o.date
+	}
+	return g.label < o.label
+}
+
+// shortDate は "2026-11-03" を "11/3" にします。解釈できなければそのまま返します
+func shortDate(s string) string {
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return s
+	}
+	return fmt.Sprintf("%d/%d", d.Month(), d.Day())
+}
+
+// DateLabel は日付の見出しです（例: "2026年4月29日（水・昭和の日）"）
+func DateLabel(date time.Time) string {
+	day := weekdayLabels[date.Weekday()]
+	if name := schedule.HolidayName(date); name != "" {
+		day += "・" + name
+	}
+	return fmt.Sprintf("%d年%d月%d日（%s）", date.Year(), date.Month(), date.Day(), day)
+}
+
+// dateDayLabel は date に走るダイヤの種類です（平日・土曜・日曜・休日）
+func dateDayLabel(date time.Time) string {
+	switch schedule.DayTypeOf(date) {
+	case schedule.DayTypeHoliday:
+		return "休日"
+	case schedule.DayTypeSaturday:
+		return "土曜"
+	case schedule.DayTypeSunday:
+		return "日曜"
+	}
+	return "平日"
+}
+
+// ForDate は date に運行する便を行き先ごとに 1 つの表にまとめます。
+// 日付・期間指定の特別ダイヤの便には記号を付け、凡例に載せます。
+func ForDate(stop string, date time.Time, segments []Segment) Board {
+	b := Board{Stop: stop, Subtitle: DateLabel(date) + "の時刻表"}
+	dayLabel := dateDayLabel(date)
+
+	var applicable []Segment
+	for _, seg := range segments {
+		if schedule.PeriodsInclude(seg.Validity, date) && seg.Condition.Applies(date) {
+			applicable = append(applicable, seg)
+		}
+	}
+
+	var specials []dayGroup
+	for _, seg := range applicable {
+		if g := conditionGroup(seg.Condition); g.special && !slices.Contains(specials, g) {
+			specials = append(specials, g)
+		}
+	}
+	sort.Slice(specials, func(i, j int) bool { return specials[i].less(specials[j]) })
+	specialMarks := map[string]string{}
+	for i, g := range specials {
+		specialMarks[g.label] = markFor(i)
+		b.Legends = append(b.Legends, Legend{Mark: markFor(i), Text: g.label + " の特別ダイヤで運行する便"})
+	}
+
+	multiOrigin := hasMultipleOrigins(applicable)
+	groups := map[string]*tableBuilder{}
+	var keys []string
+	for _, seg := range applicable {
+		key := seg.Destination
+		if multiOrigin {
+			key = seg.Origin + "\x00" + key
+		}
+		tb, ok := groups[key]
+		if !ok {
+			tb = &tableBuilder{Table: Table{Destination: seg.Destination, DayLabel: dayLabel}}
+			if multiOrigin {
+				tb.Origin = seg.Origin
+			}
+			groups[key] = tb
+			keys = append(keys, key)
+		}
+		tb.add(seg, specialMarks[conditionGroup(seg.Condition).label])
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		b.Tables = append(b.Tables, groups[key].build())
+	}
+
+	if name := schedule.HolidayName(date); name != "" {
+		b.Legends = append(b.Legends, Legend{Text: fmt.Sprintf("%s は祝日（%s）のため、休日の時刻で運行します", shortDate(date.Format("2006-01-02")), name)})
+	}
+	return b
+}
+
+// ForPeriod は from〜to に運行する便を、行き先とダイヤ（平日・土曜・特別ダイヤなど）ごとの表にまとめます。
+// 特別ダイヤの表には記号を付け、期間中の祝日とあわせて凡例に載せます。
+func ForPeriod(stop string, from, to time.Time, segments []Segment) Board {
+	b := Board{
+		Stop:     stop,
+		Subtitle: fmt.Sprintf("%s〜%sの時刻表", DateLabel(from), DateLabel(to)),
+	}
+
+	var applicable []Segment
+	for _, seg := range segments {
+		for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+			if schedule.PeriodsInclude(seg.Validity, d) && seg.Condition.Applies(d) {
+				applicable = append(applicable, seg)
+				break
+			}
+		}
+	}
+
+	type groupKey struct {
+		origin, destination string
+		day                 dayGroup
+	}
+	multiOrigin := hasMultipleOrigins(applicable)
+	groups := map[groupKey]*tableBuilder{}
+	var keys []groupKey
+	for _, seg := range applicable {
+		day := conditionGroup(seg.Condition)
+		key := groupKey{destination: seg.Destination, day: day}
+		if multiOrigin {
+			key.origin = seg.Origin
+		}
+		tb, ok := groups[key]
+		if !ok {
+			tb = &tableBuilder{Table: Table{Origin: key.origin, Destination: seg.Destination, DayLabel: day.label}}
+			if day.special {
+				tb.DayLabel = day.label + " 特別ダイヤ"
+			}
+			groups[key] = tb
+			keys = append(keys, key)
+		}
+		tb.add(seg, "")
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.origin != b.origin {
+			return a.origin < b.origin
+		}
+		if a.destination != b.destination {
+			return a.destination < b.destination
+		}
+		return a.day.less(b.day)
+	})
+
+	// 記号は行き先によらず日付の早い特別ダイヤから順に割り当てる
+	var specials []dayGroup
+	for _, key := range keys {
+		if key.day.special && !slices.Contains(specials, key.day) {
+			specials = append(specials, key.day)
+		}
+	}
+	sort.Slice(specials, func(i, j int) bool { return specials[i].less(specials[j]) })
+	specialMarks := map[string]string{}
+	for i, g := range specials {
+		specialMarks[g.label] = markFor(i)
+	}
+	for _, key := range keys {
+		tb := groups[key]
+		tb.Mark = specialMarks[key.day.label]
+		b.Tables = append(b.Tables, tb.build())
+	}
+	for i, g := range specials {
+		b.Legends = append(b.Legends, Legend{Mark: markFor(i), Text: g.label + " のみ運行する特別ダイヤ"})
+	}
+
+	var holidays []string
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		if name := schedule.HolidayName(d); name != "" {
+			holidays = append(holidays, fmt.Sprintf("%s（%s）", shortDate(d.Format("2006-01-02")), name))
+		}
+	}
+	if len(holidays) > 0 {
+		b.Legends = append(b.Legends, Legend{Text: "期間中の祝日（休日の時刻で運行）: " + strings.Join(holidays, "、")})
+	}
+	return b
+}
+
+func hasMultipleOrigins(segments []Segment) bool {
+	for _, seg := range segments {
+		if seg.Origin != segments[0].Origin {
+			return true
+		}
+	}
+	return false
+}
+
+// tableBuilder は発車時刻を集めて時ごとの行に並べます
+type tableBuilder struct {
+	Table
+	entries []entry
+}
+
+type entry struct {
+	minutes int // 0:00 からの分
+	mark    string
+	shuttle *Shuttle
+}
+
+func (tb *tableBuilder) add(seg Segment, mark string) {
+	for _, dep := range seg.Departures {
+		if m, ok := clockMinutes(dep); ok {
+			tb.entries = append(tb.entries, entry{minutes: m, mark: mark})
+		}
+	}
+	if seg.Shuttle != nil {
+		if m, ok := clockMinutes(seg.Shuttle.Start); ok {
+			tb.entries = append(tb.entries, entry{minutes: m, mark: mark, shuttle: seg.Shuttle})
+		}
+	}
+}
+
+// build は発車時刻順に並べ、同じ時の便を 1 行にまとめます。シャトル運行は開始時刻の位置に 1 行で挟みます。
+// 同じ時刻の便が複数のサービスにある場合は 1 つにまとめます。
+func (tb *tableBuilder) build() Table {
+	sort.SliceStable(tb.entries, func(i, j int) bool {
+		a, b := tb.entries[i], tb.entries[j]
+		if a.minutes != b.minutes {
+			return a.minutes < b.minutes
+		}
+		// 同じ時刻なら定時便を先に
+		return a.shuttle == nil && b.shuttle != nil
+	})
+
+	t := tb.Table
+	var prev *entry
+	for i := range tb.entries {
+		e := &tb.entries[i]
+		if prev != nil && prev.shuttle == nil && e.shuttle == nil && prev.minutes == e.minutes && prev.mark == e.mark {
+			continue
+		}
+		prev = e
+		if e.shuttle != nil {
+			t.Rows = append(t.Rows, Row{Hour: e.minutes / 60, Shuttle: e.shuttle})
+			continue
+		}
+		hour := e.minutes / 60
+		if n := len(t.Rows); n == 0 || t.Rows[n-1].Shuttle != nil || t.Rows[n-1].Hour != hour {
+			t.Rows = append(t.Rows, Row{Hour: hour})
+		}
+		last := &t.Rows[len(t.Rows)-1]
+		last.Minutes = append(last.Minutes, Minute{Minute: fmt.Sprintf("%02d", e.minutes%60), Mark: e.mark})
+		if len(last.Minutes) > t.Columns {
+			t.Columns = len(last.Minutes)
+		}
+	}
+	if t.Columns == 0 {
+		t.Columns = 1 // シャトル運行のみの表
+	}
+	return t
+}
+
+// clockMinutes は "H:MM" を 0:00 からの分に変換します
+func clockMinutes(s string) (int, bool) {
+	h, m, ok := strings.Cut(s, ":")
+	if !ok {
+		return 0, false
+	}
+	hour, err := strconv.Atoi(h)
+	if err != nil {
+		return 0, false
+	}
+	minute, err := strconv.Atoi(m)
+	if err != nil || minute < 0 || minute > 59 {
+		return 0, false
+	}
+	return hour*60 + minute, true
+}
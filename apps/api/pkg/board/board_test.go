@@ -0,0 +1,190 @@
+package board
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"api/pkg/schedule"
+)
+
+func day(s string) time.Time {
+	d, err := time.ParseInLocation("2006-01-02", s, time.Local)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func weekday() schedule.Condition {
+	return schedule.Condition{Type: schedule.ConditionTypeDayType, Value: string(schedule.DayTypeWeekday)}
+}
+
+func specificDate(date string) schedule.Condition {
+	return schedule.Condition{Type: schedule.ConditionTypeSpecificDate, Value: date}
+}
+
+func TestForDate_MarksSpecialDepartures(t *testing.T) {
+	segments := []Segment{
+		{Origin: "八王子駅", Destination: "大学", Condition: weekday(), Departures: []string{"8:00", "8:30"}},
+		{Origin: "八王子駅", Destination: "大学", Condition: specificDate("2026-05-12"), Departures: []string{"8:15"}},
+		// 別の日の特別ダイヤは表にも凡例にも出さない
+		{Origin: "八王子駅", Destination: "大学", Condition: specificDate("2026-05-13"), Departures: []string{"8:45"}},
+	}
+
+	b := ForDate("八王子駅", day("2026-05-12"), segments)
+	want := []Table{{
+		Destination: "大学",
+		DayLabel:    "平日",
+		Columns:     3,
+		Rows:        []Row{{Hour: 8, Minutes: []Minute{{Minute: "00"}, {Minute: "15", Mark: "◆"}, {Minute: "30"}}}},
+	}}
+	if !reflect.DeepEqual(b.Tables, want) {
+		t.Errorf("Tables = %+v, want %+v", b.Tables, want)
+	}
+	if wantLegends := []Legend{{Mark: "◆", Text: "5/12 の特別ダイヤで運行する便"}}; !reflect.DeepEqual(b.Legends, wantLegends) {
+		t.Errorf("Legends = %+v, want %+v", b.Legends, wantLegends)
+	}
+}
+
+func TestForPeriod_MarksInDateOrder(t *testing.T) {
+	segments := []Segment{
+		{Origin: "大学", Destination: "南大沢駅", Condition: weekday(), Departures: []string{"9:00"}},
+		{Origin: "大学", Destination: "南大沢駅", Departures: []string{"10:00"},
+			Condition: schedule.Condition{Type: schedule.ConditionTypeSpecificPeriod, From: "2026-05-08", To: "2026-05-09"}},
+		// 並び順では先に来る行き先でも、記号は日付の早い特別ダイヤから割り当てる
+		{Origin: "大学", Destination: "八王子駅", Condition: specificDate("2026-05-12"), Departures: []string{"11:00"}},
+		// 期間外の特別ダイヤは載せない
+		{Origin: "大学", Destination: "八王子駅", Condition: specificDate("2026-06-01"), Departures: []string{"12:00"}},
+	}
+
+	b := ForPeriod("大学", day("2026-05-07"), day("2026-05-15"), segments)
+	type summary struct{ Destination, DayLabel, Mark string }
+	var got []summary
+	for _, table := range b.Tables {
+		got = append(got, summary{table.Destination, table.DayLabel, table.Mark})
+	}
+	want := []summary{
+		{"八王子駅", "5/12 特別ダイヤ", "●"},
+		{"南大沢駅", "平日", ""},
+		{"南大沢駅", "5/8〜5/9 特別ダイヤ", "◆"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tables = %+v, want %+v", got, want)
+	}
+	wantLegends := []Legend{
+		{Mark: "◆", Text: "5/8〜5/9 のみ運行する特別ダイヤ"},
+		{Mark: "●", Text: "5/12 のみ運行する特別ダイヤ"},
+	}
+	if !reflect.DeepEqual(b.Legends, wantLegends) {
+		t.Errorf("Legends = %+v, want %+v", b.Legends, wantLegends)
+	}
+}
+
+func TestMarkFor_BeyondSymbols(t *testing.T) {
+	if got := markFor(0); got != "◆" {
+		t.Errorf("markFor(0) = %q", got)
+	}
+	if got := markFor(len(marks)); got != "※6" {
+		t.Errorf("markFor(%d) = %q, want ※6", len(marks), got)
+	}
+}
+
+func TestForDate_ShuttleRows(t *testing.T) {
+	shuttle := &Shuttle{Start: "8:00", End: "9:00", MinInterval: 3, MaxInterval: 5}
+	segments := []Segment{
+		{Destination: "大学", Condition: weekday(), Departures: []string{"7:50", "8:00", "9:10"}},
+		// 別のサービスの同じ時刻の便は 1 つにまとめる
+		{Destination: "大学", Condition: weekday(), Departures: []string{"7:50"}},
+		{Destination: "大学", Condition: weekday(), Shuttle: shuttle},
+	}
+
+	b := ForDate("八王子駅", day("2026-05-12"), segments)
+	if len(b.Tables) != 1 {
+		t.Fatalf("Tables = %+v, want 1 table", b.Tables)
+	}
+	want := []Row{
+		{Hour: 7, Minutes: []Minute{{Minute: "50"}}},
+		// 同じ時刻なら定時便がシャトル運行の前に来る
+		{Hour: 8, Minutes: []Minute{{Minute: "00"}}},
+		{Hour: 8, Shuttle: shuttle},
+		{Hour: 9, Minutes: []Minute{{Minute: "10"}}},
+	}
+	if got := b.Tables[0].Rows; !reflect.DeepEqual(got, want) {
+		t.Errorf("Rows = %+v, want %+v", got, want)
+	}
+	if got := b.Tables[0].Columns; got != 1 {
+		t.Errorf("Columns = %d, want 1", got)
+	}
+
+	if got := shuttle.Label(); got != "～ 約3〜5分間隔 ～" {
+		t.Errorf("Label() = %q", got)
+	}
+	if got := (Shuttle{MinInterval: 5, MaxInterval: 5}).Label(); got != "～ 約5分間隔 ～" {
+		t.Errorf("Label() = %q", got)
+	}
+
+	only := ForDate("八王子駅", day("2026-05-12"), segments[2:])
+	if got := only.Tables[0].Columns; got != 1 {
+		t.Errorf("shuttle-only Columns = %d, want 1", got)
+	}
+}
+
+func TestForDate_HolidayLegend(t *testing.T) {
+	segments := []Segment{
+		{Destination: "大学", Condition: weekday(), Departures: []string{"8:00"}},
+		{Destination: "大学", Condition: schedule.Condition{Type: schedule.ConditionTypeDayType, Value: string(schedule.DayTypeHoliday)}, Departures: []string{"9:00"}},
+	}
+
+	b := ForDate("八王子駅", day("2026-04-29"), segments)
+	if b.Subtitle != "2026年4月29日（水・昭和の日）の時刻表" {
+		t.Errorf("Subtitle = %q", b.Subtitle)
+	}
+	if len(b.Tables) != 1 || b.Tables[0].DayLabel != "休日" || b.Tables[0].Rows[0].Hour != 9 {
+		t.Errorf("Tables = %+v, want only the holiday departures", b.Tables)
+	}
+	want := []Legend{{Text: "4/29 は祝日（昭和の日）のため、休日の時刻で運行します"}}
+	if !reflect.DeepEqual(b.Legends, want) {
+		t.Errorf("Legends = %+v, want %+v", b.Legends, want)
+	}
+}
+
+func TestForPeriod_HolidayLegend(t *testing.T) {
+	segments := []Segment{{Destination: "大学", Condition: weekday(), Departures: []string{"8:00"}}}
+
+	b := ForPeriod("八王子駅", day("2026-04-28"), day("2026-05-06"), segments)
+	want := []Legend{{Text: "期間中の祝日（休日の時刻で運行）: 4/29（昭和の日）、5/3（憲法記念日）、5/4（みどりの日）、5/5（こどもの日）、5/6（振替休日）"}}
+	if !reflect.DeepEqual(b.Legends, want) {
+		t.Errorf("Legends = %+v, want %+v", b.Legends, want)
+	}
+
+	if b := ForPeriod("八王子駅", day("2026-05-11"), day("2026-05-15"), segments); len(b.Legends) != 0 {
+		t.Errorf("Legends = %+v, want none without holidays", b.Legends)
+	}
+}
+
+func TestConditionLabel(t *testing.T) {
+	tests := []struct {
+		cond schedule.Condition
+		want string
+	}{
+		{weekday(), "平日"},
+		{schedule.Condition{Type: schedule.ConditionTypeDayType, Value: string(schedule.DayTypeWednesday)}, "水曜"},
+		{schedule.Condition{Type: schedule.ConditionTypeDayType, Value: "unknown"}, "unknown"},
+		{specificDate("2026-05-12"), "2026-05-12"},
+		{schedule.Condition{Type: schedule.ConditionTypeSpecificPeriod, From: "2026-05-08", To: "2026-05-09"}, "2026-05-08〜2026-05-09"},
+		{schedule.Condition{Type: schedule.ConditionTypeSpecificPeriod, From: "2026-05-08", To: "2026-05-08"}, "2026-05-08"},
+	}
+	for _, tt := range tests {
+		if got := ConditionLabel(tt.cond); got != tt.want {
+			t.Errorf("ConditionLabel(%+v) = %q, want %q", tt.cond, got, tt.want)
+		}
+	}
+	// 曜日の表示名は表の見出しと同じ
+	for _, d := range dayTypeLabels {
+		cond := schedule.Condition{Type: schedule.ConditionTypeDayType, Value: string(d.value)}
+		if got, heading := ConditionLabel(cond), conditionGroup(cond).label; got != heading {
+			t.Errorf("ConditionLabel(%s) = %q, heading = %q", d.value, got, heading)
+		}
+	}
+}
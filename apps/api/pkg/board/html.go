@@ -0,0 +1,17 @@
+package board
+
+import (
+	_ "embed"
+	"html/template"
+	"io"
+)
+
+//go:embed board.html.tmpl
+var htmlTemplateText string
+
+var htmlTemplate = template.Must(template.New("board").Parse(htmlTemplateText))
+
+// WriteHTML は印刷用の HTML を w に書き出します。同じ入力からは常に同じ出力になります
+func (b Board) WriteHTML(w io.Writer) error {
+	return htmlTemplate.Execute(w, b)
+}
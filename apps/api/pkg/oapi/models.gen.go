@@ -20,6 +20,20 @@ import (
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
+// Defines values for ErrorsErrorCode.
+const (
+	BadRequest           ErrorsErrorCode = "BadRequest"
+	BusStopGroupNotFound ErrorsErrorCode = "BusStopGroupNotFound"
+	BusStopNotFound      ErrorsErrorCode = "BusStopNotFound"
+	InternalServerError  ErrorsErrorCode = "InternalServerError"
+	InvalidDate          ErrorsErrorCode = "InvalidDate"
+	InvalidParameter     ErrorsErrorCode = "InvalidParameter"
+	InvalidPeriod        ErrorsErrorCode = "InvalidPeriod"
+	MethodNotAllowed     ErrorsErrorCode = "MethodNotAllowed"
+	NotFound             ErrorsErrorCode = "NotFound"
+	Unauthorized         ErrorsErrorCode = "Unauthorized"
+)
+
 // Defines values for ModelsFixedSegmentSegmentType.
 const (
 	Fixed ModelsFixedSegmentSegmentType = "fixed"
@@ -30,20 +44,26 @@ const (
 	Shuttle ModelsShuttleSegmentSegmentType = "shuttle"
 )
 
-// Defines values for RoutesTimetableBadRequestCode.
-const (
-	BadRequest RoutesTimetableBadRequestCode = "BadRequest"
-)
+// ErrorsErrorCode 機械可読なエラーコード。値は変更しません。
+type ErrorsErrorCode string
 
-// Defines values for RoutesTimetableBadRequestDetail.
-const (
-	ThedateQueryMustBeInYYYYMMDDFormat RoutesTimetableBadRequestDetail = "The 'date' query must be in YYYY-MM-DD format."
-)
+// ErrorsErrorResponse すべての API が返す共通のエラーレスポンス
+type ErrorsErrorResponse struct {
+	// Code 機械可読なエラーコード。値は変更しません。
+	Code ErrorsErrorCode `json:"code"`
 
-// Defines values for RoutesTimetableBadRequestMessage.
-const (
-	InvalidDate RoutesTimetableBadRequestMessage = "InvalidDate"
-)
+	// Field 原因となったパラメータ（例: query.date, path.id）
+	Field *string `json:"field,omitempty"`
+
+	// Message Accept-Language に応じた利用者向けのメッセージ（ja / en、既定は ja）
+	Message string `json:"message"`
+
+	// RequestId リクエスト ID。X-Request-Id レスポンスヘッダーと同じ値です。
+	RequestId string `json:"requestId"`
+
+	// Status HTTP ステータスコード
+	Status int32 `json:"status"`
+}
 
 // ModelsBusStop defines model for Models.BusStop.
 type ModelsBusStop struct {
@@ -62,10 +82,13 @@ type ModelsBusStopGroup struct {
 
 // ModelsBusStopGroupTimetable defines model for Models.BusStopGroupTimetable.
 type ModelsBusStopGroupTimetable struct {
-	Date     ScalarsDateISO         `json:"date"`
-	Id       int32                  `json:"id"`
-	Name     string                 `json:"name"`
-	Segments []ModelsBusStopSegment `json:"segments"`
+	Date ScalarsDateISO `json:"date"`
+	Id   int32          `json:"id"`
+
+	// IncludesArchived アーカイブ済み（期限切れ）の時刻表を含む場合 true
+	IncludesArchived bool                   `json:"includesArchived"`
+	Name             string                 `json:"name"`
+	Segments         []ModelsBusStopSegment `json:"segments"`
 }
 
 // ModelsBusStopSegment defines model for Models.BusStopSegment.
@@ -75,16 +98,21 @@ type ModelsBusStopSegment struct {
 
 // ModelsBusStopTimetable defines model for Models.BusStopTimetable.
 type ModelsBusStopTimetable struct {
-	Date     ScalarsDateISO         `json:"date"`
-	Id       int32                  `json:"id"`
-	Lat      ScalarsLatitude        `json:"lat"`
-	Lon      ScalarsLongitude       `json:"lon"`
-	Name     string                 `json:"name"`
-	Segments []ModelsBusStopSegment `json:"segments"`
+	Date ScalarsDateISO `json:"date"`
+	Id   int32          `json:"id"`
+
+	// IncludesArchived アーカイブ済み（期限切れ）の時刻表を含む場合 true
+	IncludesArchived bool                   `json:"includesArchived"`
+	Lat              ScalarsLatitude        `json:"lat"`
+	Lon              ScalarsLongitude       `json:"lon"`
+	Name             string                 `json:"name"`
+	Segments         []ModelsBusStopSegment `json:"segments"`
 }
 
 // ModelsFixedSegment defines model for Models.FixedSegment.
 type ModelsFixedSegment struct {
+	// Archived アーカイブ済み（期限切れ）の時刻表から取得したセグメントの場合 true
+	Archived    *bool                         `json:"archived,omitempty"`
 	Destination ModelsStopRef                 `json:"destination"`
 	SegmentType ModelsFixedSegmentSegmentType `json:"segmentType"`
 	Times       []ModelsTimePair              `json:"times"`
@@ -95,6 +123,8 @@ type ModelsFixedSegmentSegmentType string
 
 // ModelsShuttleSegment defines model for Models.ShuttleSegment.
 type ModelsShuttleSegment struct {
+	// Archived アーカイブ済み（期限切れ）の時刻表から取得したセグメントの場合 true
+	Archived      *bool          `json:"archived,omitempty"`
 	Destination   ModelsStopRef  `json:"destination"`
 	EndTime       ScalarsTimeISO `json:"endTime"`
 	IntervalRange struct {
@@ -122,22 +152,6 @@ type ModelsTimePair struct {
 	Departure ScalarsTimeISO `json:"departure"`
 }
 
-// RoutesTimetableBadRequest HTTP 400 Bad Request - The request cannot be processed due to client error.
-type RoutesTimetableBadRequest struct {
-	Code    RoutesTimetableBadRequestCode    `json:"code"`
-	Detail  RoutesTimetableBadRequestDetail  `json:"detail"`
-	Message RoutesTimetableBadRequestMessage `json:"message"`
-}
-
-// RoutesTimetableBadRequestCode defines model for RoutesTimetableBadRequest.Code.
-type RoutesTimetableBadRequestCode string
-
-// RoutesTimetableBadRequestDetail defines model for RoutesTimetableBadRequest.Detail.
-type RoutesTimetableBadRequestDetail string
-
-// RoutesTimetableBadRequestMessage defines model for RoutesTimetableBadRequest.Message.
-type RoutesTimetableBadRequestMessage string
-
 // ScalarsDateISO defines model for Scalars.DateISO.
 type ScalarsDateISO = openapi_types.Date
 
@@ -158,11 +172,53 @@ type BusStopServiceGetAllBusStopsParams struct {
 // BusStopGroupsServiceGetBusStopGroupsTimetableParams defines parameters for BusStopGroupsServiceGetBusStopGroupsTimetable.
 type BusStopGroupsServiceGetBusStopGroupsTimetableParams struct {
 	Date *ScalarsDateISO `form:"date,omitempty" json:"date,omitempty"`
+
+	// IncludeHistorical 期限切れでアーカイブ済みの時刻表も対象にするかどうか。省略時は過去の日付のときのみ対象にします。
+	IncludeHistorical *bool `form:"includeHistorical,omitempty" json:"includeHistorical,omitempty"`
+
+	// IfNoneMatch 以前に受け取った ETag。一致した場合は 304 Not Modified を返します。
+	IfNoneMatch *string `json:"If-None-Match,omitempty"`
+}
+
+// BusStopGroupsServiceGetBusStopGroupPrintableTimetableParams defines parameters for BusStopGroupsServiceGetBusStopGroupPrintableTimetable.
+type BusStopGroupsServiceGetBusStopGroupPrintableTimetableParams struct {
+	// Date 1 日分の時刻表を出力する日付。from/to と同時には指定できません。いずれも省略時は今日です。
+	Date *ScalarsDateISO `form:"date,omitempty" json:"date,omitempty"`
+
+	// From 期間の時刻表を出力するときの開始日。to と同時に指定します。
+	From *ScalarsDateISO `form:"from,omitempty" json:"from,omitempty"`
+
+	// To 期間の時刻表を出力するときの終了日（366 日以内）。
+	To *ScalarsDateISO `form:"to,omitempty" json:"to,omitempty"`
+
+	// IncludeHistorical 期限切れでアーカイブ済みの時刻表も対象にするかどうか。省略時は過去の日付のときのみ対象にします。
+	IncludeHistorical *bool `form:"includeHistorical,omitempty" json:"includeHistorical,omitempty"`
 }
 
 // BusStopServiceGetBusStopTimetableParams defines parameters for BusStopServiceGetBusStopTimetable.
 type BusStopServiceGetBusStopTimetableParams struct {
 	Date *ScalarsDateISO `form:"date,omitempty" json:"date,omitempty"`
+
+	// IncludeHistorical 期限切れでアーカイブ済みの時刻表も対象にするかどうか。省略時は過去の日付のときのみ対象にします。
+	IncludeHistorical *bool `form:"includeHistorical,omitempty" json:"includeHistorical,omitempty"`
+
+	// IfNoneMatch 以前に受け取った ETag。一致した場合は 304 Not Modified を返します。
+	IfNoneMatch *string `json:"If-None-Match,omitempty"`
+}
+
+// BusStopServiceGetBusStopPrintableTimetableParams defines parameters for BusStopServiceGetBusStopPrintableTimetable.
+type BusStopServiceGetBusStopPrintableTimetableParams struct {
+	// Date 1 日分の時刻表を出力する日付。from/to と同時には指定できません。いずれも省略時は今日です。
+	Date *ScalarsDateISO `form:"date,omitempty" json:"date,omitempty"`
+
+	// From 期間の時刻表を出力するときの開始日。to と同時に指定します。
+	From *ScalarsDateISO `form:"from,omitempty" json:"from,omitempty"`
+
+	// To 期間の時刻表を出力するときの終了日（366 日以内）。
+	To *ScalarsDateISO `form:"to,omitempty" json:"to,omitempty"`
+
+	// IncludeHistorical 期限切れでアーカイブ済みの時刻表も対象にするかどうか。省略時は過去の日付のときのみ対象にします。
+	IncludeHistorical *bool `form:"includeHistorical,omitempty" json:"includeHistorical,omitempty"`
 }
 
 // AsModelsFixedSegment returns the union data inside the ModelsBusStopSegment as a ModelsFixedSegment
@@ -242,11 +298,34 @@ type ServerInterface interface {
 	// (GET /api/bus-stops/groups/{id}/timetable)
 	BusStopGroupsServiceGetBusStopGroupsTimetable(ctx echo.Context, id int32, params BusStopGroupsServiceGetBusStopGroupsTimetableParams) error
 
+	// (GET /api/bus-stops/groups/{id}/timetable/print)
+	BusStopGroupsServiceGetBusStopGroupPrintableTimetable(ctx echo.Context, id int32, params BusStopGroupsServiceGetBusStopGroupPrintableTimetableParams) error
+
 	// (GET /api/bus-stops/{id})
 	BusStopServiceGetBusStopDetails(ctx echo.Context, id int32) error
 
 	// (GET /api/bus-stops/{id}/timetable)
 	BusStopServiceGetBusStopTimetable(ctx echo.Context, id int32, params BusStopServiceGetBusStopTimetableParams) error
+
+	// (GET /api/bus-stops/{id}/timetable/print)
+	BusStopServiceGetBusStopPrintableTimetable(ctx echo.Context, id int32, params BusStopServiceGetBusStopPrintableTimetableParams) error
+}
+
+// InvalidParamFormatError is set as the Internal error of the echo.HTTPError returned
+// when a parameter cannot be bound to its Go type.
+type InvalidParamFormatError struct {
+	ParamName string
+	// In is the parameter location: path, query, header or cookie.
+	In  string
+	Err error
+}
+
+func (e *InvalidParamFormatError) Error() string {
+	return fmt.Sprintf("Invalid format for parameter %s: %s", e.ParamName, e.Err.Error())
+}
+
+func (e *InvalidParamFormatError) Unwrap() error {
+	return e.Err
 }
 
 // ServerInterfaceWrapper converts echo contexts to parameters.
@@ -264,7 +343,7 @@ func (w *ServerInterfaceWrapper) BusStopServiceGetAllBusStops(ctx echo.Context)
 
 	err = runtime.BindQueryParameter("form", false, false, "group_id", ctx.QueryParams(), &params.GroupId)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter group_id: %s", err))
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter group_id: %s", err)).SetInternal(&InvalidParamFormatError{ParamName: "group_id", In: "query", Err: err})
 	}
 
 	// Invoke the callback with all the unmarshaled arguments
@@ -289,7 +368,7 @@ func (w *ServerInterfaceWrapper) BusStopGroupsServiceGetBusStopGroupDetails(ctx
 
 	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err)).SetInternal(&InvalidParamFormatError{ParamName: "id", In: "path", Err: err})
 	}
 
 	// Invoke the callback with all the unmarshaled arguments
@@ -305,7 +384,7 @@ func (w *ServerInterfaceWrapper) BusStopGroupsServiceGetBusStopGroupsTimetable(c
 
 	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err)).SetInternal(&InvalidParamFormatError{ParamName: "id", In: "path", Err: err})
 	}
 
 	// Parameter object where we will unmarshal all parameters from the context
@@ -314,7 +393,31 @@ func (w *ServerInterfaceWrapper) BusStopGroupsServiceGetBusStopGroupsTimetable(c
 
 	err = runtime.BindQueryParameter("form", true, false, "date", ctx.QueryParams(), &params.Date)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter date: %s", err))
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter date: %s", err)).SetInternal(&InvalidParamFormatError{ParamName: "date", In: "query", Err: err})
+	}
+
+	// ------------- Optional query parameter "includeHistorical" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "includeHistorical", ctx.QueryParams(), &params.IncludeHistorical)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter includeHistorical: %s", err)).SetInternal(&InvalidParamFormatError{ParamName: "includeHistorical", In: "query", Err: err})
+	}
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "If-None-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-None-Match")]; found {
+		var IfNoneMatch string
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for If-None-Match, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "If-None-Match", valueList[0], &IfNoneMatch, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter If-None-Match: %s", err)).SetInternal(&InvalidParamFormatError{ParamName: "If-None-Match", In: "header", Err: err})
+		}
+
+		params.IfNoneMatch = &IfNoneMatch
 	}
 
 	// Invoke the callback with all the unmarshaled arguments
@@ -322,6 +425,52 @@ func (w *ServerInterfaceWrapper) BusStopGroupsServiceGetBusStopGroupsTimetable(c
 	return err
 }
 
+// BusStopGroupsServiceGetBusStopGroupPrintableTimetable converts echo context to params.
+func (w *ServerInterfaceWrapper) BusStopGroupsServiceGetBusStopGroupPrintableTimetable(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id int32
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err)).SetInternal(&InvalidParamFormatError{ParamName: "id", In: "path", Err: err})
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params BusStopGroupsServiceGetBusStopGroupPrintableTimetableParams
+	// ------------- Optional query parameter "date" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "date", ctx.QueryParams(), &params.Date)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter date: %s", err)).SetInternal(&InvalidParamFormatError{ParamName: "date", In: "query", Err: err})
+	}
+
+	// ------------- Optional query parameter "from" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "from", ctx.QueryParams(), &params.From)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter from: %s", err)).SetInternal(&InvalidParamFormatError{ParamName: "from", In: "query", Err: err})
+	}
+
+	// ------------- Optional query parameter "to" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "to", ctx.QueryParams(), &params.To)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter to: %s", err)).SetInternal(&InvalidParamFormatError{ParamName: "to", In: "query", Err: err})
+	}
+
+	// ------------- Optional query parameter "includeHistorical" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "includeHistorical", ctx.QueryParams(), &params.IncludeHistorical)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter includeHistorical: %s", err)).SetInternal(&InvalidParamFormatError{ParamName: "includeHistorical", In: "query", Err: err})
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.BusStopGroupsServiceGetBusStopGroupPrintableTimetable(ctx, id, params)
+	return err
+}
+
 // BusStopServiceGetBusStopDetails converts echo context to params.
 func (w *ServerInterfaceWrapper) BusStopServiceGetBusStopDetails(ctx echo.Context) error {
 	var err error
@@ -330,7 +479,7 @@ func (w *ServerInterfaceWrapper) BusStopServiceGetBusStopDetails(ctx echo.Contex
 
 	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err)).SetInternal(&InvalidParamFormatError{ParamName: "id", In: "path", Err: err})
 	}
 
 	// Invoke the callback with all the unmarshaled arguments
@@ -346,7 +495,7 @@ func (w *ServerInterfaceWrapper) BusStopServiceGetBusStopTimetable(ctx echo.Cont
 
 	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err)).SetInternal(&InvalidParamFormatError{ParamName: "id", In: "path", Err: err})
 	}
 
 	// Parameter object where we will unmarshal all parameters from the context
@@ -355,7 +504,31 @@ func (w *ServerInterfaceWrapper) BusStopServiceGetBusStopTimetable(ctx echo.Cont
 
 	err = runtime.BindQueryParameter("form", true, false, "date", ctx.QueryParams(), &params.Date)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter date: %s", err))
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter date: %s", err)).SetInternal(&InvalidParamFormatError{ParamName: "date", In: "query", Err: err})
+	}
+
+	// ------------- Optional query parameter "includeHistorical" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "includeHistorical", ctx.QueryParams(), &params.IncludeHistorical)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter includeHistorical: %s", err)).SetInternal(&InvalidParamFormatError{ParamName: "includeHistorical", In: "query", Err: err})
+	}
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "If-None-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-None-Match")]; found {
+		var IfNoneMatch string
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for If-None-Match, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "If-None-Match", valueList[0], &IfNoneMatch, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter If-None-Match: %s", err)).SetInternal(&InvalidParamFormatError{ParamName: "If-None-Match", In: "header", Err: err})
+		}
+
+		params.IfNoneMatch = &IfNoneMatch
 	}
 
 	// Invoke the callback with all the unmarshaled arguments
@@ -363,6 +536,52 @@ func (w *ServerInterfaceWrapper) BusStopServiceGetBusStopTimetable(ctx echo.Cont
 	return err
 }
 
+// BusStopServiceGetBusStopPrintableTimetable converts echo context to params.
+func (w *ServerInterfaceWrapper) BusStopServiceGetBusStopPrintableTimetable(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id int32
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err)).SetInternal(&InvalidParamFormatError{ParamName: "id", In: "path", Err: err})
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params BusStopServiceGetBusStopPrintableTimetableParams
+	// ------------- Optional query parameter "date" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "date", ctx.QueryParams(), &params.Date)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter date: %s", err)).SetInternal(&InvalidParamFormatError{ParamName: "date", In: "query", Err: err})
+	}
+
+	// ------------- Optional query parameter "from" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "from", ctx.QueryParams(), &params.From)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter from: %s", err)).SetInternal(&InvalidParamFormatError{ParamName: "from", In: "query", Err: err})
+	}
+
+	// ------------- Optional query parameter "to" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "to", ctx.QueryParams(), &params.To)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter to: %s", err)).SetInternal(&InvalidParamFormatError{ParamName: "to", In: "query", Err: err})
+	}
+
+	// ------------- Optional query parameter "includeHistorical" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "includeHistorical", ctx.QueryParams(), &params.IncludeHistorical)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter includeHistorical: %s", err)).SetInternal(&InvalidParamFormatError{ParamName: "includeHistorical", In: "query", Err: err})
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.BusStopServiceGetBusStopPrintableTimetable(ctx, id, params)
+	return err
+}
+
 // This is a simple interface which specifies echo.Route addition functions which
 // are present on both echo.Echo and echo.Group, since we want to allow using
 // either of them for path registration
@@ -395,42 +614,62 @@ func RegisterHandlersWithBaseURL(router EchoRouter, si ServerInterface, baseURL
 	router.GET(baseURL+"/api/bus-stops/groups", wrapper.BusStopGroupsServiceGetAllBusStopGroups)
 	router.GET(baseURL+"/api/bus-stops/groups/:id", wrapper.BusStopGroupsServiceGetBusStopGroupDetails)
 	router.GET(baseURL+"/api/bus-stops/groups/:id/timetable", wrapper.BusStopGroupsServiceGetBusStopGroupsTimetable)
+	router.GET(baseURL+"/api/bus-stops/groups/:id/timetable/print", wrapper.BusStopGroupsServiceGetBusStopGroupPrintableTimetable)
 	router.GET(baseURL+"/api/bus-stops/:id", wrapper.BusStopServiceGetBusStopDetails)
 	router.GET(baseURL+"/api/bus-stops/:id/timetable", wrapper.BusStopServiceGetBusStopTimetable)
+	router.GET(baseURL+"/api/bus-stops/:id/timetable/print", wrapper.BusStopServiceGetBusStopPrintableTimetable)
 
 }
 
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/+xZe28TyxX/KqMpEq3kF5BKdP+piCJopPJQkqqKQlqNd4/tobs7y8xslMi1xNpNoS9R",
-	"RdCUCvUhAUlB4nIF94HgwocZHPwxrmbWb2+cdeIb0L38Aco+5pzf+Z3zO3NmXcU28wLmgy8FtqpY2BXw",
-	"iPnzInPAFbnZUCxKFug7AWcBcEnBPKeO/r/EuEcktjD15ZnTOIPlRgDxJZSB41oGu/p5FZ/gUMIW/lG+",
-	"5zHfdpdftIlLuMj9kkgqQwfMMr+cehnzy911PvFAL2wDEZJTv4xrtQzmcD2kHBxsrWj07VdXu5hZ8RrY",
-	"UtsYjP0CZ2ECAcX4aUyGBE8cBHeI0VrXMeGcbOjr1JxOGmSmhzZlvEvUA0mKLowG7hAJaVMzRyTML16e",
-	"RnAZLKDsdSr1EIwvxstHiR9Dm4m1z/PB7HW8WFXMfLhcwtZKKpDn6To4PYip1ixWQild6K5aHUHzAdN4",
-	"WN0zf5q6/yBVo0OPI5moggZKYDRfICT1iaQHE9QpD8mCBSj1kbBkPFcx+KGnQZe0xz5IPdYk9WBiynS1",
-	"XSGUH0hWP5zMQGQdz2NoGqr66RIFvqOjSFuC+t2OMHwJfI24C8QvJ8jNI+spheNRP9WbQ5zqZRnjJom7",
-	"fQpAxFwmloCQhMtDkTFBtntOetwPczmuFtqZG6H7mKcOIVkwn7Yz6pcvpdrB22b7lozhoqu+ETII53SN",
-	"uIcoagcCwmXIj1wDPUOZLpykWBZYKCE2Y/atWeIswPUQhGxL2+Y0iKWNf7G0dAXNFApoljio/RbKoqUK",
-	"IN6+sonvM4mKgALObBACHOSEgCRDtkvBlwg4ZzyHM0OU2cwZEEofjiStOCAJdfsXaBQndfM/ia6HwDeQ",
-	"FwoDhPpoeXl5OXvxYnZuDsXlkks06oEQpDwAY95fIy519H6csGSIcxNDz0wXZRLvwzt9fyW3t7ARfCMC",
-	"GljEQj12mI5EPY3+ZwXT2+KLrL5qW/RDrxgrY1Rb402eOjtg01zua7RTmloeRErguoZ+8+OfWyuFU6tX",
-	"rzq/P71SyJ5Z/Ym1Usj+VN84MRp0zTT6EjPSpbpzWniJ/W6DoV/5dA24oHIDsRJaArviM5eVN9BsKNCv",
-	"oYjOXZlHi8DXqK1DMO+aKi7kCrmCxskC8ElAsYXPmFsZDbNiqjFPApovhiIrOieOMiQIorm5qxp/V/WX",
-	"zei+ip6++/pG69GOqm81b/+j+WZbRdsqeqOie+pGXdUfq8a2qn+lGjuq8VxFO6isx/7fUgep+tbeX282",
-	"n/5Lv1r/i4p2Vf2ZajxRjdd6TbTz/ot/q/qfW29eq+ht8/ZnrcY3KtqJ7WITBjfdXfdD3J2iTOAXQJ5z",
-	"3dnOWURHyIkHErgwczKsB64RXom4AjTV2MJGPp3hysIdnLormuaTbrNc1doQAfNFLPDThUKsc1+2pwgS",
-	"BC61DfT8NREPDz0PUzni1Uyr6E9Zf7OqEIFEaNsADjg5I2dJysK0n1Cg9vlN3x6sh7yhJGVZDKRyXIns",
-	"l0pzPBRJCY2f4OMnOj6hT59t1I5of87zVerU9iV+P9Zb/3/+/sUzFT1R0QMV/UFFjw5Pf//tOdPfE3Rl",
-	"dKS7SU9GRkC93ULyEI5XUBOnd7J0ZvBMYWYiQInDxQy6xCQ6z0LfGRwtwEEcBAu5DchhIJCeM2CdCnnw",
-	"MHGJSWNxzCghhmeJnttiKJCuwbhjjzhPOUn0czsGz/iRYnSSSE6SAL4GvDONlajvIJlI5ZF0mJf9XzuS",
-	"FdknxOYfN5ubu83o/vu79/b+dENFT/fu1Zu3XrX+t6vqW0aIu6oeTU2dovcx5lgEmqkm7p+dDxKphDjy",
-	"Aei4Zd/jLKG0OmPKtor+s7f98N2rfw4lMSFxpjFMD/KY88o0AX/qZB9BJztU9iZsZynniaPNECP96Xs6",
-	"OCQlcZTDvcZm87+f/3DFdzTZfaeKmzBZqQ9MaaeFPvfjdd56cHPv7rN3r75svb3T/NuLQ4nw03gwkbhT",
-	"TAZ72w8HMtd4rOovVeOWqm+13t752MaCSdF+aksfqi1Nnq/9OpP5ZUafjWKdD7oJONMiD7mLLVyRMhBW",
-	"Pl9hZdD/crBOvMCFnM0883vx4FqX2cTNOrA2YMDK582DChPSOlsoFLAWbBtZtdMCegi13aGbneGltlr7",
-	"NgAA///6Es4CPiIAAA==",
+	"H4sIAAAAAAAC/+xb7VPbRv7/VzT764vfzfgpJddp/aaT9JGZJs0EOnczCXezSGtbOVnrSisKx3nGKx9g",
+	"EhJIm0Bo6NGkEJxwheSSXh6g8McsMuRV/oWbXdmWZQljSA4azi/aCFna/Xw/34f97mftYSDjbA7rSCcm",
+	"SA4DU86gLBSXnxgGNsyY+OcjrCB+T0GmbKg5omIdJEHl/nzl7kNncnXnwc+MPmB2mRXvs+I6sx/z/xfH",
+	"WcF2CguMrjoL45XbTxidYXSD0dvM/o4VbBABSLeyIHkBnIbKefS1hUwCIqBbH4CaqpyDBswiggzv1seQ",
+	"oIYHkKFiBUTAWUw+xZbOL09bZg/BueCdzwxsNd7+SocWyWBD/Svif55BJIOVs5ic0jT8jbjVrRNk6FDr",
+	"QcYAMgQNoC8CyFAOgSQwiaHqaZCP+Hg6j8wc1s0QrhidZfQ5o/cYXZFOneuWGJ3Y2bzB6Kwz8uhl4XtG",
+	"Vzz+iv9k9nNW/IEVHzP7OYiAnIFzyCAqEp6Rq954x0ApkAT/F/d8GK86MB7wXj4CUirSlCA059q8c/tH",
+	"Rsvch/QnRudZ8bpAckc4c/PVemlr40pS+tpCxlBMgQRFpBwkmZiqvFofByGcZJFpwnQIDadkGeVI9Auo",
+	"py2YRhKjy87mHKO3GJ13Sve3b5R3CiPO1HVGr3NGOIIis9cEjmev1kuXoBSXkM4KtDJz11n5ntFV6RLc",
+	"BYXhhlR3iM2s+IDZq5xxTnRJ6v6YFew/RqtBGO1WpCYnsOItDqVY4FBo2ZmaYPSWCO4l7tqCHQbAJJBY",
+	"ZnD2z3t7z0li0FGXYX5dSxoQASlsZCEBSaDqpOtdb2RVJyiNDJCvGqcaSOHpU50n4kaGR38jBV7o4v5L",
+	"SCYc3xmsIM2MVVOE4/QHmiqY2xNNBGiQ7BWQPTLUoGHGvoBEJZYbkJqebvs1rKfr7+kwK2KrifAmVlQF",
+	"VB/d23ZRHoIE9LufimuVoKy5F9wmRvP1iaFhwCH+d9uc7tfIiIe2TXt71SwisF9DQcN5krfrGl6Vu3u+",
+	"3Jdxqi5rloLMU4acUQdQWIbad0VuLDN7gRWnK89KjPJKVJmbfzk75ZTGmD3xan2c0ZXKrO2U1nbulJn9",
+	"rTO1zOyC8+MTZ6okEcNC3uz9GGsI6i2ojQATpbO1pfAA/u5xXw+6vYXTFHdNq88cws3e7qxNnBwGWEdf",
+	"pkDyQlu4P1UHkeKhbuudnoxFiIbqb/UF0PzPxtVByyDW236tjTJ4JGHMTXcted2Q9sVkIIDgG3UsvcLs",
+	"cWdy2tmYEc3pPO817Iei73jMiiVGV/byuYJMourQBdFeAhGcO49SDY7qFaMO19vhlDro48jzLFGzaN9u",
+	"5fl4DqrGng5thOO3rDZzC7811YVj7jmkK5zWdvOWP1urZXxfMQC181BPh1TILBxss9ZlVb2tJ5uczF+L",
+	"iGnCnLlLRJquc0Nj0iTQIAciYx/h503icd/MZavgrHouQPchd64mwbnudhcz/vDZtrrA6rANr7Tgol4O",
+	"QlLUUAegdoCgVlAOGsQyXjsGvIEidThhtjQ3CY2MVtefQKAGHOl7CVu8YxGZoWZ51H+QEDnm/hH9IFEf",
+	"Ubey/cjwjVj3ceshT7zvG/PE+60GrVHE3QQJFyNAEvzp/z9MXkic6Pvw4kXlb+9eSES7+n6XvJCI/r7v",
+	"4kXlnaDVeVFxUljEkMpTOAl68V+GsPSVrg4gw1TJkIRTUi+SMzrWcHpIOm2Z0h9Qv1ApuPihytwG8awo",
+	"14lYIpbgQHEO6TCngiToErciHGdGRFIc5tR4v2VGzdr2KY1IiPQwUmbFKWY/d+gcoytbzwo795Z4u+UV",
+	"9g13e83sB6w4w+ynrLjEKzxdktJ8D/NnVZGY/W1lYkyoAbPMvsKVDL4SLIv99AyjS9u//IPZl3c21hnd",
+	"5HJV8dfGbTvPAFFmeGKCeg8kDP8McUXodG1jxS2sqlKm6LHRYE4TakwKaibiVIMkEDpJrTVKghpOnp4i",
+	"C9qr2n08MVw5SRD4biIBhPijk+r6CnM5TZUF9Pgl013FvBneyH41n480uaw3g6SqqiBloCmZliwjpCAl",
+	"xt8+uU+Q7UpYdWEtBNFpqEhV4UaKSo34voGmpLpiYRXdyUNHdxYTSYiOfmxIkfotU+Lp4QayAKtjIqX4",
+	"wzG3qKagpZFDh3xKlywdDeaQzGEi/rhUC8WYqNgEpk2h21qmVJUc+G1/1seFXW0mvy9hWxWC3RJWKBpm",
+	"WNq6n4DDTycx8evn1NsTBlKV6t2DIT6sKvldI2K3cNi5/3j7yUNGlxldYPTvjN47eFw03v4YEahqIWVd",
+	"lHG+mHlVXNRvr1Phu4rDref7jrv91+7Dr44ckSkOWCQZ6qL8qboiEV+hNJCJLUNGxzMb4qRRLgvPi4Z0",
+	"cEZHnJGyQ+e2b85WxgtNOpVIhzKz6RvLEdNT8w4lTSLDoU1UTVNqy6cBBTEfaaa0UVvgzWCYBOHn1nZW",
+	"N3Ye3RFVyG0zrzB6n9FRflGwt+fo9s3FyqzN6OpLes25tsZfn1ncWrvFT7L46dpVcbHZMI7PNWFmV4Wz",
+	"z1WTYEOVoebjoFnTCJq5tbbojF/lp2yTM4xedyan3RM+6ZNemGYFmy+0Y+7B7Lyrl/ATta7ESYn3LWew",
+	"oqZUJDpscVgZxJtBUEGGB7g7FT2LdRQ9A4mcCQNb35gccj30wjgkuWvbB05D3Wc+/Tcsl1zbBfiPoJxB",
+	"0Y+wTgyshSXwz6z4kzjLfMqKi3zwuXHn8nMehdP8LHxr7XJlZtE3KV3dnv+Z0UlWoIz+wOwJ7suF6Yao",
+	"Wn158yl/gB+9X24Etnv+BWUEHgghR/sejJVarLDiWPW0kp/MTrlnsrXtWLnpRFU84ztFdiW7yqMXHOlB",
+	"8XLEXYmTQcC+cHU77XpdFWsdX1vkDNeJFMlUdRmJRSatDiBdGBjrOPSoHHoUm8aDZPwR9UgHhHqMG6V4",
+	"zlB1ctB26dq/thdebN8o+yi8+tApPd2+UZY+7z3zhcTo0pvpns5xoBzy4XdRfk5OSJWZRac02hw6Yy+c",
+	"y7fdhqYaWwU7ZeBsnGDJ/Z6LaGmWGV2txeGS6GS873GJHdn3vI+yfV1QrQQutexw/huN3fR3Lcyst2Iv",
+	"p684S9xqVrCbrG1Iudb9GafqaNBv/2JvvRitzCy+Wi91vfeeJLy36IyO8JOyXfES3Gmi92qi925MCRok",
+	"8QzJav7qGSK9NyletSrTSEq14gQ77CNaGQ8A8uRvH+TxWA3bVM9eTzELrGTHVCYLc1eQw0pxxPnx0W+q",
+	"Idw3yLf9IKFdmayBl9b98c7CWOXmw621f+9s3nCuPjlQVnR0sY4u9nbrYm1IYs0aRvFBVQ0IM7wjn3T0",
+	"sI5D32Y9bB/pfqRi2P5wHq/2Zy/xy2uCll2py12WnV/vOuuT/Fu1szan6c4EK65xQcj+1inNMLq89eye",
+	"+IXa/M6dcsgX4F9DIAu0Th1VrKOKdVSxjirWUcU6qth+Vkbx+wT+ZSF3ifCPmTPEj8EtQ+PbL0JyZjIe",
+	"z+A04v/F0CDM5jQUk3EWBFNWwzLUogoa8A2QjMfFBxlskuT7iUQC5PvqyIZreeQhzEcCN2tiXr4v/58B",
+	"AMB4/ntrPwAA",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
@@ -0,0 +1,230 @@
+// Package schedule は時刻表の日付判定（曜日種別・祝日・セグメント条件・有効期間）を提供します。
+// API の domain と timetable-gen の両方から使うため、他のパッケージに依存しません。
+package schedule
+
+import (
+	"time"
+
+	"github.com/rickar/cal/v2"
+	"github.com/rickar/cal/v2/jp"
+)
+
+// DayType 曜日の種類を定義
+type DayType string
+
+const (
+	DayTypeWeekday   DayType = "weekday"
+	DayTypeSaturday  DayType = "saturday"
+	DayTypeSunday    DayType = "sunday"
+	DayTypeWeekend   DayType = "weekend"
+	DayTypeHoliday   DayType = "holiday"
+	DayTypeMonday    DayType = "monday"
+	DayTypeTuesday   DayType = "tuesday"
+	DayTypeWednesday DayType = "wednesday"
+	DayTypeThursday  DayType = "thursday"
+	DayTypeFriday    DayType = "friday"
+)
+
+// ConditionType セグメント条件の種類を定義
+type ConditionType string
+
+const (
+	ConditionTypeDayType        ConditionType = "dayType"
+	ConditionTypeSpecificDate   ConditionType = "specificDate"
+	ConditionTypeSpecificPeriod ConditionType = "specificPeriod"
+)
+
+// Condition はセグメントの適用条件を表します
+type Condition struct {
+	Type  ConditionType `json:"type"`
+	Value string        `json:"value,omitempty"`
+	From  string        `json:"from,omitempty"`
+	To    string        `json:"to,omitempty"`
+}
+
+// Period はサービスの有効期間を表します。From/To は YYYY-MM-DD で、空の場合は無期限です
+type Period struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// 日本の祝日カレンダー
+var japaneseCalendar *cal.Calendar
+
+func init() {
+	japaneseCalendar = &cal.Calendar{}
+
+	// 日本の祝日を追加
+	japaneseCalendar.AddHoliday(
+		jp.NewYear,
+		jp.ComingOfAgeDay,
+		jp.NationalFoundationDay,
+		jp.TheEmperorsBirthday,
+		jp.VernalEquinoxDay,
+		jp.ShowaDay,
+		jp.ConstitutionMemorialDay,
+		jp.GreeneryDay,
+		jp.ChildrensDay,
+		jp.MarineDay,
+		jp.MountainDay,
+		jp.RespectForTheAgedDay,
+		jp.AutumnalEquinoxDay,
+		jp.SportsDay,
+		jp.CultureDay,
+		jp.LaborThanksgivingDay,
+	)
+}
+
+// IsHoliday は指定された日付が日本の祝日（振替休日を含む）かどうかを判定します
+func IsHoliday(date time.Time) bool {
+	actual, observed, _ := japaneseCalendar.IsHoliday(date)
+	return actual || observed
+}
+
+// Holiday は指定された日付の祝日を返します。祝日でなければ nil を返します
+func Holiday(date time.Time) *cal.Holiday {
+	if actual, observed, h := japaneseCalendar.IsHoliday(date); (actual || observed) && h != nil {
+		return h
+	}
+	return nil
+}
+
+// holidayNames は祝日の日本語名です（cal/jp の名前は英語のため）
+var holidayNames = map[string]string{
+	"New Year's Day":            "元日",
+	"Coming of Age Day":         "成人の日",
+	"National Foundation Day":   "建国記念の日",
+	"The Emperor's Birthday":    "天皇誕生日",
+	"Vernal Equinox Day":        "春分の日",
+	"Showa Day":                 "昭和の日",
+	"Constitution Memorial Day": "憲法記念日",
+	"Greenery Day":              "みどりの日",
+	"Children's Day":            "こどもの日",
+	"Marine Day":                "海の日",
+	"Mountain Day":              "山の日",
+	"Respect for the Aged Day":  "敬老の日",
+	"Autumnal Equinox Day":      "秋分の日",
+	"Sports Day":                "スポーツの日",
+	"Culture Day":               "文化の日",
+	"Labor Thanksgiving Day":    "勤労感謝の日",
+}
+
+// HolidayName は指定された日付の祝日名を日本語で返します。振替休日は "振替休日"、祝日でなければ空文字を返します
+func HolidayName(date time.Time) string {
+	actual, observed, h := japaneseCalendar.IsHoliday(date)
+	switch {
+	case !actual && observed:
+		return "振替休日"
+	case !actual || h == nil:
+		return ""
+	}
+	if name, ok := holidayNames[h.Name]; ok {
+		return name
+	}
+	return h.Name
+}
+
+// IsWeekend は指定された曜日が週末（土日）かどうかを判定します
+func IsWeekend(dayType DayType) bool {
+	return dayType == DayTypeSaturday || dayType == DayTypeSunday
+}
+
+// IsWeekday は指定された曜日が平日（月〜金）かどうかを判定します
+func IsWeekday(dayType DayType) bool {
+	return dayType == DayTypeMonday || dayType == DayTypeTuesday ||
+		dayType == DayTypeWednesday || dayType == DayTypeThursday || dayType == DayTypeFriday
+}
+
+// DayTypeOf は指定された日付の曜日タイプを返します。祝日は曜日より優先されます
+func DayTypeOf(date time.Time) DayType {
+	if IsHoliday(date) {
+		return DayTypeHoliday
+	}
+
+	switch date.Weekday() {
+	case time.Monday:
+		return DayTypeMonday
+	case time.Tuesday:
+		return DayTypeTuesday
+	case time.Wednesday:
+		return DayTypeWednesday
+	case time.Thursday:
+		return DayTypeThursday
+	case time.Friday:
+		return DayTypeFriday
+	case time.Saturday:
+		return DayTypeSaturday
+	case time.Sunday:
+		return DayTypeSunday
+	default:
+		return DayTypeWeekday // 念のため
+	}
+}
+
+// matchesDayType は value（"weekday"/"weekend"/"holiday" または曜日名）が dayType に一致するかを判定します
+func matchesDayType(value string, dayType DayType) bool {
+	switch DayType(value) {
+	case DayTypeWeekday:
+		// "weekday" は月〜金のどれかに一致するか（祝日除く）
+		return IsWeekday(dayType) && dayType != DayTypeHoliday
+	case DayTypeWeekend:
+		return IsWeekend(dayType)
+	case DayTypeHoliday:
+		return dayType == DayTypeHoliday
+	}
+	return value == string(dayType)
+}
+
+// Applies は指定された日付にこの条件のセグメントが有効かどうかを判断します
+func (c Condition) Applies(date time.Time) bool {
+	dateStr := date.Format("2006-01-02")
+
+	switch c.Type {
+	case ConditionTypeDayType:
+		return matchesDayType(c.Value, DayTypeOf(date))
+
+	case ConditionTypeSpecificDate:
+		return dateStr == c.Value
+
+	case ConditionTypeSpecificPeriod:
+		if c.From != "" && c.To != "" {
+			return dateStr >= c.From && dateStr <= c.To
+		} else if c.From != "" {
+			return dateStr >= c.From
+		} else if c.To != "" {
+			return dateStr <= c.To
+		}
+	}
+
+	// 後方互換性のため
+	if c.Type == "" && c.Value != "" {
+		return matchesDayType(c.Value, DayTypeOf(date))
+	}
+
+	return true
+}
+
+// PeriodsInclude は有効期間のいずれかが指定された日付を含むかを判定します。期間が空なら常に true です
+func PeriodsInclude(periods []Period, date time.Time) bool {
+	if len(periods) == 0 {
+		return true
+	}
+
+	dateStr := date.Format("2006-01-02")
+	for _, period := range periods {
+		if period.From != "" && period.To != "" {
+			if dateStr >= period.From && dateStr <= period.To {
+				return true
+			}
+		} else if period.From != "" {
+			if dateStr >= period.From {
+				return true
+			}
+		} else if period.To != "" {
+			if dateStr <= period.To {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// archiveDirName is the subdirectory of the services directory that holds expired services.
+// The API only reads it for historical queries.
+const archiveDirName = "archived"
+
+// archivePolicy decides which expired services are moved to archived/.
+type archivePolicy struct {
+	// GraceDays keeps a service for this many days after its last validity date.
+	GraceDays int
+	// KeepLatest never archives the newest service of each regular schedule series
+	// (same route, direction and dayType), so a route keeps a timetable until the next PDF.
+	KeepLatest bool
+	// Exclude lists service IDs that are never archived.
+	Exclude map[string]bool
+}
+
+// archiveCandidate is one service file selected by planArchive.
+type archiveCandidate struct {
+	File      string
+	ID        string
+	ExpiredOn string // latest validityPeriods[].to
+}
+
+// seriesPattern matches IDs of regular dayType schedules, e.g. "hachioji-to-school-weekday-20260407".
+// Special-date services ("...-2026-11-03") are not part of a series.
+var seriesPattern = regexp.MustCompile(`^(.+)-(\d{8})$`)
+
+// scheduleSeries returns the ID without its validity start date and the start date itself.
+func scheduleSeries(id string) (series, start string, ok bool) {
+	m := seriesPattern.FindStringSubmatch(id)
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// jstToday returns today's date in Japan, where the timetables apply.
+func jstToday() time.Time {
+	jst, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		jst = time.FixedZone("JST", 9*60*60)
+	}
+	now := time.Now().In(jst)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// planArchive lists the services in servicesDir that policy would archive on today.
+// Files that cannot be read or have no validity period are left alone.
+func planArchive(servicesDir string, today time.Time, policy archivePolicy) ([]archiveCandidate, error) {
+	entries, err := os.ReadDir(servicesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var expired []archiveCandidate
+	latest := map[string]string{} // series → ID of the newest service in servicesDir
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		id := strings.TrimSuffix(e.Name(), ".json")
+		if series, start, ok := scheduleSeries(id); ok {
+			if cur, seen := latest[series]; !seen || start > cur[len(cur)-8:] {
+				latest[series] = id
+			}
+		}
+
+		data, err := os.ReadFile(filepath.Join(servicesDir, e.Name()))
+		if err != nil {
+			continue
+		}
+		var svc struct {
+			ValidityPeriods []struct {
+				To string `json:"to"`
+			} `json:"validityPeriods"`
+		}
+		if err := json.Unmarshal(data, &svc); err != nil || len(svc.ValidityPeriods) == 0 {
+			continue
+		}
+
+		// 全期間の最大 to を求める
+		var maxTo time.Time
+		for _, vp := range svc.ValidityPeriods {
+			t, err := time.Parse("2006-01-02", vp.To)
+			if err != nil {
+				continue
+			}
+			if t.After(maxTo) {
+				maxTo = t
+			}
+		}
+		if maxTo.IsZero() || !maxTo.AddDate(0, 0, policy.GraceDays).Before(today) {
+			continue
+		}
+		expired = append(expired, archiveCandidate{File: e.Name(), ID: id, ExpiredOn: maxTo.Format("2006-01-02")})
+	}
+
+	var plan []archiveCandidate
+	for _, c := range expired {
+		if policy.Exclude[c.ID] {
+			continue
+		}
+		if series, _, ok := scheduleSeries(c.ID); ok && policy.KeepLatest && latest[series] == c.ID {
+			continue
+		}
+		plan = append(plan, c)
+	}
+	return plan, nil
+}
+
+// archiveServices moves the planned files into archived/. With dryRun it only prints what
+// would be moved. Each file is recorded in run. It returns the number of files (to be) moved.
+func archiveServices(servicesDir string, plan []archiveCandidate, dryRun bool, run *RunReport) int {
+	archiveDir := filepath.Join(servicesDir, archiveDirName)
+	count := 0
+	for _, c := range plan {
+		if dryRun {
+			fmt.Printf("  アーカイブ予定: %s (期限: %s)\n", c.File, c.ExpiredOn)
+			run.addArchived(ArchiveRecord{File: c.File, ExpiredOn: c.ExpiredOn})
+			count++
+			continue
+		}
+		if err := os.MkdirAll(archiveDir, 0755); err != nil {
+			log.Printf("archived/ 作成失敗: %v", err)
+			continue
+		}
+		if err := os.Rename(filepath.Join(servicesDir, c.File), filepath.Join(archiveDir, c.File)); err != nil {
+			log.Printf("アーカイブ失敗 %s: %v", c.File, err)
+			continue
+		}
+		fmt.Printf("  アーカイブ: %s (期限: %s)\n", c.File, c.ExpiredOn)
+		run.addArchived(ArchiveRecord{File: c.File, ExpiredOn: c.ExpiredOn})
+		count++
+	}
+	return count
+}
+
+// archiveExpired moves services whose validity has ended into archived/ using policy.
+// With dryRun it only prints what would be moved. Each file is recorded in run.
+func archiveExpired(servicesDir string, policy archivePolicy, dryRun bool, run *RunReport) int {
+	plan, err := planArchive(servicesDir, jstToday(), policy)
+	if err != nil {
+		log.Printf("ディレクトリ読み込み失敗: %v", err)
+		return 0
+	}
+	return archiveServices(servicesDir, plan, dryRun, run)
+}
+
+// unarchiveServices moves the given service IDs from archived/ back into servicesDir.
+// A service that already exists in servicesDir is only overwritten with force.
+func unarchiveServices(servicesDir string, ids []string, force, dryRun bool) (int, error) {
+	archiveDir := filepath.Join(servicesDir, archiveDirName)
+	count := 0
+	for _, id := range ids {
+		name := strings.TrimSuffix(id, ".json") + ".json"
+		src := filepath.Join(archiveDir, name)
+		dst := filepath.Join(servicesDir, name)
+		if _, err := os.Stat(src); err != nil {
+			return count, fmt.Errorf("%s は archived/ にありません", name)
+		}
+		if _, err := os.Stat(dst); err == nil && !force {
+			return count, fmt.Errorf("%s は既に存在します (上書きするには --force)", name)
+		}
+		if dryRun {
+			fmt.Printf("  復元予定: %s\n", name)
+			count++
+			continue
+		}
+		if err := os.Rename(src, dst); err != nil {
+			return count, fmt.Errorf("復元失敗 %s: %w", name, err)
+		}
+		fmt.Printf("  復元: %s\n", name)
+		count++
+	}
+	return count, nil
+}
+
+// parseArchivePolicyArg applies an archive policy flag at args[i] to policy. It reports
+// whether args[i] was a policy flag.
+func parseArchivePolicyArg(args []string, i int, policy *archivePolicy) bool {
+	switch args[i] {
+	case "--grace-days":
+		if i+1 < len(args) {
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 0 {
+				log.Fatalf("--grace-days には 0 以上の整数を指定してください: %q", args[i+1])
+			}
+			policy.GraceDays = n
+		}
+	case "--keep-latest":
+		policy.KeepLatest = true
+	case "--exclude":
+		if i+1 < len(args) {
+			if policy.Exclude == nil {
+				policy.Exclude = map[string]bool{}
+			}
+			for _, id := range strings.Split(args[i+1], ",") {
+				if id = strings.TrimSuffix(strings.TrimSpace(id), ".json"); id != "" {
+					policy.Exclude[id] = true
+				}
+			}
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// runArchive implements `timetable-gen archive`: archive expired services with a policy.
+func runArchive(args []string) {
+	servicesDir := "../../data/services"
+	runReportPath := ""
+	dryRun := false
+	var policy archivePolicy
+
+	for i, a := range args {
+		if parseArchivePolicyArg(args, i, &policy) {
+			continue
+		}
+		switch a {
+		case "--dir":
+			if i+1 < len(args) {
+				servicesDir = args[i+1]
+			}
+		case "--report":
+			if i+1 < len(args) {
+				runReportPath = args[i+1]
+			}
+		case "--dry-run":
+			dryRun = true
+		}
+	}
+
+	run := newRunReport("archive", runReportPath, dryRun)
+	plan, err := planArchive(servicesDir, jstToday(), policy)
+	if err != nil {
+		run.fatalf("ディレクトリ読み込み失敗: %v", err)
+	}
+	if len(plan) == 0 {
+		fmt.Println("アーカイブ対象はありません。")
+		run.finish(exitNoChange)
+		return
+	}
+	n := archiveServices(servicesDir, plan, dryRun, run)
+	if dryRun {
+		fmt.Printf("\nアーカイブ予定: %d 件 (dry-run)\n", n)
+		run.exit(diffExitCode(0, n))
+	}
+	fmt.Printf("\nアーカイブ: %d 件\n", n)
+	if n < len(plan) {
+		run.exit(exitError)
+	}
+	run.finish(exitNoChange)
+}
+
+// runUnarchive implements `timetable-gen unarchive <id>...`.
+// Without IDs it lists the archived services.
+func runUnarchive(args []string) {
+	servicesDir := "../../data/services"
+	dryRun, force := false, false
+
+	var ids []string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--dir":
+			if i+1 < len(args) {
+				servicesDir = args[i+1]
+				i++
+			}
+		case "--dry-run":
+			dryRun = true
+		case "--force":
+			force = true
+		default:
+			ids = append(ids, args[i])
+		}
+	}
+
+	if len(ids) == 0 {
+		entries, err := os.ReadDir(filepath.Join(servicesDir, archiveDirName))
+		if err != nil && !os.IsNotExist(err) {
+			log.Fatalf("archived/ の読み込み失敗: %v", err)
+		}
+		var names []string
+		for _, e := range entries {
+			if !e.IsDir() && filepath.Ext(e.Name()) == ".json" {
+				names = append(names, strings.TrimSuffix(e.Name(), ".json"))
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Println(name)
+		}
+		fmt.Printf("\nアーカイブ済み: %d 件 (復元: timetable-gen unarchive <id>...)\n", len(names))
+		return
+	}
+
+	n, err := unarchiveServices(servicesDir, ids, force, dryRun)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if dryRun {
+		fmt.Printf("\n復元予定: %d 件 (dry-run)\n", n)
+		return
+	}
+	fmt.Printf("\n復元: %d 件\n", n)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// archiveFixture writes services with the given ID → last validity date into a temp dir.
+func archiveFixture(t *testing.T, services map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for id, to := range services {
+		svc := validService()
+		svc.ID = id
+		svc.ValidityPeriods = []ValidityPeriod{{From: "2026-04-07", To: to}}
+		writeJSON(t, filepath.Join(dir, id+".json"), svc)
+	}
+	return dir
+}
+
+func planIDs(t *testing.T, dir string, today string, policy archivePolicy) map[string]bool {
+	t.Helper()
+	d, err := time.Parse("2006-01-02", today)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := planArchive(dir, d, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := map[string]bool{}
+	for _, c := range plan {
+		ids[c.ID] = true
+	}
+	return ids
+}
+
+func TestPlanArchive_Policies(t *testing.T) {
+	dir := archiveFixture(t, map[string]string{
+		"hachioji-to-school-weekday-20260407":  "2026-07-29",
+		"hachioji-to-school-weekday-20260727":  "2026-08-30",
+		"hachioji-to-school-saturday-20260407": "2026-07-29",
+		"hachioji-to-school-2026-05-23":        "2026-05-23",
+		"minamino-to-school-weekday-20260901":  "2026-12-31",
+	})
+
+	tests := []struct {
+		name   string
+		today  string
+		policy archivePolicy
+		want   []string
+	}{
+		{"default", "2026-09-01", archivePolicy{}, []string{
+			"hachioji-to-school-weekday-20260407", "hachioji-to-school-weekday-20260727",
+			"hachioji-to-school-saturday-20260407", "hachioji-to-school-2026-05-23",
+		}},
+		{"grace days", "2026-08-06", archivePolicy{GraceDays: 7}, []string{
+			"hachioji-to-school-weekday-20260407", "hachioji-to-school-saturday-20260407",
+			"hachioji-to-school-2026-05-23",
+		}},
+		{"keep latest", "2026-09-01", archivePolicy{KeepLatest: true}, []string{
+			"hachioji-to-school-weekday-20260407", "hachioji-to-school-2026-05-23",
+		}},
+		{"exclude", "2026-09-01", archivePolicy{Exclude: map[string]bool{"hachioji-to-school-2026-05-23": true}}, []string{
+			"hachioji-to-school-weekday-20260407", "hachioji-to-school-weekday-20260727",
+			"hachioji-to-school-saturday-20260407",
+		}},
+	}
+	for _, tt := range tests {
+		got := planIDs(t, dir, tt.today, tt.policy)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: planned %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for _, id := range tt.want {
+			if !got[id] {
+				t.Errorf("%s: %s not planned (got %v)", tt.name, id, got)
+			}
+		}
+	}
+}
+
+func TestArchiveAndUnarchive(t *testing.T) {
+	dir := archiveFixture(t, map[string]string{"hachioji-to-school-weekday-20200407": "2020-07-29"})
+	name := "hachioji-to-school-weekday-20200407.json"
+
+	if n := archiveExpired(dir, archivePolicy{}, false, nil); n != 1 {
+		t.Fatalf("archiveExpired = %d, want 1", n)
+	}
+	if _, err := os.Stat(filepath.Join(dir, archiveDirName, name)); err != nil {
+		t.Fatalf("file should be in archived/: %v", err)
+	}
+
+	if n, err := unarchiveServices(dir, []string{"hachioji-to-school-weekday-20200407"}, false, true); err != nil || n != 1 {
+		t.Fatalf("unarchive dry-run = (%d, %v)", n, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+		t.Error("dry-run must not restore the file")
+	}
+
+	if _, err := unarchiveServices(dir, []string{name}, false, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+		t.Errorf("file should be restored: %v", err)
+	}
+	if _, err := unarchiveServices(dir, []string{name}, false, false); err == nil {
+		t.Error("restoring a service that is not archived should fail")
+	}
+
+	// 同名ファイルがある場合は --force なしでは上書きしない
+	archiveExpired(dir, archivePolicy{}, false, nil)
+	writeJSON(t, filepath.Join(dir, name), validService())
+	if _, err := unarchiveServices(dir, []string{name}, false, false); err == nil {
+		t.Error("expected error when the service already exists")
+	}
+	if _, err := unarchiveServices(dir, []string{name}, true, false); err != nil {
+		t.Errorf("--force should overwrite: %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// defaultCacheDir is where extraction results are cached unless --cache-dir is given.
+const defaultCacheDir = "downloaded/.extract-cache"
+
+// identifiedExtractor is implemented by extractors whose output depends on a prompt and model.
+// Only such extractors are worth caching; replay backends like fixture are already deterministic.
+type identifiedExtractor interface {
+	Extractor
+	// Identity returns the model name and a hash of the prompt used for extraction.
+	Identity() (model, promptHash string)
+}
+
+// ExtractionCacheEntry is the on-disk form of one cached extraction.
+type ExtractionCacheEntry struct {
+	PDFSHA256   string         `json:"pdfSha256"`
+	PromptHash  string         `json:"promptHash"`
+	Model       string         `json:"model"`
+	ExtractedAt string         `json:"extractedAt"`
+	Data        *ExtractedData `json:"data"`
+}
+
+// CachedExtractor wraps an extractor and stores its results keyed by PDF hash, model and prompt hash,
+// so that re-running the pipeline on the same PDF is free and reproducible.
+type CachedExtractor struct {
+	inner identifiedExtractor
+	dir   string
+	force bool
+	// readOnly skips saving new entries, for --dry-run.
+	readOnly bool
+}
+
+func NewCachedExtractor(inner identifiedExtractor, dir string, force bool) *CachedExtractor {
+	return &CachedExtractor{inner: inner, dir: dir, force: force}
+}
+
+func (c *CachedExtractor) Extract(ctx context.Context, pdfData []byte) (*ExtractedData, error) {
+	model, promptHash := c.inner.Identity()
+	pdfHash := sha256sum(pdfData)
+	path := c.entryPath(pdfHash, model, promptHash)
+
+	if !c.force {
+		entry, err := loadCacheEntry(path)
+		if err == nil {
+			log.Printf("抽出キャッシュ使用: %s (model: %s, 抽出日時: %s)", filepath.Base(path), entry.Model, entry.ExtractedAt)
+			return entry.Data, nil
+		}
+		if !os.IsNotExist(err) {
+			log.Printf("抽出キャッシュ読み込み失敗 (再抽出します): %v", err)
+		}
+	}
+
+	data, err := c.inner.Extract(ctx, pdfData)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.readOnly {
+		return data, nil
+	}
+	entry := ExtractionCacheEntry{
+		PDFSHA256:   pdfHash,
+		PromptHash:  promptHash,
+		Model:       model,
+		ExtractedAt: time.Now().Format(time.RFC3339),
+		Data:        data,
+	}
+	if err := saveCacheEntry(path, entry); err != nil {
+		log.Printf("抽出キャッシュ保存失敗: %v", err)
+	}
+	return data, nil
+}
+
+// entryPath keys the cache by model as well as prompt, so switching --model never
+// replays another model's extraction.
+func (c *CachedExtractor) entryPath(pdfHash, model, promptHash string) string {
+	return filepath.Join(c.dir, pdfHash, cacheKeyPart(model), promptHash+".json")
+}
+
+// cacheKeyPart makes a model name safe to use as a single path element
+// (model names such as "models/gemini-2.5-flash" may contain slashes).
+func cacheKeyPart(s string) string {
+	b := []byte(s)
+	for i, ch := range b {
+		if !(ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' || ch == '-' || ch == '_' || ch == '.') {
+			b[i] = '_'
+		}
+	}
+	if len(b) == 0 || string(b) == "." || string(b) == ".." {
+		return "_"
+	}
+	return string(b)
+}
+
+func loadCacheEntry(path string) (*ExtractionCacheEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entry ExtractionCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if entry.Data == nil || len(entry.Data.Tables) == 0 {
+		return nil, fmt.Errorf("%s: テーブルがありません", path)
+	}
+	return &entry, nil
+}
+
+func saveCacheEntry(path string, entry ExtractionCacheEntry) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := marshalJSON(entry)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
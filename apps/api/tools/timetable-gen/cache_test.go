@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+// countingExtractor is a fake identifiedExtractor that records how often it was called.
+type countingExtractor struct {
+	calls      int
+	model      string
+	promptHash string
+}
+
+func (c *countingExtractor) Extract(context.Context, []byte) (*ExtractedData, error) {
+	c.calls++
+	return sampleExtracted(), nil
+}
+
+func (c *countingExtractor) Identity() (string, string) {
+	if c.model == "" {
+		return "fake-model", c.promptHash
+	}
+	return c.model, c.promptHash
+}
+
+func TestCachedExtractor_ReusesResult(t *testing.T) {
+	dir := t.TempDir()
+	inner := &countingExtractor{promptHash: "p1"}
+	ext := NewCachedExtractor(inner, dir, false)
+	pdf := []byte("%PDF-fake")
+
+	for i := 0; i < 2; i++ {
+		got, err := ext.Extract(context.Background(), pdf)
+		if err != nil {
+			t.Fatalf("Extract error: %v", err)
+		}
+		if len(got.Tables) != 1 {
+			t.Fatalf("expected 1 table, got %d", len(got.Tables))
+		}
+	}
+	if inner.calls != 1 {
+		t.Errorf("inner extractor called %d times, want 1", inner.calls)
+	}
+
+	entry, err := loadCacheEntry(ext.entryPath(sha256sum(pdf), "fake-model", "p1"))
+	if err != nil {
+		t.Fatalf("cache entry not written: %v", err)
+	}
+	if entry.Model != "fake-model" || entry.PDFSHA256 != sha256sum(pdf) {
+		t.Errorf("unexpected cache entry: %+v", entry)
+	}
+}
+
+func TestCachedExtractor_PromptChangeMisses(t *testing.T) {
+	dir := t.TempDir()
+	pdf := []byte("%PDF-fake")
+
+	first := &countingExtractor{promptHash: "p1"}
+	if _, err := NewCachedExtractor(first, dir, false).Extract(context.Background(), pdf); err != nil {
+		t.Fatal(err)
+	}
+	second := &countingExtractor{promptHash: "p2"}
+	if _, err := NewCachedExtractor(second, dir, false).Extract(context.Background(), pdf); err != nil {
+		t.Fatal(err)
+	}
+	if second.calls != 1 {
+		t.Errorf("changed prompt hash should miss the cache, inner called %d times", second.calls)
+	}
+}
+
+func TestCachedExtractor_Force(t *testing.T) {
+	dir := t.TempDir()
+	pdf := []byte("%PDF-fake")
+	inner := &countingExtractor{promptHash: "p1"}
+
+	if _, err := NewCachedExtractor(inner, dir, false).Extract(context.Background(), pdf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewCachedExtractor(inner, dir, true).Extract(context.Background(), pdf); err != nil {
+		t.Fatal(err)
+	}
+	if inner.calls != 2 {
+		t.Errorf("force should re-extract, inner called %d times", inner.calls)
+	}
+}
+
+func TestCachedExtractor_ModelChangeMisses(t *testing.T) {
+	dir := t.TempDir()
+	pdf := []byte("%PDF-fake")
+
+	first := &countingExtractor{model: "gemini-2.5-flash", promptHash: "p1"}
+	if _, err := NewCachedExtractor(first, dir, false).Extract(context.Background(), pdf); err != nil {
+		t.Fatal(err)
+	}
+	second := &countingExtractor{model: "models/gemini-2.5-pro", promptHash: "p1"}
+	if _, err := NewCachedExtractor(second, dir, false).Extract(context.Background(), pdf); err != nil {
+		t.Fatal(err)
+	}
+	if second.calls != 1 {
+		t.Errorf("changed model should miss the cache, inner called %d times", second.calls)
+	}
+	again := &countingExtractor{model: "gemini-2.5-flash", promptHash: "p1"}
+	if _, err := NewCachedExtractor(again, dir, false).Extract(context.Background(), pdf); err != nil {
+		t.Fatal(err)
+	}
+	if again.calls != 0 {
+		t.Errorf("same model and prompt should hit the cache, inner called %d times", again.calls)
+	}
+}
+
+func TestCacheKeyPart(t *testing.T) {
+	tests := map[string]string{
+		"gemini-2.5-flash":      "gemini-2.5-flash",
+		"models/gemini-2.5-pro": "models_gemini-2.5-pro",
+		"..":                    "_",
+		"":                      "_",
+	}
+	for in, want := range tests {
+		if got := cacheKeyPart(in); got != want {
+			t.Errorf("cacheKeyPart(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCachedExtractor_ReadOnly(t *testing.T) {
+	dir := t.TempDir()
+	pdf := []byte("%PDF-fake")
+	inner := &countingExtractor{promptHash: "p1"}
+
+	ext := withCache(inner, extractorOptions{CacheDir: dir, CacheReadOnly: true})
+	for i := 0; i < 2; i++ {
+		if _, err := ext.Extract(context.Background(), pdf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if inner.calls != 2 {
+		t.Errorf("read-only cache should not store results, inner called %d times", inner.calls)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("read-only cache wrote %d entries", len(entries))
+	}
+
+	// Entries written by a normal run are still used in dry-run.
+	if _, err := NewCachedExtractor(inner, dir, false).Extract(context.Background(), pdf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ext.Extract(context.Background(), pdf); err != nil {
+		t.Fatal(err)
+	}
+	if inner.calls != 3 {
+		t.Errorf("read-only cache should reuse existing entries, inner called %d times", inner.calls)
+	}
+}
@@ -1,73 +1,250 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"unicode/utf8"
 )
 
+// defaultRoutesPath is the station route config, relative to the tool directory like the
+// default --output. bus_stops.json is read from the same directory to validate it.
+const defaultRoutesPath = "../../data/station_routes.json"
+
+// routeConfigVersion is the only station_routes.json version this build understands.
+const routeConfigVersion = 1
+
+// tableColumns is the number of time columns in every PDF table (the shuttle note, when present,
+// is stored after them at shuttleNoteCol). Column indices in the route config must be below it.
+const tableColumns = 3
+
+// ColumnMapping says which PDF table columns hold departure and arrival times for one direction.
+type ColumnMapping struct {
+	Departure int `json:"departure"`
+	Arrival   int `json:"arrival"`
+}
+
 // StationRoute holds the stop IDs and display names for one station's route.
 type StationRoute struct {
-	Key           string // kebab-case key used in service IDs
-	StationStopID int
-	StationName   string
-	SchoolStopID  int
-	SchoolName    string
+	Key           string   `json:"key"` // kebab-case key used in service IDs
+	StationStopID int      `json:"stationStopId"`
+	StationName   string   `json:"stationName"`
+	SchoolStopID  int      `json:"schoolStopId"`
+	SchoolName    string   `json:"schoolName"`
+	Aliases       []string `json:"aliases"` // alternate names the extractor might return
+	Columns       struct {
+		Outbound ColumnMapping `json:"outbound"` // 大学→駅
+		Inbound  ColumnMapping `json:"inbound"`  // 駅→大学
+	} `json:"columns"`
+}
+
+// RouteConfig is the contents of station_routes.json.
+type RouteConfig struct {
+	Version int            `json:"version"`
+	Routes  []StationRoute `json:"routes"`
 }
 
 // stationRoutes maps canonical station names to their route config.
-var stationRoutes = map[string]StationRoute{
-	"八王子みなみ野駅": {
-		Key:           "minamino",
-		StationStopID: 2,
-		StationName:   "八王子みなみ野駅",
-		SchoolStopID:  5,
-		SchoolName:    "大学（みなみ野駅方面）",
-	},
-	"八王子駅": {
-		Key:           "hachioji",
-		StationStopID: 1,
-		StationName:   "八王子駅",
-		SchoolStopID:  3,
-		SchoolName:    "大学（八王子駅方面）",
-	},
-	"学生会館": {
-		Key:           "gakuseikaikan",
-		StationStopID: 6,
-		StationName:   "学生会館",
-		SchoolStopID:  4,
-		SchoolName:    "大学（学生会館方面）",
-	},
-}
-
-// stationAliases maps alternate names Gemini might return to canonical names.
-var stationAliases = map[string]string{
-	"八王子みなみ野":       "八王子みなみ野駅",
-	"みなみ野駅":         "八王子みなみ野駅",
-	"みなみ野":          "八王子みなみ野駅",
-	"八王子駅南口":        "八王子駅",
-	"八王子南口":         "八王子駅",
-	"hachioji":      "八王子駅",
-	"学生会館前":         "学生会館",
-	"gakuseikaikan": "学生会館",
+// stationAliases maps alternate names to canonical names.
+// Both are filled in by useRouteConfig.
+var (
+	stationRoutes  = map[string]StationRoute{}
+	stationAliases = map[string]string{}
+)
+
+// loadRouteConfig reads the route config at path and validates it against bus_stops.json
+// in the same directory.
+func loadRouteConfig(path string) (*RouteConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("路線設定の読み込み失敗: %w", err)
+	}
+	var cfg RouteConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("路線設定のパース失敗 %s: %w", path, err)
+	}
+
+	stopsPath := filepath.Join(filepath.Dir(path), "bus_stops.json")
+	stopsData, err := os.ReadFile(stopsPath)
+	if err != nil {
+		return nil, fmt.Errorf("バス停データの読み込み失敗: %w", err)
+	}
+	var stops []struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(stopsData, &stops); err != nil {
+		return nil, fmt.Errorf("バス停データのパース失敗 %s: %w", stopsPath, err)
+	}
+	stopNames := map[int]string{}
+	for _, s := range stops {
+		stopNames[s.ID] = s.Name
+	}
+
+	if errs := validateRouteConfig(cfg, stopNames); len(errs) > 0 {
+		return nil, fmt.Errorf("路線設定が不正です %s: %w", path, errors.Join(errs...))
+	}
+	return &cfg, nil
 }
 
-func lookupStation(name string) (StationRoute, error) {
+// validateRouteConfig checks cfg for unknown stop IDs, duplicate keys or names, and unusable
+// column mappings. stopNames maps bus stop ID to name.
+func validateRouteConfig(cfg RouteConfig, stopNames map[int]string) []error {
+	var errs []error
+	if cfg.Version != routeConfigVersion {
+		errs = append(errs, fmt.Errorf("version %d is not supported (want %d)", cfg.Version, routeConfigVersion))
+	}
+	if len(cfg.Routes) == 0 {
+		errs = append(errs, fmt.Errorf("routes is empty"))
+	}
+
+	keys := map[string]bool{}
+	names := map[string]string{} // station name or alias → route key
+	for i, r := range cfg.Routes {
+		p := fmt.Sprintf("routes[%d]", i)
+		if r.Key == "" {
+			errs = append(errs, fmt.Errorf("%s: key is empty", p))
+		} else if keys[r.Key] {
+			errs = append(errs, fmt.Errorf("%s: duplicate key %q", p, r.Key))
+		}
+		keys[r.Key] = true
+
+		if name, ok := stopNames[r.StationStopID]; !ok {
+			errs = append(errs, fmt.Errorf("%s: stationStopId %d not in bus_stops.json", p, r.StationStopID))
+		} else if name != r.StationName {
+			errs = append(errs, fmt.Errorf("%s: stationName %q does not match bus stop %d (%q)", p, r.StationName, r.StationStopID, name))
+		}
+		if _, ok := stopNames[r.SchoolStopID]; !ok {
+			errs = append(errs, fmt.Errorf("%s: schoolStopId %d not in bus_stops.json", p, r.SchoolStopID))
+		}
+		if r.SchoolName == "" {
+			errs = append(errs, fmt.Errorf("%s: schoolName is empty", p))
+		}
+
+		for _, n := range append([]string{r.StationName}, r.Aliases...) {
+			if other, ok := names[n]; ok {
+				errs = append(errs, fmt.Errorf("%s: name %q is also used by route %q", p, n, other))
+			}
+			names[n] = r.Key
+		}
+
+		for dir, c := range map[string]ColumnMapping{"outbound": r.Columns.Outbound, "inbound": r.Columns.Inbound} {
+			if c.Departure < 0 || c.Arrival < 0 || c.Departure >= tableColumns || c.Arrival >= tableColumns || c.Departure == c.Arrival {
+				errs = append(errs, fmt.Errorf("%s: columns.%s must be two different indices from 0 to %d, got %d/%d", p, dir, tableColumns-1, c.Departure, c.Arrival))
+			}
+		}
+	}
+	return errs
+}
+
+// useRouteConfig makes cfg the route table used by lookupStation.
+func useRouteConfig(cfg *RouteConfig) {
+	routes := map[string]StationRoute{}
+	aliases := map[string]string{}
+	for _, r := range cfg.Routes {
+		routes[r.StationName] = r
+		for _, a := range r.Aliases {
+			aliases[a] = r.StationName
+		}
+	}
+	stationRoutes, stationAliases = routes, aliases
+}
+
+// Station match kinds, from most to least trustworthy. Only exact and alias matches are
+// written to the output directory; the rest go to the review queue.
+const (
+	matchExact = "exact"
+	matchAlias = "alias"
+	matchFuzzy = "fuzzy"
+	matchNone  = "none"
+)
+
+// StationMatch explains how an extracted station name was matched to a route.
+type StationMatch struct {
+	Kind     string  `json:"kind"`
+	Score    float64 `json:"score"` // 1 for exact, 0.9 for alias, at most 0.5 for fuzzy, 0 for none
+	RouteKey string  `json:"routeKey,omitempty"`
+	Matched  string  `json:"matched,omitempty"` // canonical name or alias that matched
+	Reason   string  `json:"reason"`
+}
+
+// confident reports whether the match is good enough to write without review.
+func (m StationMatch) confident() bool {
+	return m.Kind == matchExact || m.Kind == matchAlias
+}
+
+// matchStation finds the route for an extracted station name and scores the match.
+// Fuzzy matches compare by substring in either direction against canonical names and aliases;
+// the score is the rune-length ratio of the shorter to the longer name, halved.
+func matchStation(name string) (StationRoute, StationMatch) {
 	if r, ok := stationRoutes[name]; ok {
-		return r, nil
+		return r, StationMatch{Kind: matchExact, Score: 1, RouteKey: r.Key, Matched: name, Reason: "駅名が完全一致"}
 	}
-	// try aliases
 	if canonical, ok := stationAliases[name]; ok {
 		if r, ok := stationRoutes[canonical]; ok {
-			return r, nil
+			return r, StationMatch{Kind: matchAlias, Score: 0.9, RouteKey: r.Key, Matched: name, Reason: fmt.Sprintf("エイリアス %q → %q", name, canonical)}
 		}
 	}
-	// fuzzy: check if any canonical name is contained
-	for canonical, r := range stationRoutes {
-		if strings.Contains(name, canonical) || strings.Contains(canonical, name) {
-			return r, nil
+
+	none := StationMatch{Kind: matchNone, Reason: fmt.Sprintf("駅名 %q に一致する路線がありません", name)}
+	if strings.TrimSpace(name) == "" {
+		return StationRoute{}, none
+	}
+
+	candidates := make([]string, 0, len(stationRoutes)+len(stationAliases))
+	for canonical := range stationRoutes {
+		candidates = append(candidates, canonical)
+	}
+	for alias := range stationAliases {
+		candidates = append(candidates, alias)
+	}
+	sort.Strings(candidates) // ties resolve the same way on every run
+
+	best, bestScore := "", 0.0
+	for _, c := range candidates {
+		if !strings.Contains(name, c) && !strings.Contains(c, name) {
+			continue
+		}
+		a, b := utf8.RuneCountInString(name), utf8.RuneCountInString(c)
+		score := float64(min(a, b)) / float64(max(a, b)) / 2
+		if score > bestScore {
+			best, bestScore = c, score
 		}
 	}
-	return StationRoute{}, fmt.Errorf("unknown station %q", name)
+	if best == "" {
+		return StationRoute{}, none
+	}
+	canonical := best
+	if c, ok := stationAliases[best]; ok {
+		canonical = c
+	}
+	r := stationRoutes[canonical]
+	return r, StationMatch{
+		Kind: matchFuzzy, Score: bestScore, RouteKey: r.Key, Matched: best,
+		Reason: fmt.Sprintf("駅名 %q が %q を部分的に含むだけの一致", name, best),
+	}
+}
+
+// routeByKey returns the route whose key is key.
+func routeByKey(key string) (StationRoute, bool) {
+	for _, r := range stationRoutes {
+		if r.Key == key {
+			return r, true
+		}
+	}
+	return StationRoute{}, false
+}
+
+func lookupStation(name string) (StationRoute, error) {
+	r, m := matchStation(name)
+	if m.Kind == matchNone {
+		return StationRoute{}, fmt.Errorf("unknown station %q", name)
+	}
+	return r, nil
 }
 
 var dayTypeLabel = map[string]string{
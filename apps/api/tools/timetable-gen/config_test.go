@@ -1,6 +1,24 @@
 package main
 
-import "testing"
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMain installs the real station_routes.json, so every test maps stations the way the
+// tool does and CI fails if the data file stops validating against bus_stops.json.
+func TestMain(m *testing.M) {
+	cfg, err := loadRouteConfig(defaultRoutesPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	useRouteConfig(cfg)
+	os.Exit(m.Run())
+}
 
 func TestLookupStation_Canonical(t *testing.T) {
 	tests := []string{"八王子みなみ野駅", "八王子駅", "学生会館"}
@@ -73,3 +91,77 @@ func TestScheduleLabel(t *testing.T) {
 		}
 	}
 }
+
+func TestLookupStation_Columns(t *testing.T) {
+	r, err := lookupStation("八王子駅")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Columns.Outbound != (ColumnMapping{Departure: 0, Arrival: 1}) || r.Columns.Inbound != (ColumnMapping{Departure: 1, Arrival: 2}) {
+		t.Errorf("columns = %+v", r.Columns)
+	}
+}
+
+func TestLoadRouteConfig_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	writeJSON(t, filepath.Join(dir, "bus_stops.json"), []map[string]any{
+		{"id": 1, "name": "八王子駅"},
+		{"id": 3, "name": "大学"},
+	})
+	route := func(key, name string, stationID int) map[string]any {
+		return map[string]any{
+			"key": key, "stationName": name, "stationStopId": stationID,
+			"schoolName": "大学", "schoolStopId": 3,
+			"columns": map[string]any{
+				"outbound": map[string]int{"departure": 0, "arrival": 1},
+				"inbound":  map[string]int{"departure": 1, "arrival": 1},
+			},
+		}
+	}
+	path := filepath.Join(dir, "station_routes.json")
+	writeJSON(t, path, map[string]any{
+		"version": 1,
+		"routes": []any{
+			route("hachioji", "八王子駅", 1),
+			route("hachioji", "みなみ野駅", 2),
+		},
+	})
+
+	_, err := loadRouteConfig(path)
+	if err == nil {
+		t.Fatal("expected validation error, got nil")
+	}
+	for _, want := range []string{"duplicate key", "stationStopId 2 not in bus_stops.json", "columns.inbound"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error should mention %q: %v", want, err)
+		}
+	}
+}
+
+func TestLoadRouteConfig_ColumnOutOfRange(t *testing.T) {
+	dir := t.TempDir()
+	writeJSON(t, filepath.Join(dir, "bus_stops.json"), []map[string]any{
+		{"id": 1, "name": "八王子駅"},
+		{"id": 3, "name": "大学"},
+	})
+	path := filepath.Join(dir, "station_routes.json")
+	writeJSON(t, path, map[string]any{
+		"version": 1,
+		"routes": []any{map[string]any{
+			"key": "hachioji", "stationName": "八王子駅", "stationStopId": 1,
+			"schoolName": "大学", "schoolStopId": 3,
+			"columns": map[string]any{
+				"outbound": map[string]int{"departure": 0, "arrival": 3},
+				"inbound":  map[string]int{"departure": 1, "arrival": 2},
+			},
+		}},
+	})
+
+	_, err := loadRouteConfig(path)
+	if err == nil {
+		t.Fatal("expected validation error for column index 3, got nil")
+	}
+	if !strings.Contains(err.Error(), "columns.outbound") || strings.Contains(err.Error(), "columns.inbound") {
+		t.Errorf("error should mention only columns.outbound: %v", err)
+	}
+}
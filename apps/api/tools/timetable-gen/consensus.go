@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"api/pkg/board"
+)
+
+// namedExtractor labels an extraction run so disagreements can say which run produced what.
+type namedExtractor struct {
+	Label string
+	Extractor
+}
+
+// newExtractors builds the extraction runs for one PDF: the primary backend `runs` times,
+// plus one run of crossCheck when set. Only the first run of the primary backend uses the
+// extraction cache; repeated runs must actually call the backend to be worth comparing.
+func newExtractors(ctx context.Context, opts extractorOptions, runs int, crossCheck string) ([]namedExtractor, func(), error) {
+	if runs < 1 {
+		runs = 1
+	}
+	var closers []func()
+	closeAll := func() {
+		for _, c := range closers {
+			c()
+		}
+	}
+
+	primary, closePrimary, err := newExtractor(ctx, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	closers = append(closers, closePrimary)
+	label := opts.Backend
+	if label == "" {
+		label = extractorGemini
+	}
+	extractors := []namedExtractor{{Label: fmt.Sprintf("%s#1", label), Extractor: primary}}
+
+	if runs > 1 {
+		uncached := opts
+		uncached.CacheDir = ""
+		repeat, closeRepeat, err := newExtractor(ctx, uncached)
+		if err != nil {
+			closeAll()
+			return nil, nil, err
+		}
+		closers = append(closers, closeRepeat)
+		for i := 2; i <= runs; i++ {
+			extractors = append(extractors, namedExtractor{Label: fmt.Sprintf("%s#%d", label, i), Extractor: repeat})
+		}
+	}
+
+	if crossCheck != "" {
+		other := opts
+		other.Backend = crossCheck
+		ext, closeOther, err := newExtractor(ctx, other)
+		if err != nil {
+			closeAll()
+			return nil, nil, err
+		}
+		closers = append(closers, closeOther)
+		extractors = append(extractors, namedExtractor{Label: crossCheck, Extractor: ext})
+	}
+
+	return extractors, closeAll, nil
+}
+
+// Disagreement is one field on which two extraction runs produced different services.
+type Disagreement struct {
+	ServiceID    string
+	SegmentIndex int    // -1 for service-level fields
+	Field        string // e.g. "times[3]", "validityPeriods", "startTime"
+	BaseRun      string
+	BaseValue    string
+	OtherRun     string
+	OtherValue   string
+}
+
+// extractServices runs every extractor on the PDF and maps each result to services.
+// With a single extractor this is Extract + Map. With several, only services on which all
+// runs agree trip by trip are returned; the rest are reported as disagreements.
+// Tables whose station name is not an exact or alias match are left out of the services and
+// returned for review, taken from the first run.
+func extractServices(ctx context.Context, extractors []namedExtractor, pdfData []byte, periods []ValidityPeriod) ([]ServiceData, []ReviewItem, []Disagreement, error) {
+	var pending []ReviewItem
+	results := make([][]ServiceData, len(extractors))
+	for i, ext := range extractors {
+		if len(extractors) > 1 {
+			log.Printf("抽出 %d/%d (%s)...", i+1, len(extractors), ext.Label)
+		}
+		extracted, err := ext.Extract(ctx, pdfData)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("抽出失敗 (%s): %w", ext.Label, err)
+		}
+		confident, items := splitForReview(extracted, periods)
+		if i == 0 {
+			pending = items
+		}
+		services, err := Map(confident, periods)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("マッピング失敗 (%s): %w", ext.Label, err)
+		}
+		results[i] = services
+	}
+	if len(extractors) == 1 {
+		return results[0], pending, nil, nil
+	}
+
+	agreed, disagreements := buildConsensus(extractors, results)
+	return agreed, pending, disagreements, nil
+}
+
+// buildConsensus compares every run against the first and keeps services that are identical
+// in all of them. Services missing from any run are reported as disagreements too.
+func buildConsensus(extractors []namedExtractor, results [][]ServiceData) ([]ServiceData, []Disagreement) {
+	var agreed []ServiceData
+	var disagreements []Disagreement
+
+	seen := map[string]bool{}
+	for _, base := range results[0] {
+		seen[base.ID] = true
+		var diffs []Disagreement
+		for k := 1; k < len(results); k++ {
+			other, ok := findService(results[k], base.ID)
+			if !ok {
+				diffs = append(diffs, Disagreement{
+					ServiceID: base.ID, SegmentIndex: -1, Field: "service",
+					BaseRun: extractors[0].Label, BaseValue: "あり",
+					OtherRun: extractors[k].Label, OtherValue: "なし",
+				})
+				continue
+			}
+			for _, d := range compareServices(base, other) {
+				d.ServiceID = base.ID
+				d.BaseRun, d.OtherRun = extractors[0].Label, extractors[k].Label
+				diffs = append(diffs, d)
+			}
+		}
+		if len(diffs) == 0 {
+			agreed = append(agreed, base)
+		}
+		disagreements = append(disagreements, diffs...)
+	}
+
+	for k := 1; k < len(results); k++ {
+		for _, svc := range results[k] {
+			if seen[svc.ID] {
+				continue
+			}
+			seen[svc.ID] = true
+			disagreements = append(disagreements, Disagreement{
+				ServiceID: svc.ID, SegmentIndex: -1, Field: "service",
+				BaseRun: extractors[0].Label, BaseValue: "なし",
+				OtherRun: extractors[k].Label, OtherValue: "あり",
+			})
+		}
+	}
+	return agreed, disagreements
+}
+
+func findService(services []ServiceData, id string) (ServiceData, bool) {
+	for _, s := range services {
+		if s.ID == id {
+			return s, true
+		}
+	}
+	return ServiceData{}, false
+}
+
+// compareServices lists the fields that differ between a and b. ServiceID and run labels
+// are left for the caller to fill in.
+func compareServices(a, b ServiceData) []Disagreement {
+	var diffs []Disagreement
+	add := func(seg int, field, av, bv string) {
+		if av != bv {
+			diffs = append(diffs, Disagreement{SegmentIndex: seg, Field: field, BaseValue: av, OtherValue: bv})
+		}
+	}
+
+	add(-1, "from", fmt.Sprint(a.From.StopID), fmt.Sprint(b.From.StopID))
+	add(-1, "to", fmt.Sprint(a.To.StopID), fmt.Sprint(b.To.StopID))
+	add(-1, "validityPeriods", formatPeriods(a.ValidityPeriods), formatPeriods(b.ValidityPeriods))
+	add(-1, "segments", fmt.Sprintf("%d 件", len(a.Segments)), fmt.Sprintf("%d 件", len(b.Segments)))
+
+	for i := 0; i < len(a.Segments) && i < len(b.Segments); i++ {
+		sa, sb := a.Segments[i], b.Segments[i]
+		add(i, "segmentType", sa.SegmentType, sb.SegmentType)
+		add(i, "condition", board.ConditionLabel(sa.Condition.scheduleCondition()), board.ConditionLabel(sb.Condition.scheduleCondition()))
+		if sa.SegmentType != sb.SegmentType {
+			continue
+		}
+		switch sa.SegmentType {
+		case "fixed":
+			n := len(sa.Times)
+			if len(sb.Times) > n {
+				n = len(sb.Times)
+			}
+			for j := 0; j < n; j++ {
+				add(i, fmt.Sprintf("times[%d]", j), timePairAt(sa.Times, j), timePairAt(sb.Times, j))
+			}
+		case "shuttle":
+			add(i, "startTime", sa.StartTime, sb.StartTime)
+			add(i, "endTime", sa.EndTime, sb.EndTime)
+			add(i, "interval", formatInterval(sa.Interval), formatInterval(sb.Interval))
+		}
+	}
+	return diffs
+}
+
+func timePairAt(times []TimePair, i int) string {
+	if i >= len(times) {
+		return "(なし)"
+	}
+	return times[i].Departure + "→" + times[i].Arrival
+}
+
+func formatPeriods(periods []ValidityPeriod) string {
+	parts := make([]string, len(periods))
+	for i, vp := range periods {
+		parts[i] = vp.From + "〜" + vp.To
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatInterval(iv *Interval) string {
+	if iv == nil {
+		return "(なし)"
+	}
+	return fmt.Sprintf("%d〜%d分", iv.Min, iv.Max)
+}
+
+// writeDisagreementReport prints disagreements grouped by output file.
+func writeDisagreementReport(w io.Writer, source string, disagreements []Disagreement) {
+	if len(disagreements) == 0 {
+		return
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "=== 抽出結果の不一致: %s ===\n", source)
+	current := ""
+	for _, d := range disagreements {
+		if d.ServiceID != current {
+			current = d.ServiceID
+			fmt.Fprintf(&b, "[%s.json]\n", d.ServiceID)
+		}
+		fmt.Fprintf(&b, "  %s\n", d)
+	}
+	io.WriteString(w, b.String())
+}
+
+// String formats the disagreement without its service ID, e.g.
+// "segments[0].times[3]: gemini#1=8:00→8:20 / text=8:00→8:25".
+func (d Disagreement) String() string {
+	loc := d.Field
+	if d.SegmentIndex >= 0 {
+		loc = fmt.Sprintf("segments[%d].%s", d.SegmentIndex, d.Field)
+	}
+	return fmt.Sprintf("%s: %s=%s / %s=%s", loc, d.BaseRun, d.BaseValue, d.OtherRun, d.OtherValue)
+}
+
+// disagreedServiceIDs returns the distinct service IDs in disagreements, in order.
+func disagreedServiceIDs(disagreements []Disagreement) []string {
+	var ids []string
+	seen := map[string]bool{}
+	for _, d := range disagreements {
+		if !seen[d.ServiceID] {
+			seen[d.ServiceID] = true
+			ids = append(ids, d.ServiceID)
+		}
+	}
+	return ids
+}
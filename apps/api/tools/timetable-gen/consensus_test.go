@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// staticExtractor returns a fixed extraction.
+type staticExtractor struct {
+	data *ExtractedData
+}
+
+func (s staticExtractor) Extract(context.Context, []byte) (*ExtractedData, error) {
+	return s.data, nil
+}
+
+func TestExtractServices_Agree(t *testing.T) {
+	extractors := []namedExtractor{
+		{Label: "a", Extractor: staticExtractor{sampleExtracted()}},
+		{Label: "b", Extractor: staticExtractor{sampleExtracted()}},
+	}
+	services, _, disagreements, err := extractServices(context.Background(), extractors, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(disagreements) != 0 {
+		t.Errorf("expected no disagreements, got %+v", disagreements)
+	}
+	if len(services) != 2 {
+		t.Errorf("expected 2 agreed services, got %d", len(services))
+	}
+}
+
+func TestExtractServices_SwappedColumns(t *testing.T) {
+	swapped := sampleExtracted()
+	// the last row has its station and arrival columns swapped
+	rows := swapped.Tables[0].Segments[0].Rows
+	rows[len(rows)-1] = []string{"12:00", "12:40", "12:20"}
+
+	extractors := []namedExtractor{
+		{Label: "gemini#1", Extractor: staticExtractor{sampleExtracted()}},
+		{Label: "text", Extractor: staticExtractor{swapped}},
+	}
+	services, _, disagreements, err := extractServices(context.Background(), extractors, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 0 {
+		t.Errorf("expected both services to be withheld, got %d", len(services))
+	}
+	ids := disagreedServiceIDs(disagreements)
+	if len(ids) != 2 {
+		t.Fatalf("expected 2 disagreed services, got %v", ids)
+	}
+
+	var buf bytes.Buffer
+	writeDisagreementReport(&buf, "timetable.pdf", disagreements)
+	report := buf.String()
+	for _, want := range []string{ids[0] + ".json", "segments[2].times[2]", "gemini#1=12:00→12:20", "text=12:00→12:40"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestExtractServices_MissingTrip(t *testing.T) {
+	dropped := sampleExtracted()
+	rows := dropped.Tables[0].Segments[0].Rows
+	dropped.Tables[0].Segments[0].Rows = rows[:len(rows)-1]
+
+	extractors := []namedExtractor{
+		{Label: "a", Extractor: staticExtractor{sampleExtracted()}},
+		{Label: "b", Extractor: staticExtractor{dropped}},
+	}
+	_, _, disagreements, err := extractServices(context.Background(), extractors, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, d := range disagreements {
+		if d.Field == "times[2]" && d.OtherValue == "(なし)" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected a times[2] disagreement with a missing trip, got %+v", disagreements)
+	}
+}
+
+func TestGenerateFromPDF_WritesOnlyAgreedServices(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := filepath.Join(dir, "timetable.pdf")
+	if err := os.WriteFile(pdfPath, []byte("%PDF-fake"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	other := sampleExtracted()
+	other.Tables = append(other.Tables, ExtractedTable{
+		StationName: "学生会館",
+		DayType:     "weekday",
+		ValidFrom:   other.Tables[0].ValidFrom,
+		ValidTo:     other.Tables[0].ValidTo,
+		Segments:    other.Tables[0].Segments,
+	})
+	base := sampleExtracted()
+	base.Tables = append(base.Tables, other.Tables[1])
+	base.Tables[1].Segments = []ExtractedSegment{{Type: "fixed", Rows: [][]string{
+		{"7:00", "7:10", "7:20"}, {"8:00", "8:10", "8:20"}, {"9:00", "9:10", "9:20"},
+		{"10:00", "10:10", "10:20"}, {"11:00", "11:10", "11:20"},
+	}}}
+
+	var report bytes.Buffer
+	res := generateFromPDF(context.Background(), pdfPath, generateOptions{
+		Extractors: []namedExtractor{
+			{Label: "a", Extractor: staticExtractor{base}},
+			{Label: "b", Extractor: staticExtractor{other}},
+		},
+		OutputDir: dir,
+		Report:    &report,
+	})
+	if res.Saved != 2 || res.Failed != 2 {
+		t.Errorf("generateFromPDF = (%d, %d), want (2, 2)", res.Saved, res.Failed)
+	}
+	if !strings.Contains(report.String(), "gakuseikaikan") {
+		t.Errorf("report should mention the disagreeing gakuseikaikan services:\n%s", report.String())
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*gakuseikaikan*.json"))
+	if len(files) != 0 {
+		t.Errorf("disagreeing services must not be written, found %v", files)
+	}
+}
@@ -0,0 +1,594 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvColumns is the header of the CSV written by export-csv and read by import-csv.
+// Each row is one trip of a fixed segment, or one shuttle segment. Service and segment
+// fields are repeated on every row so that rows can be sorted and filtered in a spreadsheet.
+var csvColumns = []string{
+	"service_id", "name", "from_stop_id", "from_name", "to_stop_id", "to_name", "direction", "validity",
+	"segment", "segment_type", "condition_type", "condition_value", "condition_from", "condition_to",
+	"departure", "arrival", "start_time", "end_time", "interval_min", "interval_max", "note",
+}
+
+// utf8BOM is written first so that Excel opens the CSV as UTF-8.
+const utf8BOM = "\uFEFF"
+
+// csvRow is one CSV row keyed by column name.
+type csvRow map[string]string
+
+func (r csvRow) record() []string {
+	rec := make([]string, len(csvColumns))
+	for i, c := range csvColumns {
+		rec[i] = r[c]
+	}
+	return rec
+}
+
+// formatValidity encodes validity periods as "from/to" joined by ";".
+func formatValidity(periods []ValidityPeriod) string {
+	parts := make([]string, len(periods))
+	for i, p := range periods {
+		parts[i] = p.From + "/" + p.To
+	}
+	return strings.Join(parts, ";")
+}
+
+// serviceRows converts svc to CSV rows. A fixed segment without times and a service without
+// segments are kept as a row with empty trip columns so that the round trip is lossless.
+func serviceRows(svc ServiceData) []csvRow {
+	base := csvRow{
+		"service_id":   svc.ID,
+		"name":         svc.Name,
+		"from_stop_id": strconv.Itoa(svc.From.StopID),
+		"from_name":    svc.From.DisplayName,
+		"to_stop_id":   strconv.Itoa(svc.To.StopID),
+		"to_name":      svc.To.DisplayName,
+		"direction":    svc.Direction,
+		"validity":     formatValidity(svc.ValidityPeriods),
+	}
+	if len(svc.Segments) == 0 {
+		return []csvRow{base}
+	}
+
+	var rows []csvRow
+	for i, seg := range svc.Segments {
+		segRow := csvRow{}
+		for k, v := range base {
+			segRow[k] = v
+		}
+		segRow["segment"] = strconv.Itoa(i + 1)
+		segRow["segment_type"] = seg.SegmentType
+		segRow["condition_type"] = seg.Condition.Type
+		segRow["condition_value"] = seg.Condition.Value
+		segRow["condition_from"] = seg.Condition.From
+		segRow["condition_to"] = seg.Condition.To
+		segRow["start_time"] = seg.StartTime
+		segRow["end_time"] = seg.EndTime
+		if seg.Interval != nil {
+			segRow["interval_min"] = strconv.Itoa(seg.Interval.Min)
+			segRow["interval_max"] = strconv.Itoa(seg.Interval.Max)
+		}
+		segRow["note"] = seg.Note
+
+		if len(seg.Times) == 0 {
+			rows = append(rows, segRow)
+			continue
+		}
+		for _, tp := range seg.Times {
+			row := csvRow{}
+			for k, v := range segRow {
+				row[k] = v
+			}
+			row["departure"] = tp.Departure
+			row["arrival"] = tp.Arrival
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// writeServicesCSV writes services as CSV, preceded by a UTF-8 BOM.
+func writeServicesCSV(w io.Writer, services []ServiceData) error {
+	if _, err := io.WriteString(w, utf8BOM); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvColumns); err != nil {
+		return err
+	}
+	for _, svc := range services {
+		for _, row := range serviceRows(svc) {
+			if err := cw.Write(row.record()); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvError is a problem with one CSV row. Line is the spreadsheet row number (header = 1),
+// which differs from the file line when a quoted cell spans lines.
+type csvError struct {
+	Line int
+	Msg  string
+}
+
+func (e csvError) Error() string {
+	if e.Line == 0 {
+		return e.Msg
+	}
+	return fmt.Sprintf("%d 行目: %s", e.Line, e.Msg)
+}
+
+// csvSegment collects the rows of one segment while reading.
+type csvSegment struct {
+	line  int // first row
+	seg   ServiceSegment
+	lines []int // row of each time in seg.Times
+	empty bool  // a fixed segment written without times
+}
+
+// csvService collects the rows of one service while reading.
+type csvService struct {
+	svc      ServiceData
+	line     int // first row
+	segments map[int]*csvSegment
+}
+
+// spreadsheetTime accepts "7:30" and the "7:30:00" a spreadsheet writes back after
+// treating the cell as a time.
+var spreadsheetTime = regexp.MustCompile(`^(\d{1,2}:\d{2}):00$`)
+
+func normalizeCSVTime(s string) string {
+	s = strings.TrimSpace(s)
+	if m := spreadsheetTime.FindStringSubmatch(s); m != nil {
+		return m[1]
+	}
+	return s
+}
+
+// normalizeCSVDate accepts YYYY-MM-DD and the YYYY/M/D a spreadsheet writes back after
+// treating the cell as a date.
+func normalizeCSVDate(s string) string {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse("2006/1/2", s); err == nil {
+		return t.Format("2006-01-02")
+	}
+	return s
+}
+
+func parseCSVValidity(s string) ([]ValidityPeriod, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var periods []ValidityPeriod
+	for _, part := range strings.Split(s, ";") {
+		from, to, ok := strings.Cut(part, "/")
+		// スプレッドシートで日付に変換されると "2026/4/7/2026/7/29" になる
+		if fields := strings.Split(part, "/"); len(fields) == 6 {
+			from, to, ok = strings.Join(fields[:3], "/"), strings.Join(fields[3:], "/"), true
+		}
+		if !ok {
+			return nil, fmt.Errorf("validity %q は from/to の形式で指定してください", part)
+		}
+		periods = append(periods, ValidityPeriod{From: normalizeCSVDate(from), To: normalizeCSVDate(to)})
+	}
+	return periods, nil
+}
+
+func csvInt(row csvRow, col string) (int, error) {
+	v := strings.TrimSpace(row[col])
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s %q は整数で指定してください", col, v)
+	}
+	return n, nil
+}
+
+// readServicesCSV parses CSV written by writeServicesCSV (possibly edited in a spreadsheet).
+// Services keep the order of their first row and segments are ordered by their number.
+// All row-level problems are returned together; services are only returned when there are none.
+func readServicesCSV(r io.Reader) ([]ServiceData, map[string]*csvService, []error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, []error{err}
+	}
+	data = bytes.TrimPrefix(data, []byte(utf8BOM))
+
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, nil, []error{csvError{Line: 1, Msg: fmt.Sprintf("ヘッダーを読み込めません: %v", err)}}
+	}
+	index := map[string]int{}
+	var errs []error
+	for i, h := range header {
+		h = strings.TrimSpace(h)
+		if !slices.Contains(csvColumns, h) {
+			errs = append(errs, csvError{Line: 1, Msg: fmt.Sprintf("未知の列 %q", h)})
+			continue
+		}
+		index[h] = i
+	}
+	for _, c := range csvColumns {
+		if _, ok := index[c]; !ok {
+			errs = append(errs, csvError{Line: 1, Msg: fmt.Sprintf("列 %q がありません", c)})
+		}
+	}
+	if len(errs) > 0 {
+		return nil, nil, errs
+	}
+
+	services := map[string]*csvService{}
+	var order []string
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			errs = append(errs, csvError{Line: line, Msg: err.Error()})
+			var perr *csv.ParseError
+			if errors.As(err, &perr) && perr.Err == csv.ErrQuote {
+				break // 引用符が閉じていないと以降の行を正しく読めない
+			}
+			continue
+		}
+		row := csvRow{}
+		blank := true
+		for c, i := range index {
+			if i < len(rec) {
+				row[c] = rec[i]
+				blank = blank && strings.TrimSpace(rec[i]) == ""
+			}
+		}
+		if blank {
+			continue
+		}
+		rowErrs := addCSVRow(services, &order, row, line)
+		errs = append(errs, rowErrs...)
+	}
+	if len(errs) > 0 {
+		return nil, nil, errs
+	}
+
+	out := make([]ServiceData, 0, len(order))
+	for _, id := range order {
+		cs := services[id]
+		numbers := make([]int, 0, len(cs.segments))
+		for n := range cs.segments {
+			numbers = append(numbers, n)
+		}
+		sort.Ints(numbers)
+		for _, n := range numbers {
+			cs.svc.Segments = append(cs.svc.Segments, cs.segments[n].seg)
+		}
+		out = append(out, cs.svc)
+	}
+	return out, services, nil
+}
+
+// serviceIDPattern is the form of service IDs, which are also the output file names.
+var serviceIDPattern = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+// addCSVRow adds one row to its service and segment, checking that the repeated service and
+// segment fields agree with the first row of the same service or segment.
+func addCSVRow(services map[string]*csvService, order *[]string, row csvRow, line int) []error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, csvError{Line: line, Msg: fmt.Sprintf(format, args...)})
+	}
+
+	id := strings.TrimSpace(row["service_id"])
+	switch {
+	case id == "":
+		fail("service_id が空です")
+		return errs
+	case strings.ContainsAny(id, `/\`) || strings.Contains(id, ".."):
+		// service_id はそのまま出力先のファイル名になる
+		fail("service_id %q にパス区切りや .. は使えません", id)
+		return errs
+	case !serviceIDPattern.MatchString(id):
+		fail("service_id %q は英小文字・数字をハイフンでつないだ形式で指定してください", id)
+		return errs
+	}
+
+	svc := ServiceData{
+		ID:        id,
+		Name:      row["name"],
+		From:      StopRef{DisplayName: row["from_name"]},
+		To:        StopRef{DisplayName: row["to_name"]},
+		Direction: strings.TrimSpace(row["direction"]),
+	}
+	var err error
+	if svc.From.StopID, err = csvInt(row, "from_stop_id"); err != nil {
+		fail("%v", err)
+	}
+	if svc.To.StopID, err = csvInt(row, "to_stop_id"); err != nil {
+		fail("%v", err)
+	}
+	if svc.ValidityPeriods, err = parseCSVValidity(row["validity"]); err != nil {
+		fail("%v", err)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	cs, ok := services[id]
+	if !ok {
+		cs = &csvService{svc: svc, line: line, segments: map[int]*csvSegment{}}
+		services[id] = cs
+		*order = append(*order, id)
+	} else {
+		for _, d := range []struct{ col, got, want string }{
+			{"name", svc.Name, cs.svc.Name},
+			{"from_stop_id", strconv.Itoa(svc.From.StopID), strconv.Itoa(cs.svc.From.StopID)},
+			{"from_name", svc.From.DisplayName, cs.svc.From.DisplayName},
+			{"to_stop_id", strconv.Itoa(svc.To.StopID), strconv.Itoa(cs.svc.To.StopID)},
+			{"to_name", svc.To.DisplayName, cs.svc.To.DisplayName},
+			{"direction", svc.Direction, cs.svc.Direction},
+			{"validity", formatValidity(svc.ValidityPeriods), formatValidity(cs.svc.ValidityPeriods)},
+		} {
+			if d.got != d.want {
+				fail("%s %q が %s の %d 行目 (%q) と異なります", d.col, d.got, id, cs.line, d.want)
+			}
+		}
+	}
+
+	if strings.TrimSpace(row["segment"]) == "" {
+		for _, col := range []string{"segment_type", "departure", "arrival", "start_time"} {
+			if strings.TrimSpace(row[col]) != "" {
+				fail("segment が空です")
+				break
+			}
+		}
+		return errs
+	}
+	number, err := csvInt(row, "segment")
+	if err != nil || number <= 0 {
+		fail("segment %q は 1 以上の整数で指定してください", row["segment"])
+		return errs
+	}
+
+	seg := ServiceSegment{
+		SegmentType: strings.TrimSpace(row["segment_type"]),
+		Condition: SegmentCondition{
+			Type:  strings.TrimSpace(row["condition_type"]),
+			Value: strings.TrimSpace(row["condition_value"]),
+			From:  normalizeCSVDate(row["condition_from"]),
+			To:    normalizeCSVDate(row["condition_to"]),
+		},
+		StartTime: normalizeCSVTime(row["start_time"]),
+		EndTime:   normalizeCSVTime(row["end_time"]),
+		Note:      row["note"],
+	}
+	if row["interval_min"] != "" || row["interval_max"] != "" {
+		seg.Interval = &Interval{}
+		if seg.Interval.Min, err = csvInt(row, "interval_min"); err != nil {
+			fail("%v", err)
+		}
+		if seg.Interval.Max, err = csvInt(row, "interval_max"); err != nil {
+			fail("%v", err)
+		}
+	}
+	dep, arr := normalizeCSVTime(row["departure"]), normalizeCSVTime(row["arrival"])
+
+	switch seg.SegmentType {
+	case "fixed":
+		if seg.StartTime != "" || seg.EndTime != "" || seg.Interval != nil {
+			fail("fixed の行に start_time / end_time / interval は指定できません")
+		}
+		if (dep == "") != (arr == "") {
+			fail("departure と arrival は両方指定してください")
+		}
+	case "shuttle":
+		if dep != "" || arr != "" {
+			fail("shuttle の行に departure / arrival は指定できません")
+		}
+	default:
+		fail("segment_type %q は fixed か shuttle で指定してください", seg.SegmentType)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	cseg, ok := cs.segments[number]
+	if !ok {
+		cseg = &csvSegment{line: line, seg: seg}
+		cs.segments[number] = cseg
+	} else {
+		prev := cseg.seg
+		same := prev.SegmentType == seg.SegmentType && prev.Condition == seg.Condition &&
+			prev.StartTime == seg.StartTime && prev.EndTime == seg.EndTime &&
+			formatInterval(prev.Interval) == formatInterval(seg.Interval) && prev.Note == seg.Note
+		switch {
+		case !same:
+			fail("segment %d の segment_type / condition / shuttle / note が %d 行目と異なります", number, cseg.line)
+		case seg.SegmentType == "shuttle":
+			fail("shuttle の segment %d は 1 行にしてください (%d 行目と重複)", number, cseg.line)
+		case cseg.empty || dep == "":
+			fail("segment %d に時刻のない行と時刻のある行が混在しています", number)
+		}
+		if len(errs) > 0 {
+			return errs
+		}
+	}
+
+	if seg.SegmentType == "fixed" {
+		if dep == "" {
+			cseg.empty = true
+			return errs
+		}
+		cseg.seg.Times = append(cseg.seg.Times, TimePair{Departure: dep, Arrival: arr})
+		cseg.lines = append(cseg.lines, line)
+	}
+	return errs
+}
+
+// validateSegmentRef finds "segments[i]" and "segments[i].times[j]" in Validate messages.
+var validateSegmentRef = regexp.MustCompile(`segments\[(\d+)\](?:\.times\[(\d+)\])?`)
+
+// csvValidationErrors runs Validate on each imported service and attributes every error to
+// the CSV row it came from: the trip's row, the segment's first row, or the service's first row.
+func csvValidationErrors(services []ServiceData, rows map[string]*csvService) []error {
+	var errs []error
+	for _, svc := range services {
+		cs := rows[svc.ID]
+		numbers := make([]int, 0, len(cs.segments))
+		for n := range cs.segments {
+			numbers = append(numbers, n)
+		}
+		sort.Ints(numbers)
+
+		for _, e := range Validate(svc) {
+			line := cs.line
+			if m := validateSegmentRef.FindStringSubmatch(e.Error()); m != nil {
+				i, _ := strconv.Atoi(m[1])
+				if i < len(numbers) {
+					seg := cs.segments[numbers[i]]
+					line = seg.line
+					if m[2] != "" {
+						if j, _ := strconv.Atoi(m[2]); j < len(seg.lines) {
+							line = seg.lines[j]
+						}
+					}
+				}
+			}
+			errs = append(errs, csvError{Line: line, Msg: fmt.Sprintf("%s: %v", svc.ID, e)})
+		}
+	}
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].(csvError).Line < errs[j].(csvError).Line })
+	return errs
+}
+
+// loadServiceFiles reads one service JSON file, or every service JSON directly under a directory.
+func loadServiceFiles(target string) ([]ServiceData, error) {
+	info, err := os.Stat(target)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return loadServiceDir(target)
+	}
+	svc, err := loadExistingService(filepath.Dir(target), strings.TrimSuffix(filepath.Base(target), ".json"))
+	if err != nil {
+		return nil, err
+	}
+	return []ServiceData{*svc}, nil
+}
+
+// runExportCSV implements `timetable-gen export-csv [--out file.csv] [file.json|directory]`.
+func runExportCSV(args []string) {
+	target, out := "../../data/services", ""
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--out":
+			if i+1 < len(args) {
+				out = args[i+1]
+				i++
+			}
+		default:
+			target = args[i]
+		}
+	}
+
+	services, err := loadServiceFiles(target)
+	if err != nil {
+		log.Fatalf("読み込み失敗: %v", err)
+	}
+
+	w := io.Writer(os.Stdout)
+	if out != "" {
+		f, err := os.Create(out)
+		if err != nil {
+			log.Fatalf("出力ファイル作成失敗: %v", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := writeServicesCSV(w, services); err != nil {
+		log.Fatalf("CSV 書き込み失敗: %v", err)
+	}
+	if out != "" {
+		fmt.Printf("%d サービスを書き出しました: %s\n", len(services), out)
+	}
+}
+
+// runImportCSV implements `timetable-gen import-csv <file.csv> [--output dir] [--dry-run]`.
+// Every service is validated before anything is written; any error aborts the import.
+func runImportCSV(args []string) {
+	outputDir := "../../data/services"
+	dryRun := false
+	var input string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--output":
+			if i+1 < len(args) {
+				outputDir = args[i+1]
+				i++
+			}
+		case "--dry-run":
+			dryRun = true
+		default:
+			input = args[i]
+		}
+	}
+	if input == "" {
+		fmt.Fprintln(os.Stderr, "usage: go run . import-csv <file.csv> [--output dir] [--dry-run]")
+		os.Exit(exitError)
+	}
+
+	f, err := os.Open(input)
+	if err != nil {
+		log.Fatalf("読み込み失敗: %v", err)
+	}
+	services, rows, errs := readServicesCSV(f)
+	f.Close()
+	if len(errs) == 0 {
+		errs = csvValidationErrors(services, rows)
+	}
+	if len(errs) > 0 {
+		for _, e := range errs {
+			fmt.Fprintf(os.Stderr, "  %v\n", e)
+		}
+		fmt.Fprintf(os.Stderr, "\n%s: %d 件のエラー。何も書き込んでいません。\n", input, len(errs))
+		os.Exit(exitError)
+	}
+
+	changed := 0
+	for _, svc := range services {
+		change, err := printServiceDiff(outputDir, svc)
+		if err != nil {
+			log.Fatalf("既存ファイルの読み込み失敗: %v", err)
+		}
+		if change != changeUnchanged {
+			changed++
+		}
+	}
+	if dryRun {
+		fmt.Printf("\n%d サービス中 %d 件に変更があります (dry-run)\n", len(services), changed)
+		os.Exit(diffExitCode(0, changed))
+	}
+	if err := writeServices(outputDir, services); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("\n%d サービスを書き込みました (変更: %d 件)\n", len(services), changed)
+}
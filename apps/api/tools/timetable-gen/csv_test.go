@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func csvRoundTripFixture() []ServiceData {
+	svc := validService()
+	svc.ValidityPeriods = append(svc.ValidityPeriods, ValidityPeriod{From: "2026-09-28", To: "2026-12-25"})
+	svc.Segments = append(svc.Segments,
+		ServiceSegment{
+			SegmentType: "shuttle",
+			Condition:   SegmentCondition{Type: "dayType", Value: "weekday"},
+			StartTime:   "9:30",
+			EndTime:     "10:40",
+			Interval:    &Interval{Min: 3, Max: 5},
+			Note:        "混雑時は増便, \"臨時\"あり",
+		},
+		ServiceSegment{
+			SegmentType: "fixed",
+			Condition:   SegmentCondition{Type: "specificPeriod", From: "2026-08-03", To: "2026-08-07"},
+			Times:       []TimePair{},
+		},
+	)
+
+	special := validService()
+	special.ID = "hachioji-to-school-2026-11-03"
+	special.Segments[0].Condition = SegmentCondition{Type: "specificDate", Value: "2026-11-03"}
+	return []ServiceData{svc, special}
+}
+
+func TestCSV_RoundTripIsLossless(t *testing.T) {
+	services := csvRoundTripFixture()
+
+	var buf bytes.Buffer
+	if err := writeServicesCSV(&buf, services); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), utf8BOM+"service_id,") {
+		t.Errorf("CSV should start with a BOM and the header:\n%s", buf.String())
+	}
+
+	got, _, errs := readServicesCSV(&buf)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if len(got) != len(services) {
+		t.Fatalf("got %d services, want %d", len(got), len(services))
+	}
+	for i := range services {
+		want, _ := marshalJSON(services[i])
+		have, _ := marshalJSON(got[i])
+		if !bytes.Equal(have, want) {
+			t.Errorf("service %s changed in the round trip:\n got %s\nwant %s", services[i].ID, have, want)
+		}
+	}
+}
+
+func TestCSV_RoundTripRealData(t *testing.T) {
+	current, archived, err := loadServicesWithArchive(filepath.Join("..", "..", "data", "services"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	services := append(current, archived...)
+	if len(services) == 0 {
+		t.Fatal("no services in data/services")
+	}
+
+	var buf bytes.Buffer
+	if err := writeServicesCSV(&buf, services); err != nil {
+		t.Fatal(err)
+	}
+	got, _, errs := readServicesCSV(&buf)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if len(got) != len(services) {
+		t.Fatalf("got %d services, want %d", len(got), len(services))
+	}
+	for i := range services {
+		want, _ := marshalJSON(services[i])
+		have, _ := marshalJSON(got[i])
+		if !bytes.Equal(have, want) {
+			t.Errorf("service %s changed in the round trip:\n got %s\nwant %s", services[i].ID, have, want)
+		}
+	}
+}
+
+func TestCSV_AcceptsSpreadsheetFormats(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeServicesCSV(&buf, []ServiceData{validService()}); err != nil {
+		t.Fatal(err)
+	}
+	// 表計算ソフトで保存し直すと BOM が落ち、時刻に秒、日付に / が付く
+	edited := strings.TrimPrefix(buf.String(), utf8BOM)
+	edited = strings.ReplaceAll(edited, "2026-04-07/2026-07-29", "2026/4/7/2026/7/29")
+	edited = strings.ReplaceAll(edited, ",7:30,7:50,", ",7:30:00,7:50:00,")
+
+	got, _, errs := readServicesCSV(strings.NewReader(edited))
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	want, _ := marshalJSON(validService())
+	have, _ := marshalJSON(got[0])
+	if !bytes.Equal(have, want) {
+		t.Errorf("normalised service differs:\n got %s\nwant %s", have, want)
+	}
+}
+
+func TestCSV_RowLevelErrors(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeServicesCSV(&buf, []ServiceData{validService()}); err != nil {
+		t.Fatal(err)
+	}
+	original := buf.String()
+
+	tests := []struct {
+		name string
+		csv  string
+		want string
+	}{
+		{
+			name: "unknown column",
+			csv:  strings.Replace(original, ",note", ",memo", 1),
+			want: "memo",
+		},
+		{
+			name: "bad segment type",
+			csv:  strings.Replace(original, ",fixed,dayType,weekday,,,9:00,", ",bus,dayType,weekday,,,9:00,", 1),
+			want: "5 行目: segment_type \"bus\"",
+		},
+		{
+			name: "direction differs",
+			csv:  strings.Replace(original, ",inbound,2026-04-07/2026-07-29,1,fixed,dayType,weekday,,,8:30,", ",outbound,2026-04-07/2026-07-29,1,fixed,dayType,weekday,,,8:30,", 1),
+			want: "4 行目: direction \"outbound\" が",
+		},
+		{
+			name: "service_id with path separator",
+			csv:  strings.Replace(original, "\nhachioji-to-school-weekday-20260407,", "\n../hachioji-to-school-weekday-20260407,", 1),
+			want: "2 行目: service_id \"../hachioji-to-school-weekday-20260407\" にパス区切りや .. は使えません",
+		},
+		{
+			name: "service_id not matching the ID pattern",
+			csv:  strings.Replace(original, "\nhachioji-to-school-weekday-20260407,", "\nHachioji To School,", 1),
+			want: "2 行目: service_id \"Hachioji To School\" は英小文字・数字",
+		},
+		{
+			name: "validate error on trip row",
+			csv:  strings.Replace(original, ",8:30,8:50,", ",8:30,8:20,", 1),
+			want: "4 行目: hachioji-to-school-weekday-20260407: segments[0].times[2]: departure(8:30) >= arrival(8:20)",
+		},
+	}
+	for _, tt := range tests {
+		services, rows, errs := readServicesCSV(strings.NewReader(tt.csv))
+		if len(errs) == 0 {
+			errs = csvValidationErrors(services, rows)
+		}
+		var msgs []string
+		for _, e := range errs {
+			msgs = append(msgs, e.Error())
+		}
+		if joined := strings.Join(msgs, "\n"); !strings.Contains(joined, tt.want) {
+			t.Errorf("%s: errors = %q, want one containing %q", tt.name, joined, tt.want)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"api/pkg/board"
+)
+
+// Exit codes used when --dry-run or --diff is set, so that CI can gate a pull request.
+const (
+	exitNoChange = 0
+	exitError    = 1
+	exitChanged  = 2
+)
+
+// diffExitCode maps generation counts to the --dry-run/--diff exit code. Errors win over changes.
+func diffExitCode(failed, changed int) int {
+	switch {
+	case failed > 0:
+		return exitError
+	case changed > 0:
+		return exitChanged
+	default:
+		return exitNoChange
+	}
+}
+
+// loadExistingService reads <dir>/<id>.json. It returns nil without error when the file does not exist.
+func loadExistingService(dir, id string) (*ServiceData, error) {
+	data, err := os.ReadFile(filepath.Join(dir, id+".json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var svc ServiceData
+	if err := json.Unmarshal(data, &svc); err != nil {
+		return nil, fmt.Errorf("%s.json: %w", id, err)
+	}
+	return &svc, nil
+}
+
+// diffService describes how next differs from prev in terms an operator cares about:
+// validity periods, stops, and trips added, removed or retimed per condition.
+// It returns nil when the services are semantically equal.
+func diffService(prev, next ServiceData) []string {
+	var lines []string
+
+	if a, b := formatPeriods(prev.ValidityPeriods), formatPeriods(next.ValidityPeriods); a != b {
+		lines = append(lines, fmt.Sprintf("有効期間: %s → %s", a, b))
+	}
+	if prev.From.StopID != next.From.StopID || prev.To.StopID != next.To.StopID {
+		lines = append(lines, fmt.Sprintf("区間: %d→%d から %d→%d", prev.From.StopID, prev.To.StopID, next.From.StopID, next.To.StopID))
+	}
+	if prev.Name != next.Name {
+		lines = append(lines, fmt.Sprintf("名前: %s → %s", prev.Name, next.Name))
+	}
+
+	prevTrips, nextTrips := tripsByCondition(prev), tripsByCondition(next)
+	for _, cond := range unionKeys(prevTrips, nextTrips) {
+		lines = append(lines, diffTrips(cond, prevTrips[cond], nextTrips[cond])...)
+	}
+
+	prevShuttles, nextShuttles := shuttlesByCondition(prev), shuttlesByCondition(next)
+	for _, cond := range unionKeys(prevShuttles, nextShuttles) {
+		a, b := prevShuttles[cond], nextShuttles[cond]
+		for i := 0; i < len(a) || i < len(b); i++ {
+			switch {
+			case i >= len(a):
+				lines = append(lines, fmt.Sprintf("%s: シャトル追加 %s", cond, b[i]))
+			case i >= len(b):
+				lines = append(lines, fmt.Sprintf("%s: シャトル削除 %s", cond, a[i]))
+			case a[i] != b[i]:
+				lines = append(lines, fmt.Sprintf("%s: シャトル変更 %s ⇒ %s", cond, a[i], b[i]))
+			}
+		}
+	}
+	return lines
+}
+
+// diffTrips compares the fixed trips of one condition. Trips are grouped by departure, and
+// each departure's arrivals are compared as a multiset, so trips that share a departure but
+// arrive at different times (for example via different routes) are not merged. When a
+// departure loses and gains an arrival, the pair is reported as a retimed trip rather than
+// a removal plus an addition.
+func diffTrips(cond string, prev, next []TimePair) []string {
+	prevByDep, nextByDep := arrivalsByDeparture(prev), arrivalsByDeparture(next)
+
+	var lines []string
+	for _, dep := range distinctDepartures(next, prev) {
+		removed := subtractArrivals(prevByDep[dep], nextByDep[dep])
+		added := subtractArrivals(nextByDep[dep], prevByDep[dep])
+		for i := 0; i < len(removed) || i < len(added); i++ {
+			switch {
+			case i >= len(removed):
+				lines = append(lines, fmt.Sprintf("%s: 便追加 %s→%s", cond, dep, added[i]))
+			case i >= len(added):
+				lines = append(lines, fmt.Sprintf("%s: 便削除 %s→%s", cond, dep, removed[i]))
+			default:
+				lines = append(lines, fmt.Sprintf("%s: 時刻変更 %s→%s ⇒ %s→%s", cond, dep, removed[i], dep, added[i]))
+			}
+		}
+	}
+	return lines
+}
+
+// arrivalsByDeparture groups the arrivals of trips by departure, sorted by arrival.
+func arrivalsByDeparture(trips []TimePair) map[string][]string {
+	byDep := map[string][]string{}
+	for _, tp := range trips {
+		byDep[tp.Departure] = append(byDep[tp.Departure], tp.Arrival)
+	}
+	for _, arrivals := range byDep {
+		sort.Slice(arrivals, func(i, j int) bool { return clockMinutes(arrivals[i]) < clockMinutes(arrivals[j]) })
+	}
+	return byDep
+}
+
+// distinctDepartures returns the distinct departures of the given trip lists in order of first appearance.
+func distinctDepartures(lists ...[]TimePair) []string {
+	seen := map[string]bool{}
+	var deps []string
+	for _, trips := range lists {
+		for _, tp := range trips {
+			if !seen[tp.Departure] {
+				seen[tp.Departure] = true
+				deps = append(deps, tp.Departure)
+			}
+		}
+	}
+	return deps
+}
+
+// subtractArrivals returns the arrivals in a that are not matched by an arrival in b,
+// counting duplicates.
+func subtractArrivals(a, b []string) []string {
+	left := map[string]int{}
+	for _, arr := range b {
+		left[arr]++
+	}
+	var rest []string
+	for _, arr := range a {
+		if left[arr] > 0 {
+			left[arr]--
+			continue
+		}
+		rest = append(rest, arr)
+	}
+	return rest
+}
+
+func tripsByCondition(svc ServiceData) map[string][]TimePair {
+	trips := map[string][]TimePair{}
+	for _, seg := range svc.Segments {
+		if seg.SegmentType == "fixed" {
+			cond := board.ConditionLabel(seg.Condition.scheduleCondition())
+			trips[cond] = append(trips[cond], seg.Times...)
+		}
+	}
+	return trips
+}
+
+func shuttlesByCondition(svc ServiceData) map[string][]string {
+	shuttles := map[string][]string{}
+	for _, seg := range svc.Segments {
+		if seg.SegmentType == "shuttle" {
+			cond := board.ConditionLabel(seg.Condition.scheduleCondition())
+			shuttles[cond] = append(shuttles[cond], fmt.Sprintf("%s〜%s (%s)", seg.StartTime, seg.EndTime, formatInterval(seg.Interval)))
+		}
+	}
+	return shuttles
+}
+
+func unionKeys[V any](a, b map[string]V) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, m := range []map[string]V{a, b} {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// printServiceDiff prints the change for one generated service and returns changeAdded,
+// changeModified or changeUnchanged.
+func printServiceDiff(outputDir string, svc ServiceData) (string, error) {
+	prev, err := loadExistingService(outputDir, svc.ID)
+	if err != nil {
+		return "", err
+	}
+	if prev == nil {
+		fmt.Printf("  [追加] %s.json\n", svc.ID)
+		return changeAdded, nil
+	}
+	lines := diffService(*prev, svc)
+	if len(lines) == 0 {
+		fmt.Printf("  [変更なし] %s.json\n", svc.ID)
+		return changeUnchanged, nil
+	}
+	fmt.Printf("  [変更] %s.json\n", svc.ID)
+	for _, l := range lines {
+		fmt.Printf("      %s\n", l)
+	}
+	return changeModified, nil
+}
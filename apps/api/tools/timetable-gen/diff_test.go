@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffService_Equal(t *testing.T) {
+	if got := diffService(validService(), validService()); len(got) != 0 {
+		t.Errorf("expected no diff, got %v", got)
+	}
+}
+
+func TestDiffService_Trips(t *testing.T) {
+	prev := validService()
+	next := validService()
+	next.Segments[0].Times[1] = TimePair{Departure: "8:00", Arrival: "8:25"}                                    // retimed
+	next.Segments[0].Times = append(next.Segments[0].Times[:4], TimePair{Departure: "10:00", Arrival: "10:20"}) // 9:30 removed, 10:00 added
+	next.ValidityPeriods[0].To = "2026-07-30"
+
+	got := strings.Join(diffService(prev, next), "\n")
+	for _, want := range []string{
+		"有効期間: 2026-04-07〜2026-07-29 → 2026-04-07〜2026-07-30",
+		"平日: 時刻変更 8:00→8:20 ⇒ 8:00→8:25",
+		"平日: 便追加 10:00→10:20",
+		"平日: 便削除 9:30→9:50",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("diff missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestDiffService_DuplicateDepartures(t *testing.T) {
+	// 同じ発車時刻で到着時刻の違う便（経路違い）を 1 便にまとめない
+	prev := validService()
+	prev.Segments[0].Times = []TimePair{
+		{Departure: "8:00", Arrival: "8:20"},
+		{Departure: "8:00", Arrival: "8:35"},
+	}
+	next := validService()
+	next.Segments[0].Times = []TimePair{
+		{Departure: "8:00", Arrival: "8:35"},
+		{Departure: "8:00", Arrival: "8:20"},
+	}
+	if got := diffService(prev, next); len(got) != 0 {
+		t.Errorf("reordered trips: expected no diff, got %v", got)
+	}
+
+	next.Segments[0].Times = []TimePair{{Departure: "8:00", Arrival: "8:35"}}
+	if got := diffService(prev, next); !reflect.DeepEqual(got, []string{"平日: 便削除 8:00→8:20"}) {
+		t.Errorf("removed trip: got %v", got)
+	}
+
+	next.Segments[0].Times = []TimePair{
+		{Departure: "8:00", Arrival: "8:35"},
+		{Departure: "8:00", Arrival: "8:35"},
+	}
+	if got := diffService(prev, next); !reflect.DeepEqual(got, []string{"平日: 時刻変更 8:00→8:20 ⇒ 8:00→8:35"}) {
+		t.Errorf("retimed trip: got %v", got)
+	}
+}
+
+func TestDiffService_Shuttle(t *testing.T) {
+	prev := validService()
+	next := validService()
+	next.Segments = append(next.Segments, ServiceSegment{
+		SegmentType: "shuttle",
+		Condition:   SegmentCondition{Type: "dayType", Value: "weekday"},
+		StartTime:   "9:30",
+		EndTime:     "12:00",
+		Interval:    &Interval{Min: 3, Max: 5},
+	})
+	got := strings.Join(diffService(prev, next), "\n")
+	if !strings.Contains(got, "シャトル追加 9:30〜12:00 (3〜5分)") {
+		t.Errorf("expected shuttle addition, got:\n%s", got)
+	}
+}
+
+func TestDiffExitCode(t *testing.T) {
+	tests := []struct {
+		failed, changed, want int
+	}{
+		{0, 0, exitNoChange},
+		{0, 3, exitChanged},
+		{1, 3, exitError},
+	}
+	for _, tt := range tests {
+		if got := diffExitCode(tt.failed, tt.changed); got != tt.want {
+			t.Errorf("diffExitCode(%d, %d) = %d, want %d", tt.failed, tt.changed, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateFromPDF_DryRun(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := filepath.Join(dir, "timetable.pdf")
+	if err := os.WriteFile(pdfPath, []byte("%PDF-fake"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outDir := filepath.Join(dir, "services")
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	extractors := []namedExtractor{{Label: "static", Extractor: staticExtractor{sampleExtracted()}}}
+
+	res := generateFromPDF(context.Background(), pdfPath, generateOptions{
+		Extractors: extractors,
+		OutputDir:  outDir,
+		DryRun:     true,
+	})
+	if res.Saved != 0 || res.Changed != 2 || res.Failed != 0 {
+		t.Errorf("dry-run on empty dir = %+v, want 2 changed and nothing saved", res)
+	}
+	if files, _ := filepath.Glob(filepath.Join(outDir, "*.json")); len(files) != 0 {
+		t.Errorf("dry-run must not write files, found %v", files)
+	}
+
+	// 書き込み後に同じ抽出結果で dry-run すると変更なし
+	generateFromPDF(context.Background(), pdfPath, generateOptions{Extractors: extractors, OutputDir: outDir})
+	res = generateFromPDF(context.Background(), pdfPath, generateOptions{
+		Extractors: extractors,
+		OutputDir:  outDir,
+		DryRun:     true,
+	})
+	if res.Changed != 0 || res.Failed != 0 {
+		t.Errorf("dry-run after write = %+v, want no changes", res)
+	}
+}
+
+func TestArchiveExpired_DryRun(t *testing.T) {
+	dir := t.TempDir()
+	svc := validService()
+	svc.ValidityPeriods = []ValidityPeriod{{From: "2020-04-01", To: "2020-07-29"}}
+	writeJSON(t, filepath.Join(dir, svc.ID+".json"), svc)
+
+	if n := archiveExpired(dir, archivePolicy{}, true, nil); n != 1 {
+		t.Fatalf("archiveExpired(dryRun) = %d, want 1", n)
+	}
+	if _, err := os.Stat(filepath.Join(dir, svc.ID+".json")); err != nil {
+		t.Errorf("dry-run must leave the file in place: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "archived")); !os.IsNotExist(err) {
+		t.Errorf("dry-run must not create archived/")
+	}
+}
@@ -9,8 +9,83 @@ import (
 	"time"
 
 	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/option"
 )
 
+// Extractor reads a timetable PDF and returns the raw tables it contains.
+// Implementations only read cells; column interpretation is left to mapper.go.
+type Extractor interface {
+	Extract(ctx context.Context, pdfData []byte) (*ExtractedData, error)
+}
+
+// Extractor backend names accepted by --extractor.
+const (
+	extractorGemini  = "gemini"
+	extractorFixture = "fixture"
+	extractorText    = "text"
+	// extractorAuto uses Gemini when an API key is available and the text layer otherwise.
+	extractorAuto = "auto"
+)
+
+// extractorOptions holds the flags used to select and configure an Extractor.
+type extractorOptions struct {
+	Backend    string
+	APIKey     string
+	FixtureDir string
+	CacheDir   string // empty disables the extraction cache
+	// CacheReadOnly uses cached results but never adds entries (--dry-run writes no files).
+	CacheReadOnly bool
+	ForceExtract  bool         // ignore cached results and extract again
+	Retry         retryPolicy  // zero value means defaultRetryPolicy
+	Limiter       *callLimiter // shared by every Gemini call in the run; nil means unlimited
+}
+
+// newExtractor builds the Extractor named by opts.Backend.
+// Backends that depend on a prompt and model are wrapped with the extraction cache
+// when opts.CacheDir is set.
+// The returned close function releases backend resources and is never nil.
+func newExtractor(ctx context.Context, opts extractorOptions) (Extractor, func(), error) {
+	if opts.Backend == extractorAuto {
+		opts.Backend = extractorText
+		if opts.APIKey != "" {
+			opts.Backend = extractorGemini
+		}
+		log.Printf("extractor 自動選択: %s", opts.Backend)
+	}
+
+	switch opts.Backend {
+	case extractorGemini, "":
+		g, err := NewGeminiExtractor(ctx, opts.APIKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		if opts.Retry.MaxAttempts > 0 {
+			g.retry = opts.Retry
+		}
+		g.limiter = opts.Limiter
+		return withCache(g, opts), func() { g.Close() }, nil
+	case extractorFixture:
+		f, err := NewFixtureExtractor(opts.FixtureDir)
+		if err != nil {
+			return nil, nil, err
+		}
+		return f, func() {}, nil
+	case extractorText:
+		return NewTextLayerExtractor(), func() {}, nil
+	default:
+		return nil, nil, fmt.Errorf("未知の extractor %q (gemini|fixture|text|auto)", opts.Backend)
+	}
+}
+
+func withCache(e identifiedExtractor, opts extractorOptions) Extractor {
+	if opts.CacheDir == "" {
+		return e
+	}
+	c := NewCachedExtractor(e, opts.CacheDir, opts.ForceExtract)
+	c.readOnly = opts.CacheReadOnly
+	return c
+}
+
 const extractionPrompt = `このPDFは東京工科大学スクールバスの時刻表です。全テーブルを抽出してください。
 
 1. ページ構成
@@ -120,62 +195,142 @@ func extractionSchema() *genai.Schema {
 	}
 }
 
-// Extract calls Gemini to extract raw table data from the PDF.
-// Gemini outputs structured intermediate data; column interpretation is left to mapper.go.
-func Extract(ctx context.Context, client *genai.Client, pdfData []byte) (*ExtractedData, error) {
-	model := client.GenerativeModel("gemini-3.1-pro-preview")
+// geminiModel is the Gemini model used for table extraction.
+const geminiModel = "gemini-3.1-pro-preview"
+
+// contentGenerator sends the prompt and PDF to a model and returns the raw text response.
+// GeminiExtractor talks to it instead of genai directly so tests can use a fake backend.
+type contentGenerator interface {
+	Generate(ctx context.Context, prompt string, pdfData []byte) (string, error)
+}
+
+// genaiGenerator is the contentGenerator backed by the Gemini API.
+type genaiGenerator struct {
+	client *genai.Client
+	model  string
+}
+
+func (g genaiGenerator) Generate(ctx context.Context, prompt string, pdfData []byte) (string, error) {
+	model := g.client.GenerativeModel(g.model)
 	model.ResponseMIMEType = "application/json"
 	model.ResponseSchema = extractionSchema()
 
-	const maxRetries = 3
-	const maxRateLimitRetries = 5
-	rateLimitCount := 0
+	resp, err := model.GenerateContent(ctx,
+		genai.Text(prompt),
+		genai.Blob{MIMEType: "application/pdf", Data: pdfData},
+	)
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
+		return "", nil
+	}
+	var raw strings.Builder
+	for _, part := range resp.Candidates[0].Content.Parts {
+		if txt, ok := part.(genai.Text); ok {
+			raw.WriteString(string(txt))
+		}
+	}
+	return raw.String(), nil
+}
+
+// GeminiExtractor extracts tables by sending the PDF to Gemini.
+type GeminiExtractor struct {
+	client  *genai.Client
+	gen     contentGenerator
+	model   string
+	retry   retryPolicy
+	limiter *callLimiter // shared by every PDF in the run; may be nil
+}
+
+// NewGeminiExtractor creates a Gemini client for the given API key.
+// The caller must call Close when done.
+func NewGeminiExtractor(ctx context.Context, apiKey string) (*GeminiExtractor, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("GEMINI_API_KEY を設定するか --api-key を指定してください")
+	}
+	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
+	if err != nil {
+		return nil, fmt.Errorf("Gemini クライアント作成失敗: %w", err)
+	}
+	return &GeminiExtractor{
+		client: client,
+		gen:    genaiGenerator{client: client, model: geminiModel},
+		model:  geminiModel,
+		retry:  defaultRetryPolicy,
+	}, nil
+}
+
+func (g *GeminiExtractor) Close() error {
+	if g.client == nil {
+		return nil
+	}
+	return g.client.Close()
+}
+
+// Identity implements identifiedExtractor. The hash covers the prompt and response schema,
+// so editing either invalidates cached extractions.
+func (g *GeminiExtractor) Identity() (model, promptHash string) {
+	schema, _ := json.Marshal(extractionSchema())
+	return g.model, sha256sum(append([]byte(extractionPrompt), schema...))[:16]
+}
+
+// Extract calls Gemini to extract raw table data from the PDF.
+// Gemini outputs structured intermediate data; column interpretation is left to mapper.go.
+//
+// Failed attempts are retried up to g.retry.MaxAttempts with jittered exponential backoff.
+// API errors classified as permanent (bad request, auth) end the retries immediately;
+// unusable responses (empty, invalid JSON, missing validFrom) are retried with a hint.
+func (g *GeminiExtractor) Extract(ctx context.Context, pdfData []byte) (*ExtractedData, error) {
+	maxAttempts := g.retry.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
 	var retryHint string
-	for attempt := 1; attempt <= maxRetries; attempt++ {
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
 		if attempt > 1 {
-			log.Printf("リトライ %d/%d...", attempt, maxRetries)
-			time.Sleep(2 * time.Second)
+			d := g.retry.backoff(attempt, lastErr != nil && isRateLimitError(lastErr))
+			log.Printf("リトライ %d/%d (%s 待機)...", attempt, maxAttempts, d.Round(time.Millisecond))
+			if err := g.retry.wait(ctx, d); err != nil {
+				return nil, err
+			}
 		}
-		log.Printf("Gemini 呼び出し中 (試行 %d/%d)...", attempt, maxRetries)
+		log.Printf("Gemini 呼び出し中 (試行 %d/%d)...", attempt, maxAttempts)
 
 		prompt := extractionPrompt
 		if retryHint != "" {
 			prompt += "\n\n【前回の問題点】\n" + retryHint
 		}
 
-		resp, err := model.GenerateContent(ctx,
-			genai.Text(prompt),
-			genai.Blob{MIMEType: "application/pdf", Data: pdfData},
-		)
+		release, err := g.limiter.acquire(ctx)
 		if err != nil {
+			return nil, err
+		}
+		raw, err := g.gen.Generate(ctx, prompt, pdfData)
+		release()
+		if err != nil {
+			if !isRetryable(err) {
+				return nil, fmt.Errorf("API エラー (リトライ不可): %w", err)
+			}
 			if isRateLimitError(err) {
-				rateLimitCount++
-				if rateLimitCount > maxRateLimitRetries {
-					return nil, fmt.Errorf("レートリミットが %d 回連続しました", rateLimitCount)
-				}
-				log.Printf("レートリミット検出 (%d 回目)、10秒待機...", rateLimitCount)
-				time.Sleep(10 * time.Second)
+				log.Printf("レートリミット検出 (試行 %d): %v", attempt, err)
+			} else {
+				log.Printf("API エラー (試行 %d): %v", attempt, err)
 			}
-			log.Printf("API エラー (試行 %d): %v", attempt, err)
+			lastErr = err
 			continue
 		}
+		lastErr = nil
 
-		if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
+		if raw == "" {
 			log.Printf("空レスポンス (試行 %d)", attempt)
 			continue
 		}
-
-		var raw strings.Builder
-		for _, part := range resp.Candidates[0].Content.Parts {
-			if txt, ok := part.(genai.Text); ok {
-				raw.WriteString(string(txt))
-			}
-		}
-
-		log.Printf("受信: %d bytes", raw.Len())
+		log.Printf("受信: %d bytes", len(raw))
 
 		var extracted ExtractedData
-		if err := json.Unmarshal([]byte(raw.String()), &extracted); err != nil {
+		if err := json.Unmarshal([]byte(raw), &extracted); err != nil {
 			log.Printf("JSON パース失敗 (試行 %d): %v", attempt, err)
 			continue
 		}
@@ -201,12 +356,8 @@ func Extract(ctx context.Context, client *genai.Client, pdfData []byte) (*Extrac
 		return &extracted, nil
 	}
 
-	return nil, fmt.Errorf("全ての試行が失敗しました (%d回)", maxRetries)
-}
-
-func isRateLimitError(err error) bool {
-	msg := strings.ToLower(err.Error())
-	return strings.Contains(msg, "429") ||
-		strings.Contains(msg, "rate limit") ||
-		strings.Contains(msg, "quota exceeded")
+	if lastErr != nil {
+		return nil, fmt.Errorf("全ての試行が失敗しました (%d回): %w", maxAttempts, lastErr)
+	}
+	return nil, fmt.Errorf("全ての試行が失敗しました (%d回)", maxAttempts)
 }
@@ -11,14 +11,15 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 )
 
 const (
-	timetablePageURL = "https://www.teu.ac.jp/campus/access/006644.html"
-	baseURL          = "https://www.teu.ac.jp"
-	stateFileName    = ".fetch-state.json"
+	defaultBaseURL    = "https://www.teu.ac.jp"
+	timetablePagePath = "/campus/access/006644.html"
+	stateFileName     = ".fetch-state.json"
 )
 
 var httpClient = &http.Client{Timeout: 30 * time.Second}
@@ -27,15 +28,29 @@ type PDFState struct {
 	Title        string `json:"title"`
 	SHA256       string `json:"sha256"`
 	DownloadedAt string `json:"downloadedAt"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	RemovedAt    string `json:"removedAt,omitempty"` // set when the link disappeared from the page
+}
+
+// PageState is the last seen version of the timetable page itself.
+type PageState struct {
+	URL          string    `json:"url"`
+	SHA256       string    `json:"sha256"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	CheckedAt    string    `json:"checkedAt"`
+	Links        []PDFLink `json:"links"` // PDF links on the page as last downloaded
 }
 
 type FetchState struct {
+	Page PageState           `json:"page"`
 	PDFs map[string]PDFState `json:"pdfs"` // keyed by relative URL
 }
 
 type PDFLink struct {
-	URL   string // relative URL e.g. /campus/access/260407.pdf
-	Title string
+	URL   string `json:"url"` // relative URL e.g. /campus/access/260407.pdf
+	Title string `json:"title"`
 }
 
 // rePDFLink matches <a href="...pdf"...>title</a> (not inside HTML comments)
@@ -46,60 +61,150 @@ type DownloadedPDF struct {
 	Title string
 }
 
+// Fetcher downloads the timetable page and the PDFs it links to. BaseURL is prepended to the
+// page's root-relative PDF links; tests point both URLs at an httptest server.
+type Fetcher struct {
+	Client  *http.Client
+	BaseURL string
+	PageURL string
+}
+
+// newFetcher returns a Fetcher for baseURL. An empty pageURL means the timetable page on baseURL.
+func newFetcher(baseURL, pageURL string) *Fetcher {
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	if pageURL == "" {
+		pageURL = baseURL + timetablePagePath
+	}
+	return &Fetcher{Client: httpClient, BaseURL: baseURL, PageURL: pageURL}
+}
+
 func runFetch(args []string) {
 	outputDir := "downloaded"
+	reportPath := ""
+	baseURL, pageURL := "", ""
 	for i, a := range args {
-		if a == "--output" && i+1 < len(args) {
-			outputDir = args[i+1]
+		switch a {
+		case "--output":
+			if i+1 < len(args) {
+				outputDir = args[i+1]
+			}
+		case "--report":
+			if i+1 < len(args) {
+				reportPath = args[i+1]
+			}
+		case "--base-url":
+			if i+1 < len(args) {
+				baseURL = args[i+1]
+			}
+		case "--page-url":
+			if i+1 < len(args) {
+				pageURL = args[i+1]
+			}
 		}
 	}
 
-	newFiles, skipped, err := fetchNewPDFs(outputDir)
+	run := newRunReport("fetch", reportPath, false)
+	newFiles, skipped, err := newFetcher(baseURL, pageURL).fetchNewPDFs(outputDir, outputDir, false, run)
 	if err != nil {
-		log.Fatalf("fetch 失敗: %v", err)
+		run.fatalf("fetch 失敗: %v", err)
 	}
 
 	fmt.Printf("\nダウンロード: %d 件 / スキップ: %d 件\n", len(newFiles), skipped)
 	fmt.Printf("保存先: %s\n", outputDir)
+	run.finish(exitNoChange)
 }
 
-// fetchNewPDFs は新規・更新 PDF をダウンロードし、そのパス一覧を返す。
-func fetchNewPDFs(outputDir string) (newFiles []DownloadedPDF, skipped int, err error) {
-	if err = os.MkdirAll(outputDir, 0755); err != nil {
+// keepPDFState は取得に失敗したリンクについて、タイトルの変更と再掲載だけを状態に反映する。
+// 次回の実行で同じ変更を報告し直さないようにするため。新規のリンクは次回も新規として扱うので記録しない。
+func keepPDFState(state *FetchState, url string, prev PDFState, known bool) {
+	if known {
+		state.PDFs[url] = prev
+	}
+}
+
+// fetchNewPDFs は新規・更新 PDF を saveDir にダウンロードし、そのパス一覧を返す。
+// 前回の取得状態は stateDir の状態ファイルから読む。
+// ページ・PDF とも前回の ETag / Last-Modified で条件付きリクエストを送り、304 なら再取得しない。
+// ページから消えたリンク・タイトルが変わったリンクも報告する。
+// dryRun の場合は状態ファイルを更新せず、stateDir には何も書き込まない（saveDir には一時ディレクトリを渡す）。
+// 検出した PDF はすべて run に記録する。
+func (f *Fetcher) fetchNewPDFs(stateDir, saveDir string, dryRun bool, run *RunReport) (newFiles []DownloadedPDF, skipped int, err error) {
+	if err = os.MkdirAll(saveDir, 0755); err != nil {
 		return nil, 0, fmt.Errorf("出力ディレクトリ作成失敗: %w", err)
 	}
 
-	links, err := scrapePDFLinks()
+	stateFile := filepath.Join(stateDir, stateFileName)
+	state := loadFetchState(stateFile)
+
+	links, err := f.scrapePDFLinks(&state)
 	if err != nil {
 		return nil, 0, fmt.Errorf("ページ取得失敗: %w", err)
 	}
 	log.Printf("PDF リンク検出: %d 件", len(links))
 
-	stateFile := filepath.Join(outputDir, stateFileName)
-	state := loadFetchState(stateFile)
+	reportRemovedLinks(&state, links, run)
 
 	for _, link := range links {
-		fullURL := baseURL + link.URL
+		fullURL := f.BaseURL + link.URL
 		filename := filepath.Base(link.URL)
-		outPath := filepath.Join(outputDir, filename)
+		outPath := filepath.Join(saveDir, filename)
 
-		data, dlErr := downloadFile(fullURL)
+		rec := PDFRecord{URL: link.URL, Title: link.Title}
+		prev, known := state.PDFs[link.URL]
+		if known && prev.Title != link.Title {
+			fmt.Printf("  [タイトル変更] %s: %s → %s\n", filename, prev.Title, link.Title)
+			rec.PreviousTitle = prev.Title
+			prev.Title = link.Title
+		}
+		if known && prev.RemovedAt != "" {
+			fmt.Printf("  [再掲載] %s  (%s)\n", filename, link.Title)
+			prev.RemovedAt = ""
+		}
+
+		var cond conditional
+		if known {
+			cond = conditional{ETag: prev.ETag, LastModified: prev.LastModified}
+		}
+		resp, dlErr := f.get(fullURL, cond)
 		if dlErr != nil {
 			log.Printf("ダウンロード失敗 %s: %v", fullURL, dlErr)
+			rec.Status, rec.Error = pdfFailed, dlErr.Error()
+			run.addPDF(rec)
+			keepPDFState(&state, link.URL, prev, known)
 			continue
 		}
 
-		hash := sha256sum(data)
-		prev, known := state.PDFs[link.URL]
+		if resp.NotModified {
+			log.Printf("変更なし (304): %s", filename)
+			rec.SHA256 = prev.SHA256
+			rec.Status = pdfUnchanged
+			run.addPDF(rec)
+			state.PDFs[link.URL] = prev
+			skipped++
+			continue
+		}
+
+		hash := sha256sum(resp.Body)
+		rec.SHA256 = hash
 
 		if known && prev.SHA256 == hash {
 			log.Printf("変更なし: %s", filename)
+			prev.ETag, prev.LastModified = resp.ETag, resp.LastModified
+			state.PDFs[link.URL] = prev
+			rec.Status = pdfUnchanged
+			run.addPDF(rec)
 			skipped++
 			continue
 		}
 
-		if writeErr := os.WriteFile(outPath, data, 0644); writeErr != nil {
+		if writeErr := os.WriteFile(outPath, resp.Body, 0644); writeErr != nil {
 			log.Printf("保存失敗 %s: %v", outPath, writeErr)
+			rec.Status, rec.Error = pdfFailed, writeErr.Error()
+			run.addPDF(rec)
+			keepPDFState(&state, link.URL, prev, known)
 			continue
 		}
 
@@ -107,16 +212,28 @@ func fetchNewPDFs(outputDir string) (newFiles []DownloadedPDF, skipped int, err
 			Title:        link.Title,
 			SHA256:       hash,
 			DownloadedAt: time.Now().Format(time.RFC3339),
+			ETag:         resp.ETag,
+			LastModified: resp.LastModified,
 		}
 
 		action := "新規"
+		rec.Status = pdfNew
 		if known {
 			action = "更新"
+			rec.Status = pdfUpdated
 		}
+		rec.Path = outPath
+		run.addPDF(rec)
 		fmt.Printf("  [%s] %s  (%s)\n", action, filename, link.Title)
 		newFiles = append(newFiles, DownloadedPDF{Path: outPath, Title: link.Title})
 	}
 
+	if dryRun {
+		return newFiles, skipped, nil
+	}
+	if err = os.MkdirAll(stateDir, 0755); err != nil {
+		return nil, 0, fmt.Errorf("出力ディレクトリ作成失敗: %w", err)
+	}
 	if saveErr := saveFetchState(stateFile, state); saveErr != nil {
 		log.Printf("状態ファイル保存失敗: %v", saveErr)
 	}
@@ -124,20 +241,59 @@ func fetchNewPDFs(outputDir string) (newFiles []DownloadedPDF, skipped int, err
 	return newFiles, skipped, nil
 }
 
-func scrapePDFLinks() ([]PDFLink, error) {
-	resp, err := httpClient.Get(timetablePageURL)
+// reportRemovedLinks marks known PDFs that are no longer linked from the page as removed.
+// Their state is kept so that a link that comes back is not treated as new.
+func reportRemovedLinks(state *FetchState, links []PDFLink, run *RunReport) {
+	current := map[string]bool{}
+	for _, l := range links {
+		current[l.URL] = true
+	}
+	var urls []string
+	for url := range state.PDFs {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+
+	for _, url := range urls {
+		ps := state.PDFs[url]
+		if current[url] || ps.RemovedAt != "" {
+			continue
+		}
+		fmt.Printf("  [削除] %s  (%s)\n", filepath.Base(url), ps.Title)
+		ps.RemovedAt = time.Now().Format(time.RFC3339)
+		state.PDFs[url] = ps
+		run.addPDF(PDFRecord{URL: url, Title: ps.Title, SHA256: ps.SHA256, Status: pdfRemoved})
+	}
+}
+
+// scrapePDFLinks fetches the timetable page and returns its PDF links. When the page answers
+// 304 the links recorded in state for the unchanged page are returned.
+func (f *Fetcher) scrapePDFLinks(state *FetchState) ([]PDFLink, error) {
+	var cond conditional
+	if state.Page.URL == f.PageURL {
+		cond = conditional{ETag: state.Page.ETag, LastModified: state.Page.LastModified}
+	}
+	resp, err := f.get(f.PageURL, cond)
 	if err != nil {
 		return nil, fmt.Errorf("HTTP GET 失敗: %w", err)
 	}
-	defer resp.Body.Close()
+	state.Page.URL = f.PageURL
+	state.Page.CheckedAt = time.Now().Format(time.RFC3339)
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("レスポンス読み込み失敗: %w", err)
+	if resp.NotModified {
+		log.Printf("ページ変更なし (304)")
+		return state.Page.Links, nil
 	}
 
+	state.Page.SHA256 = sha256sum(resp.Body)
+	state.Page.ETag, state.Page.LastModified = resp.ETag, resp.LastModified
+	state.Page.Links = parsePDFLinks(string(resp.Body))
+	return state.Page.Links, nil
+}
+
+func parsePDFLinks(html string) []PDFLink {
 	// HTML コメントを除去してからリンクを抽出（コメントアウト済みリンクを無視）
-	stripped := removeHTMLComments(string(body))
+	stripped := removeHTMLComments(html)
 
 	matches := rePDFLink.FindAllStringSubmatch(stripped, -1)
 	var links []PDFLink
@@ -153,7 +309,7 @@ func scrapePDFLinks() ([]PDFLink, error) {
 			Title: strings.TrimSpace(m[2]),
 		})
 	}
-	return links, nil
+	return links
 }
 
 // removeHTMLComments は <!-- ... --> を除去する
@@ -165,12 +321,41 @@ func removeHTMLComments(html string) string {
 
 const maxBodyBytes = 50 * 1024 * 1024 // 50 MB
 
-func downloadFile(url string) ([]byte, error) {
-	resp, err := httpClient.Get(url)
+// conditional holds the validators from a previous response.
+type conditional struct {
+	ETag         string
+	LastModified string
+}
+
+type fetchResponse struct {
+	NotModified  bool
+	Body         []byte
+	ETag         string
+	LastModified string
+}
+
+// get performs a GET, sending If-None-Match / If-Modified-Since from cond when set.
+func (f *Fetcher) get(url string, cond conditional) (*fetchResponse, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if cond.ETag != "" {
+		req.Header.Set("If-None-Match", cond.ETag)
+	}
+	if cond.LastModified != "" {
+		req.Header.Set("If-Modified-Since", cond.LastModified)
+	}
+
+	resp, err := f.Client.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return &fetchResponse{NotModified: true}, nil
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
 	}
@@ -181,7 +366,11 @@ func downloadFile(url string) ([]byte, error) {
 	if int64(len(data)) > maxBodyBytes {
 		return nil, fmt.Errorf("ファイルサイズが %d MB を超えています", maxBodyBytes/1024/1024)
 	}
-	return data, nil
+	return &fetchResponse{
+		Body:         data,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}, nil
 }
 
 func sha256sum(data []byte) string {
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeSite is an httptest stand-in for the university access page and its PDFs.
+// It honours If-None-Match and counts full (200) responses per path.
+type fakeSite struct {
+	mu   sync.Mutex
+	page string
+	pdfs map[string]string // path → content
+	full map[string]int
+}
+
+func newFakeSite(links map[string]string) *fakeSite {
+	s := &fakeSite{pdfs: map[string]string{}, full: map[string]int{}}
+	s.setLinks(links)
+	return s
+}
+
+// setLinks rewrites the page to link to path → title, creating PDFs that do not exist yet.
+func (s *fakeSite) setLinks(links map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var b strings.Builder
+	b.WriteString("<html><body>\n<!-- <a href=\"/campus/access/old.pdf\">旧時刻表</a> -->\n")
+	for path, title := range links {
+		fmt.Fprintf(&b, "<a href=\"%s\" target=\"_blank\">%s</a>\n", path, title)
+		if _, ok := s.pdfs[path]; !ok {
+			s.pdfs[path] = "%PDF-" + path
+		}
+	}
+	b.WriteString("</body></html>")
+	s.page = b.String()
+}
+
+func (s *fakeSite) setPDF(path, content string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pdfs[path] = content
+}
+
+func (s *fakeSite) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var body string
+	if r.URL.Path == timetablePagePath {
+		body = s.page
+	} else if c, ok := s.pdfs[r.URL.Path]; ok {
+		body = c
+	} else {
+		http.NotFound(w, r)
+		return
+	}
+	etag := `"` + sha256sum([]byte(body))[:16] + `"`
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	s.full[r.URL.Path]++
+	w.Header().Set("ETag", etag)
+	fmt.Fprint(w, body)
+}
+
+func (s *fakeSite) fullCount(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.full[path]
+}
+
+func TestFetchNewPDFs_ConditionalRequests(t *testing.T) {
+	site := newFakeSite(map[string]string{"/campus/access/260407.pdf": "2026年度前期"})
+	srv := httptest.NewServer(site)
+	defer srv.Close()
+	f := newFetcher(srv.URL, "")
+	dir := t.TempDir()
+
+	newFiles, skipped, err := f.fetchNewPDFs(dir, dir, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(newFiles) != 1 || skipped != 0 {
+		t.Fatalf("first fetch = (%d new, %d skipped), want (1, 0)", len(newFiles), skipped)
+	}
+
+	run := newRunReport("fetch", "", false)
+	newFiles, skipped, err = f.fetchNewPDFs(dir, dir, false, run)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(newFiles) != 0 || skipped != 1 {
+		t.Errorf("second fetch = (%d new, %d skipped), want (0, 1)", len(newFiles), skipped)
+	}
+	if n := site.fullCount(timetablePagePath); n != 1 {
+		t.Errorf("page downloaded %d times, want 1 (second request should be 304)", n)
+	}
+	if n := site.fullCount("/campus/access/260407.pdf"); n != 1 {
+		t.Errorf("PDF downloaded %d times, want 1 (second request should be 304)", n)
+	}
+	if len(run.PDFs) != 1 || run.PDFs[0].Status != pdfUnchanged {
+		t.Errorf("report pdfs = %+v, want one unchanged", run.PDFs)
+	}
+
+	site.setPDF("/campus/access/260407.pdf", "%PDF-revised")
+	newFiles, _, err = f.fetchNewPDFs(dir, dir, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(newFiles) != 1 {
+		t.Errorf("changed PDF should be downloaded again, got %d new", len(newFiles))
+	}
+}
+
+func TestFetchNewPDFs_RemovedAndRetitledLinks(t *testing.T) {
+	site := newFakeSite(map[string]string{
+		"/campus/access/260407.pdf": "2026年度前期",
+		"/campus/access/260601.pdf": "6月臨時ダイヤ",
+	})
+	srv := httptest.NewServer(site)
+	defer srv.Close()
+	f := newFetcher(srv.URL, "")
+	dir := t.TempDir()
+
+	if _, _, err := f.fetchNewPDFs(dir, dir, false, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	site.setLinks(map[string]string{"/campus/access/260407.pdf": "2026年度前期（改訂）"})
+	run := newRunReport("fetch", "", false)
+	if _, _, err := f.fetchNewPDFs(dir, dir, false, run); err != nil {
+		t.Fatal(err)
+	}
+
+	byURL := map[string]PDFRecord{}
+	for _, p := range run.PDFs {
+		byURL[p.URL] = p
+	}
+	if p := byURL["/campus/access/260601.pdf"]; p.Status != pdfRemoved {
+		t.Errorf("260601.pdf status = %q, want removed", p.Status)
+	}
+	if p := byURL["/campus/access/260407.pdf"]; p.PreviousTitle != "2026年度前期" || p.Title != "2026年度前期（改訂）" {
+		t.Errorf("260407.pdf title = %q (previous %q), want retitled", p.Title, p.PreviousTitle)
+	}
+
+	state := loadFetchState(dir + "/" + stateFileName)
+	if state.PDFs["/campus/access/260601.pdf"].RemovedAt == "" {
+		t.Error("removed link should be marked in state")
+	}
+	if state.PDFs["/campus/access/260407.pdf"].Title != "2026年度前期（改訂）" {
+		t.Error("retitled link should be updated in state")
+	}
+
+	// 3 回目は削除済みを再報告しない
+	run = newRunReport("fetch", "", false)
+	if _, _, err := f.fetchNewPDFs(dir, dir, false, run); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range run.PDFs {
+		if p.Status == pdfRemoved {
+			t.Errorf("removed link reported again: %+v", p)
+		}
+	}
+}
+
+func TestFetchNewPDFs_RetitleReportedOnceWhenDownloadFails(t *testing.T) {
+	const path = "/campus/access/260407.pdf"
+	site := newFakeSite(map[string]string{path: "2026年度前期"})
+	srv := httptest.NewServer(site)
+	defer srv.Close()
+	f := newFetcher(srv.URL, "")
+	dir := t.TempDir()
+
+	if _, _, err := f.fetchNewPDFs(dir, dir, false, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// タイトルが変わり、PDF の取得には失敗する
+	site.setLinks(map[string]string{path: "2026年度前期（改訂）"})
+	site.mu.Lock()
+	delete(site.pdfs, path)
+	site.mu.Unlock()
+	run := newRunReport("fetch", "", false)
+	if _, _, err := f.fetchNewPDFs(dir, dir, false, run); err != nil {
+		t.Fatal(err)
+	}
+	if len(run.PDFs) != 1 || run.PDFs[0].Status != pdfFailed || run.PDFs[0].PreviousTitle != "2026年度前期" {
+		t.Fatalf("report pdfs = %+v, want one failed retitled PDF", run.PDFs)
+	}
+
+	state := loadFetchState(filepath.Join(dir, stateFileName))
+	if got := state.PDFs[path]; got.Title != "2026年度前期（改訂）" || got.SHA256 == "" {
+		t.Errorf("state = %+v, want the new title and the previous download", got)
+	}
+
+	// 次の実行でタイトル変更を報告し直さない
+	run = newRunReport("fetch", "", false)
+	if _, _, err := f.fetchNewPDFs(dir, dir, false, run); err != nil {
+		t.Fatal(err)
+	}
+	if len(run.PDFs) != 1 || run.PDFs[0].PreviousTitle != "" {
+		t.Errorf("report pdfs = %+v, want the title change not reported again", run.PDFs)
+	}
+}
+
+func TestFetchNewPDFs_DryRunKeepsState(t *testing.T) {
+	site := newFakeSite(map[string]string{"/campus/access/260407.pdf": "2026年度前期"})
+	srv := httptest.NewServer(site)
+	defer srv.Close()
+	f := newFetcher(srv.URL, "")
+	dir := filepath.Join(t.TempDir(), "downloaded")
+	tmp := t.TempDir()
+
+	for i := 0; i < 2; i++ {
+		newFiles, _, err := f.fetchNewPDFs(dir, tmp, true, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(newFiles) != 1 {
+			t.Fatalf("dry-run %d: got %d new, want 1 (state must not be saved)", i+1, len(newFiles))
+		}
+		if filepath.Dir(newFiles[0].Path) != tmp {
+			t.Errorf("dry-run %d: PDF saved to %s, want the temporary directory", i+1, newFiles[0].Path)
+		}
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("dry-run created %s (err = %v)", dir, err)
+	}
+}
+
+func TestNewFetcher_URLs(t *testing.T) {
+	f := newFetcher("", "")
+	if f.BaseURL != defaultBaseURL || f.PageURL != defaultBaseURL+timetablePagePath {
+		t.Errorf("defaults = (%q, %q)", f.BaseURL, f.PageURL)
+	}
+	f = newFetcher("http://localhost:8080/", "http://localhost:8080/other.html")
+	if f.BaseURL != "http://localhost:8080" || f.PageURL != "http://localhost:8080/other.html" {
+		t.Errorf("custom = (%q, %q)", f.BaseURL, f.PageURL)
+	}
+}
+
+func TestParsePDFLinks_IgnoresComments(t *testing.T) {
+	links := parsePDFLinks(`<!-- <a href="/campus/access/old.pdf">旧</a> -->
+<a href="/campus/access/new.pdf">新 </a><a href="/campus/access/new.pdf">重複</a>`)
+	if len(links) != 1 || links[0].URL != "/campus/access/new.pdf" || links[0].Title != "新" {
+		t.Errorf("links = %+v", links)
+	}
+}
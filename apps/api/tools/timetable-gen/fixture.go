@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FixtureExtractor replays previously extracted ExtractedData JSON instead of
+// reading the PDF. It makes the PDF→JSON pipeline runnable offline and deterministic.
+//
+// If Path is a directory, the fixture for a PDF is looked up as <Path>/<sha256 of PDF>.json.
+// If Path is a file, that file is returned for every PDF.
+type FixtureExtractor struct {
+	Path string
+}
+
+func NewFixtureExtractor(path string) (*FixtureExtractor, error) {
+	if path == "" {
+		return nil, fmt.Errorf("fixture extractor には --fixture-dir の指定が必要です")
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("fixture が見つかりません: %w", err)
+	}
+	return &FixtureExtractor{Path: path}, nil
+}
+
+func (f *FixtureExtractor) Extract(_ context.Context, pdfData []byte) (*ExtractedData, error) {
+	path, err := f.fixturePath(pdfData)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("fixture 読み込み失敗: %w", err)
+	}
+	var extracted ExtractedData
+	if err := json.Unmarshal(data, &extracted); err != nil {
+		return nil, fmt.Errorf("fixture パース失敗 %s: %w", path, err)
+	}
+	if len(extracted.Tables) == 0 {
+		return nil, fmt.Errorf("fixture %s にテーブルがありません", path)
+	}
+	return &extracted, nil
+}
+
+func (f *FixtureExtractor) fixturePath(pdfData []byte) (string, error) {
+	info, err := os.Stat(f.Path)
+	if err != nil {
+		return "", fmt.Errorf("fixture が見つかりません: %w", err)
+	}
+	if !info.IsDir() {
+		return f.Path, nil
+	}
+	return filepath.Join(f.Path, sha256sum(pdfData)+".json"), nil
+}
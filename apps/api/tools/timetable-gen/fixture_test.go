@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// sampleExtracted returns a minimal one-table extraction that maps to two valid services.
+func sampleExtracted() *ExtractedData {
+	year := time.Now().Year()
+	return &ExtractedData{Tables: []ExtractedTable{{
+		StationName: "八王子駅",
+		DayType:     "weekday",
+		ValidFrom:   fmt.Sprintf("%d-04-07", year),
+		ValidTo:     fmt.Sprintf("%d-07-29", year),
+		Segments: []ExtractedSegment{{
+			Type: "fixed",
+			Rows: [][]string{
+				{"7:30", "7:50", "8:10"},
+				{"8:00", "8:20", "8:40"},
+				{"8:30", "8:50", "9:10"},
+				{"～", "～", "～", "約3〜5分間隔"},
+				{"10:00", "10:20", "10:40"},
+				{"11:00", "11:20", "11:40"},
+				{"12:00", "12:20", "12:40"},
+			},
+		}},
+	}}}
+}
+
+func writeJSON(t *testing.T, path string, v interface{}) {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFixtureExtractor_DirKeyedByHash(t *testing.T) {
+	dir := t.TempDir()
+	pdf := []byte("%PDF-fake")
+	writeJSON(t, filepath.Join(dir, sha256sum(pdf)+".json"), sampleExtracted())
+
+	ext, err := NewFixtureExtractor(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ext.Extract(context.Background(), pdf)
+	if err != nil {
+		t.Fatalf("Extract error: %v", err)
+	}
+	if len(got.Tables) != 1 || got.Tables[0].StationName != "八王子駅" {
+		t.Errorf("unexpected tables: %+v", got.Tables)
+	}
+
+	if _, err := ext.Extract(context.Background(), []byte("other")); err == nil {
+		t.Error("expected error for PDF without fixture, got nil")
+	}
+}
+
+func TestFixtureExtractor_SingleFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "extracted.json")
+	writeJSON(t, path, sampleExtracted())
+
+	ext, err := NewFixtureExtractor(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ext.Extract(context.Background(), []byte("any pdf")); err != nil {
+		t.Errorf("Extract error: %v", err)
+	}
+}
+
+func TestNewExtractor_UnknownBackend(t *testing.T) {
+	if _, _, err := newExtractor(context.Background(), extractorOptions{Backend: "ocr"}); err == nil {
+		t.Error("expected error for unknown backend, got nil")
+	}
+}
+
+func TestGenerateFromPDF_Fixture(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := filepath.Join(dir, "timetable.pdf")
+	if err := os.WriteFile(pdfPath, []byte("%PDF-fake"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fixture := filepath.Join(dir, "extracted.json")
+	writeJSON(t, fixture, sampleExtracted())
+	outDir := filepath.Join(dir, "services")
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ext, err := NewFixtureExtractor(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := generateFromPDF(context.Background(), pdfPath, generateOptions{
+		Extractors: []namedExtractor{{Label: "fixture", Extractor: ext}},
+		OutputDir:  outDir,
+	})
+	if res.Saved != 2 || res.Failed != 0 {
+		t.Fatalf("generateFromPDF = (%d, %d), want (2, 0)", res.Saved, res.Failed)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(outDir, "*.json"))
+	if len(files) != 2 {
+		t.Errorf("expected 2 output files, got %v", files)
+	}
+}
@@ -8,11 +8,7 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"path/filepath"
 	"strings"
-
-	"github.com/google/generative-ai-go/genai"
-	"google.golang.org/api/option"
 )
 
 func main() {
@@ -22,6 +18,18 @@ func main() {
 		runView(os.Args[2:])
 		return
 	}
+	if len(os.Args) > 1 && os.Args[1] == "print" {
+		runPrint(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "export-csv" {
+		runExportCSV(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "import-csv" {
+		runImportCSV(os.Args[2:])
+		return
+	}
 	if len(os.Args) > 1 && os.Args[1] == "fetch" {
 		runFetch(os.Args[2:])
 		return
@@ -30,88 +38,121 @@ func main() {
 		runSync(os.Args[2:])
 		return
 	}
+	if len(os.Args) > 1 && os.Args[1] == "archive" {
+		runArchive(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "unarchive" {
+		runUnarchive(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "review" {
+		runReview(os.Args[2:])
+		return
+	}
 
 	pdfPath := flag.String("pdf", "", "PDFファイルのパス (必須)")
 	outputDir := flag.String("output", "../../data/services", "出力ディレクトリ")
 	apiKey := flag.String("api-key", os.Getenv("GEMINI_API_KEY"), "Gemini API Key")
+	backend := flag.String("extractor", extractorGemini, "抽出バックエンド (gemini|fixture|text|auto)")
+	fixtureDir := flag.String("fixture-dir", "", "fixture extractor が読む ExtractedData JSON (ファイルまたはディレクトリ)")
+	cacheDir := flag.String("cache-dir", defaultCacheDir, "抽出キャッシュの保存先 (空文字で無効)")
+	forceExtract := flag.Bool("force-extract", false, "抽出キャッシュを無視して再抽出する")
+	runs := flag.Int("runs", 1, "抽出を繰り返す回数 (2 以上で結果が一致したサービスのみ出力)")
+	maxAttempts := flag.Int("max-attempts", defaultRetryPolicy.MaxAttempts, "Gemini 呼び出しの最大試行回数")
+	retryDelay := flag.Duration("retry-delay", defaultRetryPolicy.BaseDelay, "リトライ待機の初期値 (試行ごとに倍増、ジッタ付き)")
+	maxConcurrency := flag.Int("max-concurrency", defaultMaxConcurrency, "同時に実行する Gemini 呼び出しの上限")
+	minInterval := flag.Duration("min-interval", 0, "Gemini 呼び出しの開始間隔の下限 (例: 4s)")
+	crossCheck := flag.String("cross-check", "", "結果を突き合わせる 2 つ目の抽出バックエンド (例: text)")
+	reportPath := flag.String("disagreement-report", "", "不一致レポートの出力先ファイル")
+	runReportPath := flag.String("report", "", "実行結果を JSON で書き出すファイル")
+	routesPath := flag.String("routes", defaultRoutesPath, "路線設定ファイル (同じディレクトリの bus_stops.json で検証)")
+	pendingDir := flag.String("pending-dir", defaultPendingDir, "駅名の一致が不確かな表の保存先 (review で確認)")
 	validFrom := flag.String("from", "", "有効期間 from (YYYY-MM-DD, 複数はカンマ区切り)")
 	validTo := flag.String("to", "", "有効期間 to (YYYY-MM-DD, 複数はカンマ区切り)")
+	dryRun := flag.Bool("dry-run", false, "ファイルを書き込まず既存ファイルとの差分のみ表示する")
+	showDiff := flag.Bool("diff", false, "既存ファイルとの差分を表示してから書き込む")
 	flag.Parse()
 
+	run := newRunReport("generate", *runReportPath, *dryRun)
+
 	if *pdfPath == "" {
-		log.Fatal("--pdf を指定してください")
+		run.fatalf("--pdf を指定してください")
 	}
-	if *apiKey == "" {
-		log.Fatal("GEMINI_API_KEY を設定するか --api-key を指定してください")
+	routes, err := loadRouteConfig(*routesPath)
+	if err != nil {
+		run.fatalf("%v", err)
 	}
-
+	useRouteConfig(routes)
 	periods, err := parsePeriods(*validFrom, *validTo)
 	if err != nil {
-		log.Fatalf("有効期間の指定が不正です: %v", err)
+		run.fatalf("有効期間の指定が不正です: %v", err)
 	}
 
 	pdfData, err := os.ReadFile(*pdfPath)
 	if err != nil {
-		log.Fatalf("PDF の読み込み失敗: %v", err)
+		run.fatalf("PDF の読み込み失敗: %v", err)
 	}
-	log.Printf("PDF 読み込み完了: %s (%d bytes)", *pdfPath, len(pdfData))
+	log.Printf("PDF: %s (%d bytes)", *pdfPath, len(pdfData))
+	run.addPDF(PDFRecord{Path: *pdfPath, SHA256: sha256sum(pdfData), Status: pdfLocal})
 
 	ctx := context.Background()
-	client, err := genai.NewClient(ctx, option.WithAPIKey(*apiKey))
-	if err != nil {
-		log.Fatalf("Gemini クライアント作成失敗: %v", err)
-	}
-	defer client.Close()
-
-	extracted, err := Extract(ctx, client, pdfData)
+	extractors, closeExtractors, err := newExtractors(ctx, extractorOptions{
+		Backend:       *backend,
+		APIKey:        *apiKey,
+		FixtureDir:    *fixtureDir,
+		CacheDir:      *cacheDir,
+		CacheReadOnly: *dryRun,
+		ForceExtract:  *forceExtract,
+		Retry:         newRetryPolicy(*maxAttempts, *retryDelay),
+		Limiter:       newCallLimiter(*maxConcurrency, *minInterval),
+	}, *runs, *crossCheck)
 	if err != nil {
-		log.Fatalf("PDF 抽出失敗: %v", err)
+		run.fatalf("extractor 作成失敗: %v", err)
 	}
-	log.Printf("テーブル抽出: %d 件", len(extracted.Tables))
+	defer closeExtractors()
 
-	services, err := Map(extracted, periods)
+	report, closeReport, err := openReport(*reportPath)
 	if err != nil {
-		log.Fatalf("マッピング失敗: %v", err)
+		run.fatalf("%v", err)
 	}
-	log.Printf("サービス生成: %d 件", len(services))
+	defer closeReport()
 
-	if err := os.MkdirAll(*outputDir, 0755); err != nil {
-		log.Fatalf("出力ディレクトリ作成失敗: %v", err)
-	}
-
-	saved, failed := 0, 0
-	for _, svc := range services {
-		errs := Validate(svc)
-		if len(errs) > 0 {
-			log.Printf("バリデーションエラー [%s]:", svc.ID)
-			for _, e := range errs {
-				log.Printf("  - %v", e)
-			}
-			failed++
-			continue
+	if !*dryRun {
+		if err := os.MkdirAll(*outputDir, 0755); err != nil {
+			run.fatalf("出力ディレクトリ作成失敗: %v", err)
 		}
+	}
 
-		data, err := marshalJSON(svc)
-		if err != nil {
-			log.Printf("JSON 整形失敗 [%s]: %v", svc.ID, err)
-			failed++
-			continue
-		}
+	res := generateFromPDF(ctx, *pdfPath, generateOptions{
+		Extractors: extractors,
+		OutputDir:  *outputDir,
+		Periods:    periods,
+		Report:     report,
+		DryRun:     *dryRun,
+		Diff:       *showDiff,
+		Run:        run,
+		PendingDir: *pendingDir,
+	})
 
-		outPath := filepath.Join(*outputDir, svc.ID+".json")
-		if err := os.WriteFile(outPath, data, 0644); err != nil {
-			log.Fatalf("ファイル書き込み失敗: %v", err)
-		}
-		fmt.Printf("  生成: %s.json\n", svc.ID)
-		saved++
+	if *dryRun {
+		fmt.Printf("\n変更: %d 件 / スキップ: %d 件 (dry-run)\n", res.Changed, res.Failed)
+		run.exit(diffExitCode(res.Failed, res.Changed))
 	}
 
-	fmt.Printf("\n生成: %d 件 / スキップ: %d 件\n", saved, failed)
+	fmt.Printf("\n生成: %d 件 / スキップ: %d 件\n", res.Saved, res.Failed)
 	fmt.Printf("出力先: %s\n", *outputDir)
+	if res.Pending > 0 {
+		fmt.Printf("要確認: %d 件 (timetable-gen review で確認してください)\n", res.Pending)
+	}
 
-	if saved == 0 {
-		os.Exit(1)
+	if res.Saved == 0 {
+		run.exit(exitError)
+	}
+	if *showDiff {
+		run.exit(diffExitCode(res.Failed, res.Changed))
 	}
+	run.finish(exitNoChange)
 }
 
 func parsePeriods(froms, tos string) ([]ValidityPeriod, error) {
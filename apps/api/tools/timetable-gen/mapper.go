@@ -9,7 +9,8 @@ import (
 )
 
 // Map converts extracted PDF data into ServiceData pairs (outbound + inbound per table).
-// Column assignment is deterministic and never delegated to AI:
+// Column assignment is deterministic and never delegated to AI; it comes from the route's
+// columns in station_routes.json, which for every current route is:
 //
 //	outbound (大学→駅): departure=col[0], arrival=col[1]
 //	inbound  (駅→大学): departure=col[1], arrival=col[2]
@@ -21,48 +22,56 @@ func Map(data *ExtractedData, periods []ValidityPeriod) ([]ServiceData, error) {
 		if err != nil {
 			return nil, fmt.Errorf("table %q: %w", table.StationName, err)
 		}
-
-		// validFrom が取れない通常スケジュールは日付なし ID になり学期をまたいで衝突する。
-		// CLI --from が指定されていれば periods[0].From を ID 用に補完する（validityPeriods は
-		// resolvePeriods が cli 全体から決定するため、ここでは ID 生成目的の代入のみ）。
-		if table.SpecificFrom == "" && table.ValidFrom == "" {
-			if len(periods) > 0 {
-				table.ValidFrom = periods[0].From
-			} else {
-				return nil, fmt.Errorf("table %q: validFrom が空です。PDFに運行期間が見つからない場合は --from/--to を指定してください", table.StationName)
-			}
-		}
-
-		vp, err := resolvePeriods(table, periods)
+		pair, err := mapTable(table, route, periods)
 		if err != nil {
-			return nil, fmt.Errorf("table %q: %w", table.StationName, err)
+			return nil, err
 		}
-		cond := buildCondition(table)
+		services = append(services, pair...)
+	}
 
-		outbound := ServiceData{
-			ID:              outboundServiceID(route, table),
-			Name:            outboundServiceName(route, table),
-			From:            StopRef{StopID: route.SchoolStopID, DisplayName: route.SchoolName},
-			To:              StopRef{StopID: route.StationStopID, DisplayName: route.StationName},
-			Direction:       "outbound",
-			ValidityPeriods: vp,
-			Segments:        buildSegments(table.Segments, cond, 0, 1),
-		}
+	return services, nil
+}
 
-		inbound := ServiceData{
-			ID:              inboundServiceID(route, table),
-			Name:            inboundServiceName(route, table),
-			From:            StopRef{StopID: route.StationStopID, DisplayName: route.StationName},
-			To:              StopRef{StopID: route.SchoolStopID, DisplayName: route.SchoolName},
-			Direction:       "inbound",
-			ValidityPeriods: vp,
-			Segments:        buildSegments(table.Segments, cond, 1, 2),
+// mapTable converts one extracted table into its outbound and inbound services on route.
+func mapTable(table ExtractedTable, route StationRoute, periods []ValidityPeriod) ([]ServiceData, error) {
+	// validFrom が取れない通常スケジュールは日付なし ID になり学期をまたいで衝突する。
+	// CLI --from が指定されていれば periods[0].From を ID 用に補完する（validityPeriods は
+	// resolvePeriods が cli 全体から決定するため、ここでは ID 生成目的の代入のみ）。
+	if table.SpecificFrom == "" && table.ValidFrom == "" {
+		if len(periods) > 0 {
+			table.ValidFrom = periods[0].From
+		} else {
+			return nil, fmt.Errorf("table %q: validFrom が空です。PDFに運行期間が見つからない場合は --from/--to を指定してください", table.StationName)
 		}
+	}
 
-		services = append(services, outbound, inbound)
+	vp, err := resolvePeriods(table, periods)
+	if err != nil {
+		return nil, fmt.Errorf("table %q: %w", table.StationName, err)
 	}
+	cond := buildCondition(table)
 
-	return services, nil
+	outbound := ServiceData{
+		ID:              outboundServiceID(route, table),
+		Name:            outboundServiceName(route, table),
+		From:            StopRef{StopID: route.SchoolStopID, DisplayName: route.SchoolName},
+		To:              StopRef{StopID: route.StationStopID, DisplayName: route.StationName},
+		Direction:       "outbound",
+		ValidityPeriods: vp,
+		Segments:        buildSegments(table.Segments, cond, route.Columns.Outbound.Departure, route.Columns.Outbound.Arrival),
+	}
+
+	inbound := ServiceData{
+		ID:              inboundServiceID(route, table),
+		Name:            inboundServiceName(route, table),
+		From:            StopRef{StopID: route.StationStopID, DisplayName: route.StationName},
+		To:              StopRef{StopID: route.SchoolStopID, DisplayName: route.SchoolName},
+		Direction:       "inbound",
+		ValidityPeriods: vp,
+		Segments:        buildSegments(table.Segments, cond, route.Columns.Inbound.Departure, route.Columns.Inbound.Arrival),
+	}
+
+	return []ServiceData{outbound, inbound}, nil
 }
 
 // buildSegments maps extracted segments to ServiceSegments using the given column indices.
@@ -89,7 +98,7 @@ func buildSegments(segs []ExtractedSegment, cond SegmentCondition, depCol, arrCo
 }
 
 // shuttleNoteCol is the column index where Gemini puts the interval note for "～" rows.
-const shuttleNoteCol = 3
+const shuttleNoteCol = tableColumns
 
 // expandShuttleRows splits fixed segments at "～" rows, emitting shuttle placeholders.
 func expandShuttleRows(segs []ExtractedSegment, depCol int) []ExtractedSegment {
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"api/pkg/board"
+)
+
+// maxPrintDays bounds --from/--to so a typo does not render years of timetables.
+const maxPrintDays = 366
+
+// runPrint implements `timetable-gen print`: a printable HTML timetable board for a stop,
+// laid out like the boards at the bus stops (hour rows, minute columns).
+//
+//	print --stop <id|name> [--date YYYY-MM-DD | --from YYYY-MM-DD --to YYYY-MM-DD] [--out file.html] [dir]
+func runPrint(args []string) {
+	stop, date, from, to, out := "", "", "", "", ""
+	dir := "../../data/services"
+	for i := 0; i < len(args); i++ {
+		value := func() string {
+			if i+1 < len(args) {
+				i++
+				return args[i]
+			}
+			return ""
+		}
+		switch args[i] {
+		case "--stop":
+			stop = value()
+		case "--date":
+			date = value()
+		case "--from":
+			from = value()
+		case "--to":
+			to = value()
+		case "--out":
+			out = value()
+		default:
+			dir = args[i]
+		}
+	}
+	if stop == "" {
+		fmt.Fprintln(os.Stderr, "usage: go run . print --stop <id|name> [--date YYYY-MM-DD | --from YYYY-MM-DD --to YYYY-MM-DD] [--out file.html] [dir]")
+		os.Exit(1)
+	}
+
+	w := io.Writer(os.Stdout)
+	if out != "" {
+		f, err := os.Create(out)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := printBoard(w, dir, stop, date, from, to); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	if out != "" {
+		fmt.Printf("時刻表を書き出しました: %s\n", out)
+	}
+}
+
+func printBoard(w io.Writer, dir, stop, date, from, to string) error {
+	if date != "" && (from != "" || to != "") {
+		return fmt.Errorf("--date と --from/--to は同時に指定できません")
+	}
+	if (from == "") != (to == "") {
+		return fmt.Errorf("--from と --to は両方指定してください")
+	}
+	start, err := parseDateFlag("--date", date)
+	if err != nil {
+		return err
+	}
+	end := start
+	if from != "" {
+		if start, err = parseDateFlag("--from", from); err != nil {
+			return err
+		}
+		if end, err = parseDateFlag("--to", to); err != nil {
+			return err
+		}
+		if end.Before(start) {
+			return fmt.Errorf("--to は --from 以降の日付を指定してください")
+		}
+		if end.Sub(start) >= maxPrintDays*24*time.Hour {
+			return fmt.Errorf("期間は %d 日以内で指定してください", maxPrintDays)
+		}
+	}
+
+	current, archived, err := loadServicesWithArchive(dir)
+	if err != nil {
+		return err
+	}
+	stopIDs, stopName, err := resolveStop(withArchived(current, archived), stop)
+	if err != nil {
+		return err
+	}
+
+	// API と同じく、過去の日付を含むときだけアーカイブ済みサービスを使う
+	services := current
+	if start.Before(jstToday()) {
+		services = withArchived(current, archived)
+	}
+	var segments []board.Segment
+	for _, svc := range services {
+		if stopIDs[svc.From.StopID] {
+			segments = append(segments, boardSegments(svc)...)
+		}
+	}
+
+	var b board.Board
+	if date != "" || from == "" {
+		b = board.ForDate(stopName, start, segments)
+	} else {
+		b = board.ForPeriod(stopName, start, end, segments)
+	}
+	return b.WriteHTML(w)
+}
+
+// boardSegments converts svc for the printable board.
+func boardSegments(svc ServiceData) []board.Segment {
+	var segments []board.Segment
+	for _, seg := range svc.Segments {
+		s := board.Segment{
+			Origin:      svc.From.DisplayName,
+			Destination: svc.To.DisplayName,
+			Validity:    schedulePeriods(svc.ValidityPeriods),
+			Condition:   seg.Condition.scheduleCondition(),
+		}
+		switch seg.SegmentType {
+		case "fixed":
+			for _, tp := range seg.Times {
+				s.Departures = append(s.Departures, tp.Departure)
+			}
+		case "shuttle":
+			s.Shuttle = &board.Shuttle{Start: seg.StartTime, End: seg.EndTime}
+			if seg.Interval != nil {
+				s.Shuttle.MinInterval, s.Shuttle.MaxInterval = seg.Interval.Min, seg.Interval.Max
+			}
+		default:
+			continue
+		}
+		segments = append(segments, s)
+	}
+	return segments
+}
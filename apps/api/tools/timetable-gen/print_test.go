@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/")
+
+// boardFixture writes a weekday service with a shuttle window, a saturday service to another
+// stop and a special-date service, all departing from 八王子駅.
+func boardFixture(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	weekday := validService()
+	weekday.Segments = append(weekday.Segments,
+		ServiceSegment{
+			SegmentType: "shuttle",
+			Condition:   SegmentCondition{Type: "dayType", Value: "weekday"},
+			StartTime:   "9:30",
+			EndTime:     "10:40",
+			Interval:    &Interval{Min: 3, Max: 5},
+		},
+		ServiceSegment{
+			SegmentType: "fixed",
+			Condition:   SegmentCondition{Type: "dayType", Value: "weekday"},
+			Times:       []TimePair{{Departure: "10:45", Arrival: "11:05"}, {Departure: "18:10", Arrival: "18:30"}},
+		},
+	)
+	writeJSON(t, filepath.Join(dir, weekday.ID+".json"), weekday)
+
+	saturday := validService()
+	saturday.ID = "hachioji-to-minamino-saturday-20260407"
+	saturday.To = StopRef{StopID: 2, DisplayName: "八王子みなみ野駅"}
+	saturday.Segments = []ServiceSegment{{
+		SegmentType: "fixed",
+		Condition:   SegmentCondition{Type: "dayType", Value: "saturday"},
+		Times:       []TimePair{{Departure: "8:15", Arrival: "8:35"}, {Departure: "12:15", Arrival: "12:35"}},
+	}}
+	writeJSON(t, filepath.Join(dir, saturday.ID+".json"), saturday)
+
+	special := validService()
+	special.ID = "hachioji-to-school-2026-04-29"
+	special.ValidityPeriods = []ValidityPeriod{{From: "2026-04-29", To: "2026-04-29"}}
+	special.Segments = []ServiceSegment{{
+		SegmentType: "fixed",
+		Condition:   SegmentCondition{Type: "specificDate", Value: "2026-04-29"},
+		Times:       []TimePair{{Departure: "8:00", Arrival: "8:20"}, {Departure: "9:00", Arrival: "9:20"}},
+	}}
+	writeJSON(t, filepath.Join(dir, special.ID+".json"), special)
+	return dir
+}
+
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", "board", name)
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -run %s -update to create it)", err, t.Name())
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs from the golden file; run go test -run %s -update and review the diff\n%s", path, t.Name(), got)
+	}
+}
+
+func TestPrintBoard_Snapshots(t *testing.T) {
+	dir := boardFixture(t)
+	tests := []struct {
+		golden         string
+		date, from, to string
+	}{
+		{"weekday.html", "2026-04-28", "", ""},
+		{"special-date.html", "2026-04-29", "", ""},
+		{"period.html", "", "2026-04-25", "2026-05-06"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := printBoard(&buf, dir, "八王子駅", tt.date, tt.from, tt.to); err != nil {
+			t.Fatalf("%s: %v", tt.golden, err)
+		}
+		assertGolden(t, tt.golden, buf.Bytes())
+	}
+}
+
+func TestPrintBoard_Layout(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printBoard(&buf, boardFixture(t), "1", "2026-04-28", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	html := buf.String()
+
+	// 9 時台はシャトル運行の前後で 2 行に分かれ、間に運行間隔の行が入る
+	shuttle := strings.Index(html, "～ 約3〜5分間隔 ～")
+	before := strings.Index(html, `<td class="minute">00</td><td class="minute">30</td>`)
+	after := strings.Index(html, `<th class="hour">10</th><td class="minute">45</td>`)
+	if shuttle < 0 || before < 0 || after < 0 || !(before < shuttle && shuttle < after) {
+		t.Errorf("shuttle row should sit between 9:00/9:30 and 10:45:\n%s", html)
+	}
+	if strings.Contains(html, "八王子みなみ野駅") {
+		t.Error("saturday-only destination should not appear on a weekday")
+	}
+}
+
+func TestPrintBoard_InvalidArgs(t *testing.T) {
+	dir := boardFixture(t)
+	tests := []struct{ date, from, to string }{
+		{"2026-04-28", "2026-04-01", "2026-04-30"},
+		{"", "2026-04-01", ""},
+		{"", "2026-05-01", "2026-04-01"},
+		{"", "2026-01-01", "2027-01-02"}, // 367 日間
+		{"2026/04/28", "", ""},
+	}
+	for _, tt := range tests {
+		if err := printBoard(&bytes.Buffer{}, dir, "八王子駅", tt.date, tt.from, tt.to); err == nil {
+			t.Errorf("printBoard(date=%q from=%q to=%q) should fail", tt.date, tt.from, tt.to)
+		}
+	}
+	if err := printBoard(&bytes.Buffer{}, dir, "存在しない停留所", "2026-04-28", "", ""); err == nil {
+		t.Error("unknown stop should fail")
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// Output formats for view --stop and view --compare.
+const (
+	formatText     = "text"
+	formatMarkdown = "markdown"
+	formatHTML     = "html"
+)
+
+// textTable is a titled table that can be rendered for the terminal, a pull request
+// description (markdown) or a browser (html).
+type textTable struct {
+	Title  string
+	Header []string
+	Rows   [][]string
+	Notes  []string // printed below the table
+}
+
+func (t textTable) render(w io.Writer, format string) error {
+	switch format {
+	case formatText, "":
+		t.renderText(w)
+	case formatMarkdown, "md":
+		t.renderMarkdown(w)
+	case formatHTML:
+		t.renderHTML(w)
+	default:
+		return fmt.Errorf("未知の出力形式 %q (text|markdown|html)", format)
+	}
+	return nil
+}
+
+func (t textTable) renderText(w io.Writer) {
+	widths := make([]int, len(t.Header))
+	for i, h := range t.Header {
+		widths[i] = displayWidth(h)
+	}
+	for _, row := range t.Rows {
+		for i, cell := range row {
+			if i < len(widths) && displayWidth(cell) > widths[i] {
+				widths[i] = displayWidth(cell)
+			}
+		}
+	}
+
+	line := func(cells []string) {
+		var b strings.Builder
+		for i, cell := range cells {
+			if i > 0 {
+				b.WriteString("  ")
+			}
+			b.WriteString(cell)
+			if i < len(cells)-1 {
+				b.WriteString(strings.Repeat(" ", widths[i]-displayWidth(cell)))
+			}
+		}
+		fmt.Fprintln(w, strings.TrimRight(b.String(), " "))
+	}
+
+	if t.Title != "" {
+		fmt.Fprintln(w, t.Title)
+	}
+	line(t.Header)
+	total := 0
+	for _, wd := range widths {
+		total += wd
+	}
+	fmt.Fprintln(w, strings.Repeat("─", total+2*(len(widths)-1)))
+	for _, row := range t.Rows {
+		line(row)
+	}
+	for _, n := range t.Notes {
+		fmt.Fprintln(w, n)
+	}
+}
+
+func (t textTable) renderMarkdown(w io.Writer) {
+	cell := func(s string) string { return strings.ReplaceAll(s, "|", `\|`) }
+	row := func(cells []string) {
+		escaped := make([]string, len(cells))
+		for i, c := range cells {
+			escaped[i] = cell(c)
+		}
+		fmt.Fprintf(w, "| %s |\n", strings.Join(escaped, " | "))
+	}
+
+	if t.Title != "" {
+		fmt.Fprintf(w, "### %s\n\n", t.Title)
+	}
+	row(t.Header)
+	sep := make([]string, len(t.Header))
+	for i := range sep {
+		sep[i] = "---"
+	}
+	fmt.Fprintf(w, "|%s|\n", strings.Join(sep, "|"))
+	for _, r := range t.Rows {
+		row(r)
+	}
+	if len(t.Notes) > 0 {
+		fmt.Fprintln(w)
+		for _, n := range t.Notes {
+			fmt.Fprintf(w, "%s\n", n)
+		}
+	}
+}
+
+func (t textTable) renderHTML(w io.Writer) {
+	if t.Title != "" {
+		fmt.Fprintf(w, "<h3>%s</h3>\n", html.EscapeString(t.Title))
+	}
+	fmt.Fprintln(w, "<table>")
+	fmt.Fprint(w, "  <tr>")
+	for _, h := range t.Header {
+		fmt.Fprintf(w, "<th>%s</th>", html.EscapeString(h))
+	}
+	fmt.Fprintln(w, "</tr>")
+	for _, r := range t.Rows {
+		fmt.Fprint(w, "  <tr>")
+		for _, c := range r {
+			fmt.Fprintf(w, "<td>%s</td>", html.EscapeString(c))
+		}
+		fmt.Fprintln(w, "</tr>")
+	}
+	fmt.Fprintln(w, "</table>")
+	for _, n := range t.Notes {
+		fmt.Fprintf(w, "<p>%s</p>\n", html.EscapeString(n))
+	}
+}
+
+// displayWidth approximates the terminal width of s: CJK characters and full-width forms
+// take two columns.
+func displayWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		switch {
+		case r >= 0x1100 && r <= 0x115F,
+			r >= 0x2E80 && r <= 0xA4CF,
+			r >= 0xAC00 && r <= 0xD7A3,
+			r >= 0xF900 && r <= 0xFAFF,
+			r >= 0xFE30 && r <= 0xFE4F,
+			r >= 0xFF00 && r <= 0xFF60,
+			r >= 0xFFE0 && r <= 0xFFE6:
+			n += 2
+		default:
+			n++
+		}
+	}
+	return n
+}
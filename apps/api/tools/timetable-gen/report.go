@@ -0,0 +1,257 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// reportSchemaVersion is bumped whenever a field of RunReport is renamed, removed or changes
+// meaning. Adding fields does not bump it, so consumers should ignore unknown fields.
+const reportSchemaVersion = 1
+
+// PDFRecord statuses.
+const (
+	pdfNew       = "new"       // first time this URL was seen
+	pdfUpdated   = "updated"   // known URL whose content hash changed
+	pdfUnchanged = "unchanged" // known URL with the same content hash
+	pdfFailed    = "failed"    // download failed
+	pdfRemoved   = "removed"   // known URL no longer linked from the page
+	pdfLocal     = "local"     // passed with --pdf, not fetched
+)
+
+// ServiceRecord statuses.
+const (
+	serviceGenerated = "generated"     // written to the output directory
+	serviceDryRun    = "dryRun"        // valid, but not written because of --dry-run
+	serviceFailed    = "failed"        // validation, disagreement or write error
+	servicePending   = "pendingReview" // station match not confident; saved to the review queue
+)
+
+// ServiceRecord changes, filled in with --dry-run or --diff.
+const (
+	changeAdded     = "added"
+	changeModified  = "modified"
+	changeUnchanged = "unchanged"
+)
+
+// RunReport is the machine-readable summary written by --report.
+type RunReport struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	Command       string          `json:"command"` // "generate", "fetch", "sync" or "archive"
+	DryRun        bool            `json:"dryRun"`
+	StartedAt     string          `json:"startedAt"` // RFC 3339
+	FinishedAt    string          `json:"finishedAt"`
+	DurationMs    int64           `json:"durationMs"`
+	ExitCode      int             `json:"exitCode"`
+	Error         string          `json:"error,omitempty"` // set when the run aborted
+	PDFs          []PDFRecord     `json:"pdfs"`
+	Services      []ServiceRecord `json:"services"`
+	Archived      []ArchiveRecord `json:"archived"`
+	Summary       ReportSummary   `json:"summary"`
+
+	path     string
+	started  time.Time
+	mu       sync.Mutex // sync processes PDFs concurrently
+	cleanups []func()
+}
+
+// PDFRecord is one PDF seen by fetch or processed by generate.
+type PDFRecord struct {
+	URL           string `json:"url,omitempty"` // relative URL on the university site; empty for --pdf
+	Title         string `json:"title,omitempty"`
+	PreviousTitle string `json:"previousTitle,omitempty"` // set when the link text changed
+	Path          string `json:"path,omitempty"`
+	SHA256        string `json:"sha256,omitempty"`
+	Status        string `json:"status"`
+	Error         string `json:"error,omitempty"`
+	GenerateMs    int64  `json:"generateMs,omitempty"` // time spent extracting and writing services
+}
+
+// ServiceRecord is one service produced (or rejected) from a PDF.
+type ServiceRecord struct {
+	ID     string   `json:"id"`
+	Source string   `json:"source"` // PDF file name
+	Status string   `json:"status"`
+	Change string   `json:"change,omitempty"`
+	File   string   `json:"file,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// ArchiveRecord is one service file moved (or, with --dry-run, to be moved) to archived/.
+type ArchiveRecord struct {
+	File      string `json:"file"`
+	ExpiredOn string `json:"expiredOn"` // latest validityPeriods[].to
+}
+
+type ReportSummary struct {
+	PDFsNew       int `json:"pdfsNew"`
+	PDFsUpdated   int `json:"pdfsUpdated"`
+	PDFsUnchanged int `json:"pdfsUnchanged"`
+	PDFsFailed    int `json:"pdfsFailed"`
+	PDFsRemoved   int `json:"pdfsRemoved"`
+	Generated     int `json:"generated"`
+	Failed        int `json:"failed"`
+	Changed       int `json:"changed"`
+	Archived      int `json:"archived"`
+	PendingReview int `json:"pendingReview"`
+}
+
+// newRunReport starts a report for command. path is where finish writes it; empty disables writing
+// but the report is still collected so callers never have to check for nil.
+func newRunReport(command, path string, dryRun bool) *RunReport {
+	now := time.Now()
+	return &RunReport{
+		SchemaVersion: reportSchemaVersion,
+		Command:       command,
+		DryRun:        dryRun,
+		StartedAt:     now.Format(time.RFC3339),
+		PDFs:          []PDFRecord{},
+		Services:      []ServiceRecord{},
+		Archived:      []ArchiveRecord{},
+		path:          path,
+		started:       now,
+	}
+}
+
+func (r *RunReport) addPDF(rec PDFRecord) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.PDFs = append(r.PDFs, rec)
+}
+
+// setGenerateTime records how long generation took for the PDF at path.
+func (r *RunReport) setGenerateTime(path string, d time.Duration) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.PDFs {
+		if r.PDFs[i].Path == path {
+			r.PDFs[i].GenerateMs = d.Milliseconds()
+		}
+	}
+}
+
+// setPDFError records a failure that prevented any service from being produced from path.
+func (r *RunReport) setPDFError(path string, err error) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.PDFs {
+		if r.PDFs[i].Path == path {
+			r.PDFs[i].Error = err.Error()
+		}
+	}
+}
+
+func (r *RunReport) addService(rec ServiceRecord) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Services = append(r.Services, rec)
+}
+
+func (r *RunReport) addArchived(rec ArchiveRecord) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Archived = append(r.Archived, rec)
+}
+
+// onFinish registers f to run when the run finishes, including through exit and fatalf,
+// which leave the process without running deferred calls.
+func (r *RunReport) onFinish(f func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cleanups = append(r.cleanups, f)
+}
+
+// finish fills in timing and the summary and writes the report when a path was given.
+func (r *RunReport) finish(exitCode int) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	defer func() {
+		for _, f := range r.cleanups {
+			f()
+		}
+		r.cleanups = nil
+	}()
+	now := time.Now()
+	r.FinishedAt = now.Format(time.RFC3339)
+	r.DurationMs = now.Sub(r.started).Milliseconds()
+	r.ExitCode = exitCode
+
+	s := ReportSummary{Archived: len(r.Archived)}
+	for _, p := range r.PDFs {
+		switch p.Status {
+		case pdfNew:
+			s.PDFsNew++
+		case pdfUpdated:
+			s.PDFsUpdated++
+		case pdfUnchanged:
+			s.PDFsUnchanged++
+		case pdfFailed:
+			s.PDFsFailed++
+		case pdfRemoved:
+			s.PDFsRemoved++
+		}
+	}
+	for _, svc := range r.Services {
+		switch svc.Status {
+		case serviceGenerated:
+			s.Generated++
+		case serviceFailed:
+			s.Failed++
+		case servicePending:
+			s.PendingReview++
+		}
+		if svc.Change == changeAdded || svc.Change == changeModified {
+			s.Changed++
+		}
+	}
+	r.Summary = s
+
+	if r.path == "" {
+		return
+	}
+	data, err := marshalJSON(r)
+	if err != nil {
+		log.Printf("レポート整形失敗: %v", err)
+		return
+	}
+	if err := os.WriteFile(r.path, append(data, '\n'), 0644); err != nil {
+		log.Printf("レポート書き込み失敗: %v", err)
+	}
+}
+
+// exit writes the report and exits the process with code.
+func (r *RunReport) exit(code int) {
+	r.finish(code)
+	os.Exit(code)
+}
+
+// fatalf records an aborting error, writes the report and exits with exitError.
+func (r *RunReport) fatalf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	if r != nil {
+		r.Error = msg
+	}
+	r.finish(exitError)
+	log.Fatal(msg)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunReport_Generate(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := filepath.Join(dir, "timetable.pdf")
+	if err := os.WriteFile(pdfPath, []byte("%PDF-fake"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outDir := filepath.Join(dir, "services")
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// 学生会館の表は時刻が足りずバリデーションで落ちる
+	extracted := sampleExtracted()
+	extracted.Tables = append(extracted.Tables, ExtractedTable{
+		StationName: "学生会館",
+		DayType:     "weekday",
+		ValidFrom:   extracted.Tables[0].ValidFrom,
+		ValidTo:     extracted.Tables[0].ValidTo,
+		Segments:    []ExtractedSegment{{Type: "fixed", Rows: [][]string{{"7:00", "7:10", "7:20"}}}},
+	})
+
+	reportPath := filepath.Join(dir, "report.json")
+	run := newRunReport("generate", reportPath, false)
+	run.addPDF(PDFRecord{Path: pdfPath, SHA256: sha256sum([]byte("%PDF-fake")), Status: pdfLocal})
+	generateFromPDF(context.Background(), pdfPath, generateOptions{
+		Extractors: []namedExtractor{{Label: "static", Extractor: staticExtractor{extracted}}},
+		OutputDir:  outDir,
+		Run:        run,
+	})
+	run.finish(exitNoChange)
+
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got RunReport
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.SchemaVersion != reportSchemaVersion || got.Command != "generate" {
+		t.Errorf("header = (%d, %q)", got.SchemaVersion, got.Command)
+	}
+	if got.FinishedAt == "" {
+		t.Error("finishedAt is empty")
+	}
+	if len(got.PDFs) != 1 || got.PDFs[0].Status != pdfLocal {
+		t.Errorf("pdfs = %+v", got.PDFs)
+	}
+	if got.Summary.Generated != 2 || got.Summary.Failed != 2 {
+		t.Errorf("summary = %+v, want 2 generated and 2 failed", got.Summary)
+	}
+	for _, svc := range got.Services {
+		switch svc.Status {
+		case serviceGenerated:
+			if svc.File == "" {
+				t.Errorf("%s: generated service without file", svc.ID)
+			}
+		case serviceFailed:
+			if len(svc.Errors) == 0 {
+				t.Errorf("%s: failed service without errors", svc.ID)
+			}
+		default:
+			t.Errorf("%s: unexpected status %q", svc.ID, svc.Status)
+		}
+	}
+}
+
+func TestRunReport_EmptyListsAreArrays(t *testing.T) {
+	data, err := marshalJSON(newRunReport("fetch", "", false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"pdfs", "services", "archived"} {
+		if string(raw[key]) != "[]" {
+			t.Errorf("%s = %s, want []", key, raw[key])
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// retryPolicy controls how many times a failed call is retried and how long to wait between
+// attempts: BaseDelay doubled each attempt, capped at MaxDelay, with up to Jitter of the delay
+// randomly subtracted so that parallel runs do not retry in lockstep.
+type retryPolicy struct {
+	MaxAttempts    int
+	BaseDelay      time.Duration
+	MaxDelay       time.Duration
+	Jitter         float64       // 0..1
+	RateLimitDelay time.Duration // minimum wait after a rate-limit error
+
+	// sleep and random are replaced in tests; nil means time-based sleep and math/rand.
+	sleep  func(ctx context.Context, d time.Duration) error
+	random func() float64
+}
+
+var defaultRetryPolicy = retryPolicy{
+	MaxAttempts:    3,
+	BaseDelay:      2 * time.Second,
+	MaxDelay:       time.Minute,
+	Jitter:         0.5,
+	RateLimitDelay: 10 * time.Second,
+}
+
+// newRetryPolicy returns defaultRetryPolicy with the attempt count and base delay from the CLI.
+func newRetryPolicy(maxAttempts int, baseDelay time.Duration) retryPolicy {
+	p := defaultRetryPolicy
+	if maxAttempts > 0 {
+		p.MaxAttempts = maxAttempts
+	}
+	if baseDelay > 0 {
+		p.BaseDelay = baseDelay
+	}
+	return p
+}
+
+// backoff returns the wait before attempt (2-based: the wait after the first failure is
+// backoff(2)). rateLimited raises the wait to at least RateLimitDelay.
+func (p retryPolicy) backoff(attempt int, rateLimited bool) time.Duration {
+	d := p.BaseDelay
+	for i := 2; i < attempt && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		random := p.random
+		if random == nil {
+			random = rand.Float64
+		}
+		d -= time.Duration(float64(d) * p.Jitter * random())
+	}
+	if rateLimited && d < p.RateLimitDelay {
+		d = p.RateLimitDelay
+	}
+	return d
+}
+
+func (p retryPolicy) wait(ctx context.Context, d time.Duration) error {
+	if p.sleep != nil {
+		return p.sleep(ctx, d)
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// permanentError marks an error that retrying cannot fix.
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+func permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err}
+}
+
+// isRetryable classifies an error from the extraction backend. Quota, overload and transport
+// errors are retryable; bad requests, auth failures and cancellation are not. Errors the
+// backend does not classify are retried, as the original single-loop implementation did.
+func isRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var perm permanentError
+	if errors.As(err, &perm) {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var gerr *googleapi.Error
+	if errors.As(err, &gerr) {
+		return retryableHTTPStatus(gerr.Code)
+	}
+	if st, ok := status.FromError(err); ok && st.Code() != codes.Unknown {
+		switch st.Code() {
+		case codes.ResourceExhausted, codes.Unavailable, codes.DeadlineExceeded, codes.Internal, codes.Aborted:
+			return true
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func retryableHTTPStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout, http.StatusRequestTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// rateLimitText matches a 429 status in the text of an untyped error, e.g. "status 429" or
+// "code: 429". A bare "429" is not enough: it also appears in names like 260429.pdf.
+var rateLimitText = regexp.MustCompile(`\b(status|code)\s*[:=]?\s*429\b|\b429 too many requests\b|rate limit|quota exceeded`)
+
+// isRateLimitError reports whether err is a quota error, which gets the longer RateLimitDelay.
+// Typed googleapi and gRPC errors are classified by their code; the text is only checked for
+// errors that carry no code.
+func isRateLimitError(err error) bool {
+	var gerr *googleapi.Error
+	if errors.As(err, &gerr) {
+		return gerr.Code == http.StatusTooManyRequests
+	}
+	if st, ok := status.FromError(err); ok && st.Code() != codes.Unknown {
+		return st.Code() == codes.ResourceExhausted
+	}
+	return rateLimitText.MatchString(strings.ToLower(err.Error()))
+}
+
+// callLimiter bounds backend calls shared across parallel PDFs: at most Concurrency calls in
+// flight, and at least MinInterval between the start of consecutive calls.
+type callLimiter struct {
+	sem         chan struct{}
+	minInterval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// newCallLimiter returns a limiter. concurrency < 1 means unlimited; minInterval 0 means no
+// rate limit.
+func newCallLimiter(concurrency int, minInterval time.Duration) *callLimiter {
+	l := &callLimiter{minInterval: minInterval}
+	if concurrency > 0 {
+		l.sem = make(chan struct{}, concurrency)
+	}
+	return l
+}
+
+// acquire blocks until a call may start. The returned release must be called when it ends.
+func (l *callLimiter) acquire(ctx context.Context) (release func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	if l.sem != nil {
+		select {
+		case l.sem <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	release = func() {
+		if l.sem != nil {
+			<-l.sem
+		}
+	}
+
+	if l.minInterval > 0 {
+		l.mu.Lock()
+		now := time.Now()
+		start := l.next
+		if start.Before(now) {
+			start = now
+		}
+		l.next = start.Add(l.minInterval)
+		l.mu.Unlock()
+
+		if d := time.Until(start); d > 0 {
+			t := time.NewTimer(d)
+			defer t.Stop()
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				release()
+				return nil, ctx.Err()
+			}
+		}
+	}
+	return release, nil
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeGenerator is a contentGenerator that returns the queued errors in order and then
+// responds with data. It records the peak number of concurrent calls.
+type fakeGenerator struct {
+	mu       sync.Mutex
+	errs     []error
+	data     *ExtractedData
+	calls    int
+	inFlight int
+	peak     int
+	delay    time.Duration
+}
+
+func (f *fakeGenerator) Generate(ctx context.Context, prompt string, pdfData []byte) (string, error) {
+	f.mu.Lock()
+	f.calls++
+	f.inFlight++
+	if f.inFlight > f.peak {
+		f.peak = f.inFlight
+	}
+	var err error
+	if len(f.errs) > 0 {
+		err, f.errs = f.errs[0], f.errs[1:]
+	}
+	f.mu.Unlock()
+
+	time.Sleep(f.delay)
+
+	f.mu.Lock()
+	f.inFlight--
+	f.mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+	b, _ := json.Marshal(f.data)
+	return string(b), nil
+}
+
+// noSleepPolicy retries without waiting and records the requested waits.
+func noSleepPolicy(maxAttempts int, waits *[]time.Duration) retryPolicy {
+	p := defaultRetryPolicy
+	p.MaxAttempts = maxAttempts
+	p.random = func() float64 { return 0 }
+	p.sleep = func(ctx context.Context, d time.Duration) error {
+		*waits = append(*waits, d)
+		return ctx.Err()
+	}
+	return p
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := retryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second, RateLimitDelay: 10 * time.Second}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, w := range want {
+		if got := p.backoff(i+2, false); got != w {
+			t.Errorf("backoff(%d) = %v, want %v", i+2, got, w)
+		}
+	}
+	if got := p.backoff(2, true); got != 10*time.Second {
+		t.Errorf("rate-limited backoff = %v, want RateLimitDelay", got)
+	}
+
+	p.Jitter = 0.5
+	p.random = func() float64 { return 1 }
+	if got := p.backoff(3, false); got != time.Second {
+		t.Errorf("full jitter backoff(3) = %v, want half of 2s", got)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"503", &googleapi.Error{Code: http.StatusServiceUnavailable}, true},
+		{"429", fmt.Errorf("wrapped: %w", &googleapi.Error{Code: http.StatusTooManyRequests}), true},
+		{"400", &googleapi.Error{Code: http.StatusBadRequest}, false},
+		{"403", &googleapi.Error{Code: http.StatusForbidden}, false},
+		{"grpc unavailable", status.Error(codes.Unavailable, "overloaded"), true},
+		{"grpc resource exhausted", status.Error(codes.ResourceExhausted, "quota"), true},
+		{"grpc invalid argument", status.Error(codes.InvalidArgument, "bad pdf"), false},
+		{"grpc unauthenticated", status.Error(codes.Unauthenticated, "bad key"), false},
+		{"canceled", context.Canceled, false},
+		{"permanent", permanent(errors.New("schema rejected")), false},
+		{"unclassified", errors.New("connection reset by peer"), true},
+	}
+	for _, tt := range tests {
+		if got := isRetryable(tt.err); got != tt.want {
+			t.Errorf("isRetryable(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsRateLimitError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"googleapi 429", fmt.Errorf("wrapped: %w", &googleapi.Error{Code: http.StatusTooManyRequests}), true},
+		{"googleapi 503", &googleapi.Error{Code: http.StatusServiceUnavailable, Message: "rate limit"}, false},
+		{"grpc resource exhausted", status.Error(codes.ResourceExhausted, "quota"), true},
+		{"grpc unavailable", status.Error(codes.Unavailable, "code 429"), false},
+		{"status text", errors.New("generate content: unexpected status 429"), true},
+		{"code text", errors.New("Error 429 Too Many Requests"), true},
+		{"quota text", errors.New("Quota exceeded for model"), true},
+		// 4/29 の PDF のファイル名や URL に含まれる 429 はレート制限ではない
+		{"pdf name", errors.New("open downloaded/260429.pdf: permission denied"), false},
+		{"pdf url", errors.New(`Get "https://example.ac.jp/campus/access/260429.pdf": connection reset by peer`), false},
+	}
+	for _, tt := range tests {
+		if got := isRateLimitError(tt.err); got != tt.want {
+			t.Errorf("isRateLimitError(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGeminiExtract_RetriesTransientErrors(t *testing.T) {
+	var waits []time.Duration
+	gen := &fakeGenerator{
+		errs: []error{
+			&googleapi.Error{Code: http.StatusServiceUnavailable},
+			&googleapi.Error{Code: http.StatusTooManyRequests},
+		},
+		data: sampleExtracted(),
+	}
+	g := &GeminiExtractor{gen: gen, model: geminiModel, retry: noSleepPolicy(3, &waits)}
+
+	got, err := g.Extract(context.Background(), []byte("%PDF"))
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if len(got.Tables) != len(sampleExtracted().Tables) || gen.calls != 3 {
+		t.Errorf("got %d tables after %d calls, want %d tables after 3", len(got.Tables), gen.calls, len(sampleExtracted().Tables))
+	}
+	want := []time.Duration{defaultRetryPolicy.BaseDelay, defaultRetryPolicy.RateLimitDelay}
+	if len(waits) != 2 || waits[0] != want[0] || waits[1] != want[1] {
+		t.Errorf("waits = %v, want %v", waits, want)
+	}
+}
+
+func TestGeminiExtract_PermanentErrorStopsRetrying(t *testing.T) {
+	var waits []time.Duration
+	gen := &fakeGenerator{errs: []error{&googleapi.Error{Code: http.StatusBadRequest}}, data: sampleExtracted()}
+	g := &GeminiExtractor{gen: gen, model: geminiModel, retry: noSleepPolicy(3, &waits)}
+
+	if _, err := g.Extract(context.Background(), []byte("%PDF")); err == nil {
+		t.Fatal("expected error")
+	}
+	if gen.calls != 1 || len(waits) != 0 {
+		t.Errorf("calls = %d, waits = %v; a permanent error must not be retried", gen.calls, waits)
+	}
+}
+
+func TestGeminiExtract_GivesUpAfterMaxAttempts(t *testing.T) {
+	var waits []time.Duration
+	unavailable := status.Error(codes.Unavailable, "overloaded")
+	gen := &fakeGenerator{errs: []error{unavailable, unavailable, unavailable}, data: sampleExtracted()}
+	g := &GeminiExtractor{gen: gen, model: geminiModel, retry: noSleepPolicy(3, &waits)}
+
+	_, err := g.Extract(context.Background(), []byte("%PDF"))
+	if err == nil || status.Code(errors.Unwrap(err)) != codes.Unavailable {
+		t.Fatalf("err = %v, want the last backend error wrapped", err)
+	}
+	if gen.calls != 3 {
+		t.Errorf("calls = %d, want 3", gen.calls)
+	}
+}
+
+func TestCallLimiter_BoundsConcurrency(t *testing.T) {
+	gen := &fakeGenerator{data: sampleExtracted(), delay: 20 * time.Millisecond}
+	limiter := newCallLimiter(2, 0)
+	g := &GeminiExtractor{gen: gen, model: geminiModel, retry: defaultRetryPolicy, limiter: limiter}
+
+	var wg sync.WaitGroup
+	var failed atomic.Int32
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := g.Extract(context.Background(), []byte("%PDF")); err != nil {
+				failed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if failed.Load() != 0 {
+		t.Fatalf("%d extractions failed", failed.Load())
+	}
+	if gen.peak > 2 {
+		t.Errorf("peak concurrent calls = %d, want ≤ 2", gen.peak)
+	}
+}
+
+func TestCallLimiter_MinInterval(t *testing.T) {
+	l := newCallLimiter(0, 30*time.Millisecond)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		release, err := l.acquire(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+	}
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("3 calls took %v, want ≥ 60ms with a 30ms interval", elapsed)
+	}
+
+	l = newCallLimiter(1, time.Hour)
+	release, err := l.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := l.acquire(ctx); err == nil {
+		t.Error("acquire should fail when the context is canceled while waiting")
+	}
+}
+
+// TestGenerateFromPDF_ConcurrentRunReport processes two PDFs at once against one RunReport,
+// as sync does; run with -race.
+func TestGenerateFromPDF_ConcurrentRunReport(t *testing.T) {
+	dir := t.TempDir()
+	run := newRunReport("sync", "", false)
+	var wg sync.WaitGroup
+	for _, name := range []string{"a.pdf", "b.pdf"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("%PDF-"+name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		run.addPDF(PDFRecord{Path: path, Status: pdfNew})
+		out := filepath.Join(dir, name+"-services")
+		if err := os.MkdirAll(out, 0755); err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			generateFromPDF(context.Background(), path, generateOptions{
+				Extractors: []namedExtractor{{Label: "static", Extractor: staticExtractor{sampleExtracted()}}},
+				OutputDir:  out,
+				Run:        run,
+			})
+		}()
+	}
+	wg.Wait()
+	run.finish(exitNoChange)
+	if run.Summary.Generated != 4 {
+		t.Errorf("generated = %d, want 2 services from each PDF", run.Summary.Generated)
+	}
+}
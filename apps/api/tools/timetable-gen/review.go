@@ -0,0 +1,338 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultPendingDir is where tables with a low-confidence station match wait for review.
+const defaultPendingDir = "pending-review"
+
+// ReviewItem is one extracted table whose station name matched no route exactly or by alias.
+// It is saved as <pendingDir>/<ID>.json until an operator accepts, rejects or maps it.
+type ReviewItem struct {
+	ID          string           `json:"id"`
+	Source      string           `json:"source"` // PDF file name
+	TableIndex  int              `json:"tableIndex"`
+	CreatedAt   string           `json:"createdAt"`
+	StationName string           `json:"stationName"` // as extracted
+	Match       StationMatch     `json:"match"`
+	Periods     []ValidityPeriod `json:"periods,omitempty"` // CLI --from/--to used when mapping
+	Table       ExtractedTable   `json:"table"`
+	Services    []ServiceData    `json:"services,omitempty"` // mapped onto the guessed route, if any
+	MapError    string           `json:"mapError,omitempty"`
+}
+
+// splitForReview removes tables whose station match is not confident from data and returns
+// them as review items. Items get their ID and Source from the caller.
+func splitForReview(data *ExtractedData, periods []ValidityPeriod) (*ExtractedData, []ReviewItem) {
+	confident := &ExtractedData{}
+	var items []ReviewItem
+	for i, table := range data.Tables {
+		route, m := matchStation(table.StationName)
+		if m.confident() {
+			confident.Tables = append(confident.Tables, table)
+			continue
+		}
+		item := ReviewItem{
+			TableIndex:  i,
+			StationName: table.StationName,
+			Match:       m,
+			Periods:     periods,
+			Table:       table,
+		}
+		if m.Kind != matchNone {
+			services, err := mapTable(table, route, periods)
+			if err != nil {
+				item.MapError = err.Error()
+			}
+			item.Services = services
+		}
+		items = append(items, item)
+	}
+	return confident, items
+}
+
+// reviewItemID derives a stable ID from the PDF name and table index, so re-running the same
+// PDF overwrites its pending items instead of piling up duplicates.
+func reviewItemID(source string, tableIndex int) string {
+	stem := strings.TrimSuffix(source, filepath.Ext(source))
+	return fmt.Sprintf("%s-table%d", stem, tableIndex)
+}
+
+func saveReviewItem(dir string, item ReviewItem) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	data, err := marshalJSON(item)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, item.ID+".json")
+	return path, os.WriteFile(path, data, 0644)
+}
+
+// reviewItemPath returns the file of the pending item id under dir. The id comes from the
+// command line, so IDs that could point outside dir are rejected.
+func reviewItemPath(dir, id string) (string, error) {
+	id = strings.TrimSuffix(id, ".json")
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.Contains(id, "..") {
+		return "", fmt.Errorf("不正な保留項目 ID: %q", id)
+	}
+	return filepath.Join(dir, id+".json"), nil
+}
+
+func loadReviewItem(dir, id string) (ReviewItem, error) {
+	var item ReviewItem
+	path, err := reviewItemPath(dir, id)
+	if err != nil {
+		return item, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return item, err
+	}
+	err = json.Unmarshal(data, &item)
+	return item, err
+}
+
+func listReviewItems(dir string) ([]ReviewItem, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var items []ReviewItem
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		item, err := loadReviewItem(dir, e.Name())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Name(), err)
+		}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	return items, nil
+}
+
+// writeServices validates and writes services to outputDir. Nothing is written if any service
+// is invalid.
+func writeServices(outputDir string, services []ServiceData) error {
+	for _, svc := range services {
+		if errs := Validate(svc); len(errs) > 0 {
+			msgs := make([]string, len(errs))
+			for i, e := range errs {
+				msgs[i] = e.Error()
+			}
+			return fmt.Errorf("バリデーションエラー [%s]: %s", svc.ID, strings.Join(msgs, "; "))
+		}
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return err
+	}
+	for _, svc := range services {
+		data, err := marshalJSON(svc)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(outputDir, svc.ID+".json"), data, 0644); err != nil {
+			return err
+		}
+		fmt.Printf("  生成: %s.json\n", svc.ID)
+	}
+	return nil
+}
+
+// runReview implements `timetable-gen review`:
+//
+//	review [list]                 保留中の項目を一覧表示
+//	review accept <id>            推定された路線のまま出力する
+//	review reject <id>            破棄する
+//	review map <id> <route-key>   指定した路線で再マッピングして出力する
+func runReview(args []string) {
+	pendingDir := defaultPendingDir
+	outputDir := "../../data/services"
+	routesPath := defaultRoutesPath
+
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--dir":
+			if i+1 < len(args) {
+				pendingDir = args[i+1]
+				i++
+			}
+		case "--output":
+			if i+1 < len(args) {
+				outputDir = args[i+1]
+				i++
+			}
+		case "--routes":
+			if i+1 < len(args) {
+				routesPath = args[i+1]
+				i++
+			}
+		default:
+			positional = append(positional, args[i])
+		}
+	}
+
+	cmd := "list"
+	if len(positional) > 0 {
+		cmd, positional = positional[0], positional[1:]
+	}
+
+	switch cmd {
+	case "list":
+		items, err := listReviewItems(pendingDir)
+		if err != nil {
+			log.Fatalf("保留項目の読み込み失敗: %v", err)
+		}
+		printReviewItems(items)
+	case "accept":
+		id := requireArg(positional, 0, "review accept <id>")
+		if err := acceptReviewItem(pendingDir, outputDir, id); err != nil {
+			log.Fatal(err)
+		}
+	case "reject":
+		id := requireArg(positional, 0, "review reject <id>")
+		if err := rejectReviewItem(pendingDir, id); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("破棄: %s\n", id)
+	case "map":
+		id := requireArg(positional, 0, "review map <id> <route-key>")
+		key := requireArg(positional, 1, "review map <id> <route-key>")
+		routes, err := loadRouteConfig(routesPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		useRouteConfig(routes)
+		if err := mapReviewItem(pendingDir, outputDir, id, key); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		log.Fatalf("不明なサブコマンド: review %s (list|accept|reject|map)", cmd)
+	}
+}
+
+func requireArg(args []string, i int, usage string) string {
+	if i >= len(args) {
+		log.Fatalf("使い方: timetable-gen %s", usage)
+	}
+	return args[i]
+}
+
+func printReviewItems(items []ReviewItem) {
+	if len(items) == 0 {
+		fmt.Println("保留中の項目はありません。")
+		return
+	}
+	for _, item := range items {
+		fmt.Printf("[%s] %s (表 %d)\n", item.ID, item.Source, item.TableIndex)
+		fmt.Printf("  駅名: %q  一致: %s (score %.2f)\n", item.StationName, item.Match.Kind, item.Match.Score)
+		fmt.Printf("  理由: %s\n", item.Match.Reason)
+		if item.MapError != "" {
+			fmt.Printf("  マッピングエラー: %s\n", item.MapError)
+		}
+		for _, svc := range item.Services {
+			fmt.Printf("  候補: %s.json\n", svc.ID)
+		}
+	}
+	fmt.Printf("\n合計: %d 件\n", len(items))
+}
+
+// acceptReviewItem writes the services mapped onto the guessed route and removes the item.
+func acceptReviewItem(pendingDir, outputDir, id string) error {
+	item, err := loadReviewItem(pendingDir, id)
+	if err != nil {
+		return fmt.Errorf("保留項目の読み込み失敗: %w", err)
+	}
+	if len(item.Services) == 0 {
+		return fmt.Errorf("%s には推定された路線がありません。review map %s <route-key> を使ってください", item.ID, item.ID)
+	}
+	if err := writeServices(outputDir, item.Services); err != nil {
+		return err
+	}
+	return rejectReviewItem(pendingDir, item.ID)
+}
+
+// mapReviewItem re-maps the item's table onto the route with the given key, writes the result
+// and removes the item. The route table must already be loaded.
+func mapReviewItem(pendingDir, outputDir, id, key string) error {
+	item, err := loadReviewItem(pendingDir, id)
+	if err != nil {
+		return fmt.Errorf("保留項目の読み込み失敗: %w", err)
+	}
+	route, ok := routeByKey(key)
+	if !ok {
+		return fmt.Errorf("路線キー %q は路線設定にありません", key)
+	}
+	services, err := mapTable(item.Table, route, item.Periods)
+	if err != nil {
+		return err
+	}
+	if err := writeServices(outputDir, services); err != nil {
+		return err
+	}
+	fmt.Printf("※ 今後も自動で対応させるには station_routes.json の %q の aliases に %q を追加してください\n", key, item.StationName)
+	return rejectReviewItem(pendingDir, item.ID)
+}
+
+// rejectReviewItem removes the pending item id. The file must hold a review item with that ID,
+// so that a mistyped ID cannot delete some other JSON file in the directory.
+func rejectReviewItem(pendingDir, id string) error {
+	item, err := loadReviewItem(pendingDir, id)
+	if err != nil {
+		return err
+	}
+	if item.ID != strings.TrimSuffix(id, ".json") {
+		return fmt.Errorf("%s は保留項目ではありません", id)
+	}
+	path, err := reviewItemPath(pendingDir, id)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// queueForReview saves items under dir, or only prints them with dryRun, and records them in
+// run. It returns the number of items that could not be saved.
+func queueForReview(dir, source string, items []ReviewItem, dryRun bool, run *RunReport) (failed int) {
+	for _, item := range items {
+		item.ID = reviewItemID(source, item.TableIndex)
+		item.Source = source
+		item.CreatedAt = time.Now().Format(time.RFC3339)
+		log.Printf("要確認 [%s]: %s", item.ID, item.Match.Reason)
+
+		rec := ServiceRecord{ID: item.ID, Source: source, Status: servicePending, Errors: []string{item.Match.Reason}}
+		if dryRun {
+			fmt.Printf("  [要確認] %s (%s)\n", item.ID, item.StationName)
+			run.addService(rec)
+			continue
+		}
+		path, err := saveReviewItem(dir, item)
+		if err != nil {
+			log.Printf("保留項目の保存失敗 [%s]: %v", item.ID, err)
+			rec.Status = serviceFailed
+			rec.Errors = append(rec.Errors, err.Error())
+			run.addService(rec)
+			failed++
+			continue
+		}
+		fmt.Printf("  要確認: %s\n", path)
+		rec.File = path
+		run.addService(rec)
+	}
+	return failed
+}
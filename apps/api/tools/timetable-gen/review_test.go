@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatchStation_Kinds(t *testing.T) {
+	tests := []struct {
+		input    string
+		wantKind string
+		wantKey  string
+	}{
+		{"八王子駅", matchExact, "hachioji"},
+		{"みなみ野駅", matchAlias, "minamino"},
+		{"JR八王子駅北口", matchFuzzy, "hachioji"},
+		{"学生会館前停留所", matchFuzzy, "gakuseikaikan"},
+		{"存在しない駅", matchNone, ""},
+		{"", matchNone, ""},
+	}
+	for _, tt := range tests {
+		r, m := matchStation(tt.input)
+		if m.Kind != tt.wantKind || r.Key != tt.wantKey {
+			t.Errorf("matchStation(%q) = (%q, %q), want (%q, %q)", tt.input, r.Key, m.Kind, tt.wantKey, tt.wantKind)
+		}
+		if m.Kind == matchFuzzy && (m.Score <= 0 || m.Score > 0.5) {
+			t.Errorf("matchStation(%q) fuzzy score = %v, want (0, 0.5]", tt.input, m.Score)
+		}
+	}
+}
+
+// reviewFixture writes a PDF stand-in and returns generateOptions whose extraction has one
+// confident table and one fuzzy-matched table.
+func reviewFixture(t *testing.T) (pdfPath string, opts generateOptions) {
+	t.Helper()
+	dir := t.TempDir()
+	pdfPath = filepath.Join(dir, "260407.pdf")
+	if err := os.WriteFile(pdfPath, []byte("%PDF-fake"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	extracted := sampleExtracted()
+	fuzzy := extracted.Tables[0]
+	fuzzy.StationName = "JR八王子駅北口"
+	fuzzy.DayType = "saturday"
+	extracted.Tables = append(extracted.Tables, fuzzy)
+
+	opts = generateOptions{
+		Extractors: []namedExtractor{{Label: "static", Extractor: staticExtractor{extracted}}},
+		OutputDir:  filepath.Join(dir, "services"),
+		PendingDir: filepath.Join(dir, "pending"),
+	}
+	if err := os.MkdirAll(opts.OutputDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	return pdfPath, opts
+}
+
+func TestGenerateFromPDF_QueuesFuzzyMatch(t *testing.T) {
+	pdfPath, opts := reviewFixture(t)
+
+	res := generateFromPDF(context.Background(), pdfPath, opts)
+	if res.Saved != 2 || res.Pending != 1 || res.Failed != 0 {
+		t.Fatalf("generateFromPDF = %+v, want 2 saved and 1 pending", res)
+	}
+	if files, _ := filepath.Glob(filepath.Join(opts.OutputDir, "*saturday*.json")); len(files) != 0 {
+		t.Errorf("fuzzy-matched services must not be written, found %v", files)
+	}
+
+	items, err := listReviewItems(opts.PendingDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected 1 review item, got %d", len(items))
+	}
+	item := items[0]
+	if item.ID != "260407-table1" || item.Match.Kind != matchFuzzy || len(item.Services) != 2 {
+		t.Errorf("review item = %s kind=%s services=%d", item.ID, item.Match.Kind, len(item.Services))
+	}
+}
+
+func TestReview_AcceptRejectMap(t *testing.T) {
+	pdfPath, opts := reviewFixture(t)
+	generateFromPDF(context.Background(), pdfPath, opts)
+
+	if err := acceptReviewItem(opts.PendingDir, opts.OutputDir, "260407-table1"); err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(opts.OutputDir, "*hachioji*saturday*.json")); len(files) != 2 {
+		t.Errorf("accept should write the guessed route's services, found %v", files)
+	}
+	if items, _ := listReviewItems(opts.PendingDir); len(items) != 0 {
+		t.Errorf("accepted item should be removed, %d left", len(items))
+	}
+
+	generateFromPDF(context.Background(), pdfPath, opts)
+	if err := mapReviewItem(opts.PendingDir, opts.OutputDir, "260407-table1", "gakuseikaikan"); err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(opts.OutputDir, "*gakuseikaikan*saturday*.json")); len(files) != 2 {
+		t.Errorf("map should write services on the chosen route, found %v", files)
+	}
+	if err := mapReviewItem(opts.PendingDir, opts.OutputDir, "260407-table1", "gakuseikaikan"); err == nil {
+		t.Error("mapped item should be gone from the queue")
+	}
+
+	generateFromPDF(context.Background(), pdfPath, opts)
+	if err := rejectReviewItem(opts.PendingDir, "260407-table1"); err != nil {
+		t.Fatal(err)
+	}
+	if items, _ := listReviewItems(opts.PendingDir); len(items) != 0 {
+		t.Errorf("rejected item should be removed, %d left", len(items))
+	}
+}
+
+func TestReview_RejectsIDsOutsidePendingDir(t *testing.T) {
+	pdfPath, opts := reviewFixture(t)
+	generateFromPDF(context.Background(), pdfPath, opts)
+
+	// 保留項目のディレクトリの外にある運行データ
+	service := filepath.Join(opts.OutputDir, "hachioji-to-school-weekday.json")
+	writeJSON(t, service, validService())
+	for _, id := range []string{
+		"../services/hachioji-to-school-weekday",
+		"..\\services\\hachioji-to-school-weekday",
+		filepath.Join(opts.OutputDir, "hachioji-to-school-weekday"),
+		"..",
+	} {
+		if err := rejectReviewItem(opts.PendingDir, id); err == nil {
+			t.Errorf("reject %q: expected error", id)
+		}
+		if err := acceptReviewItem(opts.PendingDir, opts.OutputDir, id); err == nil {
+			t.Errorf("accept %q: expected error", id)
+		}
+	}
+	if _, err := os.Stat(service); err != nil {
+		t.Errorf("service file outside the pending dir was touched: %v", err)
+	}
+
+	// 保留項目のディレクトリにあっても保留項目でない JSON は消さない
+	other := filepath.Join(opts.PendingDir, "notes.json")
+	writeJSON(t, other, validService())
+	if err := rejectReviewItem(opts.PendingDir, "notes"); err == nil {
+		t.Error("reject notes: expected error for a file that is not a pending item")
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("non-item file was removed: %v", err)
+	}
+}
+
+func TestMapReviewItem_UnknownRoute(t *testing.T) {
+	pdfPath, opts := reviewFixture(t)
+	generateFromPDF(context.Background(), pdfPath, opts)
+	if err := mapReviewItem(opts.PendingDir, opts.OutputDir, "260407-table1", "nowhere"); err == nil {
+		t.Error("expected error for unknown route key")
+	}
+}
@@ -2,25 +2,47 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
-
-	"github.com/google/generative-ai-go/genai"
-	"google.golang.org/api/option"
 )
 
 func runSync(args []string) {
 	downloadDir := "downloaded"
 	outputDir := "../../data/services"
 	apiKey := os.Getenv("GEMINI_API_KEY")
+	backend := extractorGemini
+	fixtureDir := ""
+	cacheDir := defaultCacheDir
+	forceExtract := false
+	runs := 1
+	crossCheck := ""
+	reportPath := ""
+	runReportPath := ""
+	routesPath := defaultRoutesPath
+	pendingDir := defaultPendingDir
+	baseURL, pageURL := "", ""
 	restartAPI := false
+	dryRun := false
+	showDiff := false
+	parallel := defaultParallel
+	maxAttempts := defaultRetryPolicy.MaxAttempts
+	retryDelay := defaultRetryPolicy.BaseDelay
+	maxConcurrency := defaultMaxConcurrency
+	var minInterval time.Duration
+	var policy archivePolicy
 
 	for i, a := range args {
+		if parseArchivePolicyArg(args, i, &policy) {
+			continue
+		}
 		switch a {
 		case "--download-dir":
 			if i+1 < len(args) {
@@ -34,54 +56,201 @@ func runSync(args []string) {
 			if i+1 < len(args) {
 				apiKey = args[i+1]
 			}
+		case "--extractor":
+			if i+1 < len(args) {
+				backend = args[i+1]
+			}
+		case "--fixture-dir":
+			if i+1 < len(args) {
+				fixtureDir = args[i+1]
+			}
+		case "--cache-dir":
+			if i+1 < len(args) {
+				cacheDir = args[i+1]
+			}
+		case "--force-extract":
+			forceExtract = true
+		case "--runs":
+			if i+1 < len(args) {
+				runs = positiveIntArg("--runs", args[i+1])
+			}
+		case "--cross-check":
+			if i+1 < len(args) {
+				crossCheck = args[i+1]
+			}
+		case "--disagreement-report":
+			if i+1 < len(args) {
+				reportPath = args[i+1]
+			}
+		case "--report":
+			if i+1 < len(args) {
+				runReportPath = args[i+1]
+			}
+		case "--routes":
+			if i+1 < len(args) {
+				routesPath = args[i+1]
+			}
+		case "--pending-dir":
+			if i+1 < len(args) {
+				pendingDir = args[i+1]
+			}
+		case "--base-url":
+			if i+1 < len(args) {
+				baseURL = args[i+1]
+			}
+		case "--page-url":
+			if i+1 < len(args) {
+				pageURL = args[i+1]
+			}
+		case "--parallel":
+			if i+1 < len(args) {
+				parallel = positiveIntArg("--parallel", args[i+1])
+			}
+		case "--max-attempts":
+			if i+1 < len(args) {
+				maxAttempts = positiveIntArg("--max-attempts", args[i+1])
+			}
+		case "--max-concurrency":
+			if i+1 < len(args) {
+				maxConcurrency = positiveIntArg("--max-concurrency", args[i+1])
+			}
+		case "--retry-delay":
+			if i+1 < len(args) {
+				retryDelay = durationArg("--retry-delay", args[i+1])
+			}
+		case "--min-interval":
+			if i+1 < len(args) {
+				minInterval = durationArg("--min-interval", args[i+1])
+			}
 		case "--restart-api":
 			restartAPI = true
+		case "--dry-run":
+			dryRun = true
+		case "--diff":
+			showDiff = true
 		}
 	}
 
-	if apiKey == "" {
-		log.Fatal("GEMINI_API_KEY を設定するか --api-key を指定してください")
+	run := newRunReport("sync", runReportPath, dryRun)
+
+	if backend == extractorGemini && apiKey == "" {
+		run.fatalf("GEMINI_API_KEY を設定するか --api-key を指定してください")
+	}
+	routes, err := loadRouteConfig(routesPath)
+	if err != nil {
+		run.fatalf("%v", err)
 	}
+	useRouteConfig(routes)
 
 	// 1. 新規・更新 PDF をフェッチ
-	newFiles, _, err := fetchNewPDFs(downloadDir)
+	// dry-run では取得状態を保存せず（本番実行で同じ PDF を再処理させるため）、
+	// PDF も downloaded/ ではなく終了時に削除する一時ディレクトリに保存する
+	saveDir := downloadDir
+	if dryRun {
+		tmp, err := os.MkdirTemp("", "timetable-gen-dry-run-")
+		if err != nil {
+			run.fatalf("一時ディレクトリ作成失敗: %v", err)
+		}
+		run.onFinish(func() { os.RemoveAll(tmp) })
+		saveDir = tmp
+	}
+	newFiles, _, err := newFetcher(baseURL, pageURL).fetchNewPDFs(downloadDir, saveDir, dryRun, run)
 	if err != nil {
-		log.Fatalf("fetch 失敗: %v", err)
+		run.fatalf("fetch 失敗: %v", err)
 	}
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		log.Fatalf("出力ディレクトリ作成失敗: %v", err)
+	if !dryRun {
+		if err := os.MkdirAll(outputDir, 0755); err != nil {
+			run.fatalf("出力ディレクトリ作成失敗: %v", err)
+		}
 	}
 
 	// 2. 期限切れサービスをアーカイブ（新規PDFがなくても実行）
-	archived := archiveExpired(outputDir)
+	archived := archiveExpired(outputDir, policy, dryRun, run)
 	if archived > 0 {
-		fmt.Printf("アーカイブ: %d 件\n", archived)
+		if dryRun {
+			fmt.Printf("アーカイブ予定: %d 件\n", archived)
+		} else {
+			fmt.Printf("アーカイブ: %d 件\n", archived)
+		}
 	}
 
 	if len(newFiles) == 0 {
 		fmt.Println("新規・更新 PDF なし。終了します。")
+		if dryRun || showDiff {
+			run.exit(diffExitCode(0, archived))
+		}
+		run.finish(exitNoChange)
 		return
 	}
 	fmt.Printf("新規・更新 PDF: %d 件\n", len(newFiles))
 
 	ctx := context.Background()
-	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
+	extractors, closeExtractors, err := newExtractors(ctx, extractorOptions{
+		Backend:       backend,
+		APIKey:        apiKey,
+		FixtureDir:    fixtureDir,
+		CacheDir:      cacheDir,
+		CacheReadOnly: dryRun,
+		ForceExtract:  forceExtract,
+		Retry:         newRetryPolicy(maxAttempts, retryDelay),
+		Limiter:       newCallLimiter(maxConcurrency, minInterval),
+	}, runs, crossCheck)
 	if err != nil {
-		log.Fatalf("Gemini クライアント作成失敗: %v", err)
+		run.fatalf("extractor 作成失敗: %v", err)
 	}
-	defer client.Close()
+	defer closeExtractors()
+
+	report, closeReport, err := openReport(reportPath)
+	if err != nil {
+		run.fatalf("%v", err)
+	}
+	defer closeReport()
 
 	// 3. 各 PDF を JSON に変換
-	totalSaved, totalFailed := 0, 0
-	for _, pdf := range newFiles {
-		fmt.Printf("\n--- %s (%s) ---\n", filepath.Base(pdf.Path), pdf.Title)
+	// 抽出だけを最大 parallel 件並行に行い、差分表示・レポート・ファイル書き込みは newFiles の順に 1 件ずつ行う。
+	// Gemini の呼び出し数は PDF の並行数とは別に Limiter で制限される
+	opts := generateOptions{
+		Extractors: extractors,
+		OutputDir:  outputDir,
+		Report:     report,
+		DryRun:     dryRun,
+		Diff:       showDiff,
+		Run:        run,
+		PendingDir: pendingDir,
+	}
+	extracted := make([]pdfExtraction, len(newFiles))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, pdf := range newFiles {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			extracted[i] = extractPDF(ctx, pdf.Path, opts)
+		}()
+	}
+	wg.Wait()
+
+	if err := checkDuplicateServiceIDs(extracted); err != nil {
+		run.fatalf("%v", err)
+	}
 
-		saved, failed := generateFromPDF(ctx, client, pdf.Path, outputDir)
-		totalSaved += saved
-		totalFailed += failed
+	var total generateResult
+	for i, pdf := range newFiles {
+		fmt.Printf("\n--- %s (%s) ---\n", filepath.Base(pdf.Path), pdf.Title)
+		total.add(writePDFResult(extracted[i], opts))
 	}
+	totalSaved, totalFailed := total.Saved, total.Failed
 
+	if dryRun {
+		fmt.Printf("\n合計 (dry-run): 変更 %d 件 / 失敗 %d 件\n", total.Changed, totalFailed)
+		run.exit(diffExitCode(totalFailed, total.Changed+archived))
+	}
 	fmt.Printf("\n合計: 生成 %d 件 / 失敗 %d 件\n", totalSaved, totalFailed)
+	if total.Pending > 0 {
+		fmt.Printf("要確認: %d 件 (timetable-gen review で確認してください)\n", total.Pending)
+	}
 
 	if totalFailed > 0 {
 		log.Printf("警告: %d 件の生成に失敗しました", totalFailed)
@@ -99,121 +268,222 @@ func runSync(args []string) {
 		fmt.Println("\n※ API を再起動してください: docker restart api")
 	}
 
+	if showDiff {
+		run.exit(diffExitCode(totalFailed, total.Changed+archived))
+	}
 	if totalFailed > 0 {
-		os.Exit(1)
+		run.exit(exitError)
 	}
+	run.finish(exitNoChange)
 }
 
-func archiveExpired(servicesDir string) int {
-	jst, err := time.LoadLocation("Asia/Tokyo")
-	if err != nil {
-		jst = time.FixedZone("JST", 9*60*60)
+// defaultParallel is how many PDFs sync processes at once, and defaultMaxConcurrency how many
+// Gemini calls may be in flight across them.
+const (
+	defaultParallel       = 2
+	defaultMaxConcurrency = 2
+)
+
+func positiveIntArg(name, v string) int {
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		log.Fatalf("%s には 1 以上の整数を指定してください: %q", name, v)
 	}
-	today := time.Now().In(jst).Format("2006-01-02")
-	archiveDir := filepath.Join(servicesDir, "archived")
+	return n
+}
 
-	entries, err := os.ReadDir(servicesDir)
-	if err != nil {
-		log.Printf("ディレクトリ読み込み失敗: %v", err)
-		return 0
+func durationArg(name, v string) time.Duration {
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		log.Fatalf("%s には 0 以上の時間を指定してください (例: 2s, 500ms): %q", name, v)
 	}
+	return d
+}
 
-	count := 0
-	for _, e := range entries {
-		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
-			continue
-		}
+// generateOptions configures generateFromPDF.
+type generateOptions struct {
+	Extractors []namedExtractor
+	OutputDir  string
+	Periods    []ValidityPeriod // CLI --from/--to; nil means use the periods found in the PDF
+	Report     io.Writer        // destination of the disagreement report when runs disagree
+	DryRun     bool             // print the diff against OutputDir instead of writing files
+	Diff       bool             // print the diff against OutputDir before writing
+	Run        *RunReport       // --report; may be nil
+	PendingDir string           // review queue for tables with a low-confidence station match
+}
 
-		path := filepath.Join(servicesDir, e.Name())
-		data, err := os.ReadFile(path)
-		if err != nil {
-			continue
-		}
+// generateResult counts the outcome of generateFromPDF. Changed counts services that are new
+// or differ semantically from the file already in OutputDir; it is only filled in with
+// DryRun or Diff.
+type generateResult struct {
+	Saved   int
+	Failed  int
+	Changed int
+	Pending int // tables sent to the review queue
+}
 
-		var svc struct {
-			ValidityPeriods []struct {
-				To string `json:"to"`
-			} `json:"validityPeriods"`
-		}
-		if err := json.Unmarshal(data, &svc); err != nil || len(svc.ValidityPeriods) == 0 {
-			continue
-		}
+func (r *generateResult) add(o generateResult) {
+	r.Saved += o.Saved
+	r.Failed += o.Failed
+	r.Changed += o.Changed
+	r.Pending += o.Pending
+}
 
-		// 全期間の最大 to を求める
-		var maxTo time.Time
-		for _, vp := range svc.ValidityPeriods {
-			t, err := time.Parse("2006-01-02", vp.To)
-			if err != nil {
-				continue
-			}
-			if t.After(maxTo) {
-				maxTo = t
-			}
-		}
-		todayTime, _ := time.Parse("2006-01-02", today)
-		if maxTo.IsZero() || !maxTo.Before(todayTime) {
-			continue
-		}
+func generateFromPDF(ctx context.Context, pdfPath string, opts generateOptions) generateResult {
+	return writePDFResult(extractPDF(ctx, pdfPath, opts), opts)
+}
 
-		if err := os.MkdirAll(archiveDir, 0755); err != nil {
-			log.Printf("archived/ 作成失敗: %v", err)
-			continue
-		}
-		dst := filepath.Join(archiveDir, e.Name())
-		if err := os.Rename(path, dst); err != nil {
-			log.Printf("アーカイブ失敗 %s: %v", e.Name(), err)
-			continue
-		}
-		fmt.Printf("  アーカイブ: %s (期限: %s)\n", e.Name(), maxTo.Format("2006-01-02"))
-		count++
-	}
-	return count
+// pdfExtraction is the outcome of extracting one PDF, before anything is printed or written.
+type pdfExtraction struct {
+	Path          string
+	Services      []ServiceData
+	Pending       []ReviewItem
+	Disagreements []Disagreement
+	Err           error
+	Elapsed       time.Duration
 }
 
-func generateFromPDF(ctx context.Context, client *genai.Client, pdfPath, outputDir string) (saved, failed int) {
+// extractPDF reads and extracts the PDF at pdfPath. It only logs, so several PDFs may be
+// extracted concurrently; writePDFResult does the output and must be called serially.
+func extractPDF(ctx context.Context, pdfPath string, opts generateOptions) pdfExtraction {
+	started := time.Now()
+	x := pdfExtraction{Path: pdfPath}
 	pdfData, err := os.ReadFile(pdfPath)
 	if err != nil {
-		log.Printf("PDF 読み込み失敗 %s: %v", pdfPath, err)
-		return 0, 1
+		x.Err = fmt.Errorf("PDF 読み込み失敗: %w", err)
+	} else {
+		x.Services, x.Pending, x.Disagreements, x.Err = extractServices(ctx, opts.Extractors, pdfData, opts.Periods)
 	}
+	x.Elapsed = time.Since(started)
+	return x
+}
 
-	extracted, err := Extract(ctx, client, pdfData)
-	if err != nil {
-		log.Printf("抽出失敗 %s: %v", pdfPath, err)
-		return 0, 1
+// checkDuplicateServiceIDs fails when two PDFs produce a service with the same ID,
+// since both would be written to the same file and the result would depend on order.
+func checkDuplicateServiceIDs(extracted []pdfExtraction) error {
+	seen := map[string]string{} // service ID → PDF file name
+	var dups []string
+	for _, x := range extracted {
+		source := filepath.Base(x.Path)
+		for _, svc := range x.Services {
+			if other, ok := seen[svc.ID]; ok && other != source {
+				dups = append(dups, fmt.Sprintf("%s (%s, %s)", svc.ID, other, source))
+				continue
+			}
+			seen[svc.ID] = source
+		}
+	}
+	if len(dups) > 0 {
+		return fmt.Errorf("複数の PDF が同じ ID のサービスを生成しました: %s", strings.Join(dups, ", "))
 	}
+	return nil
+}
 
-	services, err := Map(extracted, nil)
-	if err != nil {
-		log.Printf("マッピング失敗 %s: %v", pdfPath, err)
-		return 0, 1
+// writePDFResult reports and writes the services extracted from one PDF.
+func writePDFResult(x pdfExtraction, opts generateOptions) (res generateResult) {
+	started := time.Now()
+	pdfPath := x.Path
+	defer func() { opts.Run.setGenerateTime(pdfPath, x.Elapsed+time.Since(started)) }()
+	source := filepath.Base(pdfPath)
+
+	if x.Err != nil {
+		log.Printf("%s: %v", pdfPath, x.Err)
+		opts.Run.setPDFError(pdfPath, x.Err)
+		res.Failed = 1
+		return res
+	}
+	services, pending, disagreements := x.Services, x.Pending, x.Disagreements
+	if len(disagreements) > 0 {
+		ids := disagreedServiceIDs(disagreements)
+		log.Printf("抽出結果が一致しないため %d 件を出力しません: %v", len(ids), ids)
+		if opts.Report != nil {
+			writeDisagreementReport(opts.Report, source, disagreements)
+		}
+		for _, id := range ids {
+			rec := ServiceRecord{ID: id, Source: source, Status: serviceFailed}
+			for _, d := range disagreements {
+				if d.ServiceID == id {
+					rec.Errors = append(rec.Errors, "disagreement: "+d.String())
+				}
+			}
+			opts.Run.addService(rec)
+		}
+		res.Failed += len(ids)
+	}
+	if len(pending) > 0 {
+		res.Failed += queueForReview(opts.PendingDir, source, pending, opts.DryRun, opts.Run)
+		res.Pending += len(pending)
 	}
 
 	for _, svc := range services {
+		rec := ServiceRecord{ID: svc.ID, Source: source}
+		fail := func(errs ...error) {
+			rec.Status = serviceFailed
+			for _, e := range errs {
+				rec.Errors = append(rec.Errors, e.Error())
+			}
+			opts.Run.addService(rec)
+			res.Failed++
+		}
+
 		if errs := Validate(svc); len(errs) > 0 {
 			log.Printf("バリデーションエラー [%s]:", svc.ID)
 			for _, e := range errs {
 				log.Printf("  - %v", e)
 			}
-			failed++
+			fail(errs...)
 			continue
 		}
 
+		if opts.DryRun || opts.Diff {
+			change, err := printServiceDiff(opts.OutputDir, svc)
+			if err != nil {
+				log.Printf("既存ファイル読み込み失敗 [%s]: %v", svc.ID, err)
+				fail(err)
+				continue
+			}
+			rec.Change = change
+			if change != changeUnchanged {
+				res.Changed++
+			}
+			if opts.DryRun {
+				rec.Status = serviceDryRun
+				opts.Run.addService(rec)
+				continue
+			}
+		}
+
 		data, err := marshalJSON(svc)
 		if err != nil {
 			log.Printf("JSON 整形失敗 [%s]: %v", svc.ID, err)
-			failed++
+			fail(err)
 			continue
 		}
 
-		outPath := filepath.Join(outputDir, svc.ID+".json")
+		outPath := filepath.Join(opts.OutputDir, svc.ID+".json")
 		if err := os.WriteFile(outPath, data, 0644); err != nil {
 			log.Printf("書き込み失敗 [%s]: %v", svc.ID, err)
-			failed++
+			fail(err)
 			continue
 		}
 		fmt.Printf("  生成: %s.json\n", svc.ID)
-		saved++
+		rec.Status = serviceGenerated
+		rec.File = outPath
+		opts.Run.addService(rec)
+		res.Saved++
+	}
+	return res
+}
+
+// openReport returns the writer for the disagreement report: stdout, plus the file at path
+// when one is given. The returned close function is never nil.
+func openReport(path string) (io.Writer, func(), error) {
+	if path == "" {
+		return os.Stdout, func() {}, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("レポートファイル作成失敗: %w", err)
 	}
-	return saved, failed
+	return io.MultiWriter(os.Stdout, f), func() { f.Close() }, nil
 }
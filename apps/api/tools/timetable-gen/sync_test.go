@@ -0,0 +1,25 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckDuplicateServiceIDs(t *testing.T) {
+	extracted := []pdfExtraction{
+		{Path: "dl/a.pdf", Services: []ServiceData{{ID: "2026-spring-weekday"}, {ID: "2026-spring-saturday"}}},
+		{Path: "dl/b.pdf", Services: []ServiceData{{ID: "2026-summer-weekday"}}},
+	}
+	if err := checkDuplicateServiceIDs(extracted); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	extracted = append(extracted, pdfExtraction{Path: "dl/c.pdf", Services: []ServiceData{{ID: "2026-spring-weekday"}}})
+	err := checkDuplicateServiceIDs(extracted)
+	if err == nil {
+		t.Fatal("want error for a service ID produced by two PDFs")
+	}
+	if !strings.Contains(err.Error(), "2026-spring-weekday (a.pdf, c.pdf)") {
+		t.Errorf("error = %v", err)
+	}
+}
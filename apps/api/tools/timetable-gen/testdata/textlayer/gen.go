@@ -0,0 +1,149 @@
+//go:build ignore
+
+// gen writes sample.pdf, a one-page timetable with a real text layer used by
+// TestTextLayerExtractor_FixturePDF. Run it from the timetable-gen directory:
+//
+//	go run testdata/textlayer/gen.go
+//
+// The page mirrors the layout of the published PDFs: a title with the operating period,
+// a day-type heading, and two side-by-side 3-column tables, one with a shuttle row.
+// Glyphs use a simple font whose ToUnicode CMap maps one byte codes to the characters used,
+// so no font program needs to be embedded.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+)
+
+type text struct {
+	x, y float64
+	s    string
+}
+
+func page() []text {
+	texts := []text{
+		{50, 800, "4月7日(月)～7月29日(水)"},
+		{200, 800, "基本運行時刻表（2026年度）"},
+		{50, 770, "平日（月～金）"},
+		{50, 750, "八王子駅南口"},
+		{300, 750, "八王子みなみ野駅"},
+	}
+	left := [][]string{
+		{"7:30", "7:50", "8:10"},
+		{"8:00", "8:20", "8:40"},
+		{"～", "～", "～"},
+		{"10:00", "10:20", "10:40"},
+		{"11:00", "11:20", "11:40"},
+	}
+	right := [][]string{
+		{"7:40", "7:50", "8:00"},
+		{"8:40", "8:50", "9:00"},
+		{"9:40", "9:50", "10:00"},
+		{"10:40", "10:50", "11:00"},
+	}
+	for i, row := range left {
+		y := 720 - float64(i)*15
+		for j, cell := range row {
+			texts = append(texts, text{50 + float64(j)*50, y, cell})
+		}
+		if row[0] == "～" {
+			texts = append(texts, text{210, y, "約3〜5分間隔"})
+		}
+	}
+	for i, row := range right {
+		y := 720 - float64(i)*15
+		for j, cell := range row {
+			texts = append(texts, text{300 + float64(j)*50, y, cell})
+		}
+	}
+	return texts
+}
+
+func main() {
+	texts := page()
+
+	// Assign one byte codes (from 1) to every distinct character.
+	codes := map[rune]byte{}
+	var runes []rune
+	for _, t := range texts {
+		for _, r := range t.s {
+			if _, ok := codes[r]; !ok {
+				if len(runes) == 255 {
+					log.Fatal("too many distinct characters for a one byte font")
+				}
+				runes = append(runes, r)
+				codes[r] = byte(len(runes))
+			}
+		}
+	}
+
+	var content bytes.Buffer
+	for _, t := range texts {
+		fmt.Fprintf(&content, "BT /F1 10 Tf %g %g Td <", t.x, t.y)
+		for _, r := range t.s {
+			fmt.Fprintf(&content, "%02X", codes[r])
+		}
+		content.WriteString("> Tj ET\n")
+	}
+
+	var cmap bytes.Buffer
+	cmap.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n")
+	cmap.WriteString("/CMapName /Sample-UTF16 def\n/CMapType 2 def\n")
+	cmap.WriteString("1 begincodespacerange\n<01> <FF>\nendcodespacerange\n")
+	for i := 0; i < len(runes); i += 100 {
+		chunk := runes[i:min(i+100, len(runes))]
+		fmt.Fprintf(&cmap, "%d beginbfchar\n", len(chunk))
+		for _, r := range chunk {
+			fmt.Fprintf(&cmap, "<%02X> <%04X>\n", codes[r], r)
+		}
+		cmap.WriteString("endbfchar\n")
+	}
+	cmap.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+
+	var widths bytes.Buffer
+	for _, r := range runes {
+		if utf8.RuneLen(r) == 1 {
+			widths.WriteString("500 ")
+		} else {
+			widths.WriteString("1000 ")
+		}
+	}
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Resources << /Font << /F1 4 0 R >> >> /Contents 5 0 R >>",
+		fmt.Sprintf("<< /Type /Font /Subtype /TrueType /BaseFont /SampleGothic /FirstChar 1 /LastChar %d /Widths [%s] /ToUnicode 6 0 R >>", len(runes), bytes.TrimSpace(widths.Bytes())),
+		stream(content.Bytes()),
+		stream(cmap.Bytes()),
+	}
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	path := filepath.Join("testdata", "textlayer", "sample.pdf")
+	if err := os.WriteFile(path, out.Bytes(), 0644); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("wrote %s (%d bytes)", path, out.Len())
+}
+
+func stream(data []byte) string {
+	return fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(data), data)
+}
@@ -0,0 +1,454 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// TextLayerExtractor reconstructs timetable tables from the PDF text layer without any LLM.
+// It reads glyph positions, groups them into words and lines, and rebuilds the 3-column
+// tables from the X positions of time cells. The output is deterministic for a given PDF.
+type TextLayerExtractor struct{}
+
+func NewTextLayerExtractor() *TextLayerExtractor {
+	return &TextLayerExtractor{}
+}
+
+func (e *TextLayerExtractor) Extract(_ context.Context, pdfData []byte) (*ExtractedData, error) {
+	pages, err := readPDFWords(pdfData)
+	if err != nil {
+		return nil, err
+	}
+
+	var extracted ExtractedData
+	for i, words := range pages {
+		tables := buildTablesFromWords(words)
+		log.Printf("テキストレイヤー解析: ページ %d から %d テーブル", i+1, len(tables))
+		extracted.Tables = append(extracted.Tables, tables...)
+	}
+	if len(extracted.Tables) == 0 {
+		return nil, fmt.Errorf("テキストレイヤーから時刻表を検出できませんでした")
+	}
+	return &extracted, nil
+}
+
+// pdfWord is a run of glyphs on the same baseline. Y increases bottom to top (PDF coordinates).
+type pdfWord struct {
+	X, Y, W  float64
+	FontSize float64
+	S        string
+}
+
+func (w pdfWord) right() float64 { return w.X + w.W }
+
+func (w pdfWord) centerX() float64 { return w.X + w.W/2 }
+
+// readPDFWords returns the words of every page. The pdf package panics on malformed input,
+// so panics are converted to errors.
+func readPDFWords(data []byte) (pages [][]pdfWord, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("PDF テキスト読み込み失敗: %v", r)
+		}
+	}()
+
+	r, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("PDF 読み込み失敗: %w", err)
+	}
+	for i := 1; i <= r.NumPage(); i++ {
+		p := r.Page(i)
+		if p.V.IsNull() {
+			continue
+		}
+		pages = append(pages, groupGlyphs(p.Content().Text))
+	}
+	return pages, nil
+}
+
+// groupGlyphs merges glyphs drawn next to each other on the same baseline into words.
+func groupGlyphs(glyphs []pdf.Text) []pdfWord {
+	sorted := make([]pdf.Text, 0, len(glyphs))
+	for _, g := range glyphs {
+		if strings.TrimSpace(g.S) != "" {
+			sorted = append(sorted, g)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if math.Abs(sorted[i].Y-sorted[j].Y) > sameLineTolerance {
+			return sorted[i].Y > sorted[j].Y
+		}
+		return sorted[i].X < sorted[j].X
+	})
+
+	var words []pdfWord
+	for _, g := range sorted {
+		if n := len(words); n > 0 {
+			last := &words[n-1]
+			gap := g.X - last.right()
+			if math.Abs(g.Y-last.Y) <= sameLineTolerance && gap >= -g.FontSize*0.5 && gap <= g.FontSize*0.3 {
+				last.S += g.S
+				last.W = g.X + g.W - last.X
+				continue
+			}
+		}
+		words = append(words, pdfWord{X: g.X, Y: g.Y, W: g.W, FontSize: g.FontSize, S: g.S})
+	}
+	return words
+}
+
+const (
+	// sameLineTolerance is the max baseline difference (pt) for two glyphs/words to share a line.
+	sameLineTolerance = 2.0
+	// columnTolerance is the max center X difference (pt) for two cells to share a column.
+	columnTolerance = 12.0
+	// minColumnCells filters out stray times (e.g. in notes) that do not form a real column.
+	minColumnCells = 3
+	// blockGapFactor splits vertically stacked tables when the line gap exceeds this multiple
+	// of the median line spacing.
+	blockGapFactor = 3.0
+	// headerSearchHeight is how far (pt) above a table its title is searched for.
+	headerSearchHeight = 80.0
+)
+
+var reTimeCell = regexp.MustCompile(`^\d{1,2}[:：]\d{2}$`)
+
+func isTimeWord(s string) bool { return reTimeCell.MatchString(strings.TrimSpace(s)) }
+
+func isTildeWord(s string) bool {
+	s = strings.TrimSpace(s)
+	return s == "～" || s == "〜" || s == "~"
+}
+
+// textLine is a set of words sharing a baseline, ordered left to right.
+type textLine struct {
+	Y     float64
+	Words []pdfWord
+}
+
+func groupLines(words []pdfWord) []textLine {
+	sorted := append([]pdfWord(nil), words...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Y > sorted[j].Y })
+
+	var lines []textLine
+	for _, w := range sorted {
+		if n := len(lines); n > 0 && math.Abs(lines[n-1].Y-w.Y) <= sameLineTolerance {
+			lines[n-1].Words = append(lines[n-1].Words, w)
+			continue
+		}
+		lines = append(lines, textLine{Y: w.Y, Words: []pdfWord{w}})
+	}
+	for i := range lines {
+		sort.SliceStable(lines[i].Words, func(a, b int) bool { return lines[i].Words[a].X < lines[i].Words[b].X })
+	}
+	return lines
+}
+
+func (l textLine) text() string {
+	parts := make([]string, len(l.Words))
+	for i, w := range l.Words {
+		parts[i] = w.S
+	}
+	return strings.Join(parts, "")
+}
+
+// isTableLine reports whether a line holds time cells or a shuttle delimiter.
+func isTableLine(l textLine) bool {
+	tildes := 0
+	for _, w := range l.Words {
+		if isTimeWord(w.S) {
+			return true
+		}
+		if isTildeWord(w.S) {
+			tildes++
+		}
+	}
+	return tildes >= 2
+}
+
+// buildTablesFromWords rebuilds ExtractedTables from the words of one page.
+//
+// Table lines (lines with time cells or "～ ～ ～" delimiters) are split into vertical blocks
+// at large gaps. Within a block the X centers of time cells are clustered into columns and
+// every 3 adjacent columns form one table (大学発, 駅, 大学着), matching the column order
+// mapper.go expects. Station name and day type are read from the text above each table.
+func buildTablesFromWords(words []pdfWord) []ExtractedTable {
+	lines := groupLines(words)
+	validFrom, validTo, specific := parsePageDates(lines)
+
+	var tables []ExtractedTable
+	for _, block := range splitBlocks(lines) {
+		cols := clusterColumns(block.lines)
+		for g := 0; g+3 <= len(cols); g += 3 {
+			tc := cols[g : g+3]
+			noteLimit := math.Inf(1)
+			if g+3 < len(cols) {
+				noteLimit = cols[g+3].left
+			}
+			rows := buildRows(block.lines, tc, noteLimit)
+			if len(rows) == 0 {
+				continue
+			}
+			header := headerText(lines, block.top, tc[0].left, tc[2].right)
+			blockHeader := headerText(lines, block.top, math.Inf(-1), math.Inf(1))
+
+			table := ExtractedTable{
+				StationName: detectStationName(header),
+				Segments:    []ExtractedSegment{{Type: "fixed", Rows: rows}},
+			}
+			if specific != "" {
+				table.SpecificFrom, table.SpecificTo = specific, specific
+			} else {
+				table.DayType = detectDayType(header)
+				if table.DayType == "" {
+					table.DayType = detectDayType(blockHeader)
+				}
+				if table.DayType == "" {
+					table.DayType = "weekday"
+				}
+				table.ValidFrom, table.ValidTo = validFrom, validTo
+			}
+			tables = append(tables, table)
+		}
+	}
+	return tables
+}
+
+type tableBlock struct {
+	top   float64
+	lines []textLine
+}
+
+func splitBlocks(lines []textLine) []tableBlock {
+	var tableLines []textLine
+	for _, l := range lines {
+		if isTableLine(l) {
+			tableLines = append(tableLines, l)
+		}
+	}
+	if len(tableLines) == 0 {
+		return nil
+	}
+
+	var gaps []float64
+	for i := 1; i < len(tableLines); i++ {
+		gaps = append(gaps, tableLines[i-1].Y-tableLines[i].Y)
+	}
+	median := 0.0
+	if len(gaps) > 0 {
+		s := append([]float64(nil), gaps...)
+		sort.Float64s(s)
+		median = s[len(s)/2]
+	}
+
+	blocks := []tableBlock{{top: tableLines[0].Y, lines: []textLine{tableLines[0]}}}
+	for i := 1; i < len(tableLines); i++ {
+		if median > 0 && gaps[i-1] > median*blockGapFactor {
+			blocks = append(blocks, tableBlock{top: tableLines[i].Y})
+		}
+		b := &blocks[len(blocks)-1]
+		b.lines = append(b.lines, tableLines[i])
+	}
+	return blocks
+}
+
+type column struct {
+	center      float64
+	left, right float64
+	cells       int
+}
+
+// clusterColumns groups time cells by X center. Columns with too few cells are dropped.
+func clusterColumns(lines []textLine) []column {
+	var cells []pdfWord
+	for _, l := range lines {
+		for _, w := range l.Words {
+			if isTimeWord(w.S) {
+				cells = append(cells, w)
+			}
+		}
+	}
+	sort.Slice(cells, func(i, j int) bool { return cells[i].centerX() < cells[j].centerX() })
+
+	var cols []column
+	for _, c := range cells {
+		if n := len(cols); n > 0 && c.centerX()-cols[n-1].center <= columnTolerance {
+			col := &cols[n-1]
+			col.center = (col.center*float64(col.cells) + c.centerX()) / float64(col.cells+1)
+			col.left = math.Min(col.left, c.X)
+			col.right = math.Max(col.right, c.right())
+			col.cells++
+			continue
+		}
+		cols = append(cols, column{center: c.centerX(), left: c.X, right: c.right(), cells: 1})
+	}
+
+	var kept []column
+	for _, c := range cols {
+		if c.cells >= minColumnCells {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// buildRows emits [col1, col2, col3] per line, or ["～", "～", "～", note] for shuttle rows.
+// Words between the last column and noteLimit are treated as the 備考 column.
+func buildRows(lines []textLine, cols []column, noteLimit float64) [][]string {
+	var rows [][]string
+	for _, l := range lines {
+		row := []string{"", "", ""}
+		tildes := 0
+		var note []string
+		for _, w := range l.Words {
+			idx := nearestColumn(cols, w.centerX())
+			switch {
+			case idx >= 0 && isTimeWord(w.S):
+				row[idx] = strings.Replace(strings.TrimSpace(w.S), "：", ":", 1)
+			case idx >= 0 && isTildeWord(w.S):
+				row[idx] = "～"
+				tildes++
+			case w.X > cols[2].right && w.right() < noteLimit:
+				note = append(note, w.S)
+			}
+		}
+		if tildes >= 2 {
+			noteText := strings.Join(note, "")
+			if reInterval.FindString(noteText) == "" {
+				noteText = ""
+			}
+			rows = append(rows, []string{"～", "～", "～", noteText})
+			continue
+		}
+		if row[0] == "" && row[1] == "" && row[2] == "" {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func nearestColumn(cols []column, x float64) int {
+	best, bestDist := -1, columnTolerance*2
+	for i, c := range cols {
+		if d := math.Abs(c.center - x); d <= bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+// headerText concatenates non-table text in the band just above top, restricted to [left, right].
+func headerText(lines []textLine, top, left, right float64) string {
+	var parts []string
+	for _, l := range lines {
+		if l.Y <= top || l.Y > top+headerSearchHeight || isTableLine(l) {
+			continue
+		}
+		for _, w := range l.Words {
+			if w.right() >= left-columnTolerance && w.X <= right+columnTolerance {
+				parts = append(parts, w.S)
+			}
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// detectStationName returns the canonical station name or alias found in s, preferring the
+// longest match so that "八王子みなみ野駅" wins over "八王子駅".
+func detectStationName(s string) string {
+	best := ""
+	candidates := make([]string, 0, len(stationRoutes)+len(stationAliases))
+	for name := range stationRoutes {
+		candidates = append(candidates, name)
+	}
+	for alias := range stationAliases {
+		candidates = append(candidates, alias)
+	}
+	for _, c := range candidates {
+		if strings.Contains(s, c) && len(c) > len(best) {
+			best = c
+		}
+	}
+	if best == "" {
+		return strings.TrimSpace(s)
+	}
+	return best
+}
+
+func detectDayType(s string) string {
+	switch {
+	case strings.Contains(s, "土曜"):
+		return "saturday"
+	case strings.Contains(s, "休日"), strings.Contains(s, "日曜"), strings.Contains(s, "祝日"):
+		return "holiday"
+	case strings.Contains(s, "平日"), strings.Contains(s, "月～金"), strings.Contains(s, "月〜金"):
+		return "weekday"
+	}
+	return ""
+}
+
+var (
+	reYear       = regexp.MustCompile(`(20\d{2})年(度)?`)
+	reDateRange  = regexp.MustCompile(`(\d{1,2})月(\d{1,2})日(?:\([^)]*\)|（[^）]*）)?\s*[～〜~]\s*(?:(\d{1,2})月)?(\d{1,2})日`)
+	reSingleDate = regexp.MustCompile(`(\d{1,2})月(\d{1,2})日`)
+)
+
+// parsePageDates reads the operating period from the page title.
+// A "M月D日～M月D日" range yields validFrom/validTo; a lone date yields a specific-date schedule.
+// Dates are only produced when a year is printed on the page, mirroring the Gemini prompt rule
+// that years must never be guessed. For "YYYY年度", January to March belong to the next year,
+// and a range whose end falls before its start (e.g. "2026年12月21日～1月9日") ends in the next year.
+func parsePageDates(lines []textLine) (validFrom, validTo, specific string) {
+	var all []string
+	for _, l := range lines {
+		if !isTableLine(l) {
+			all = append(all, l.text())
+		}
+	}
+	text := strings.Join(all, "\n")
+
+	m := reYear.FindStringSubmatch(text)
+	if m == nil {
+		return "", "", ""
+	}
+	year, _ := strconv.Atoi(m[1])
+	fiscal := m[2] != ""
+	date := func(month, day, extraYears int) string {
+		y := year + extraYears
+		if fiscal && month < 4 {
+			y++
+		}
+		return fmt.Sprintf("%04d-%02d-%02d", y, month, day)
+	}
+
+	if r := reDateRange.FindStringSubmatch(text); r != nil {
+		fromMonth, _ := strconv.Atoi(r[1])
+		fromDay, _ := strconv.Atoi(r[2])
+		toMonth := fromMonth
+		if r[3] != "" {
+			toMonth, _ = strconv.Atoi(r[3])
+		}
+		toDay, _ := strconv.Atoi(r[4])
+		from, to := date(fromMonth, fromDay, 0), date(toMonth, toDay, 0)
+		if to < from {
+			to = date(toMonth, toDay, 1)
+		}
+		return from, to, ""
+	}
+	if d := reSingleDate.FindStringSubmatch(text); d != nil {
+		month, _ := strconv.Atoi(d[1])
+		day, _ := strconv.Atoi(d[2])
+		return "", "", date(month, day, 0)
+	}
+	return "", "", ""
+}
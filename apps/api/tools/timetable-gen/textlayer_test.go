@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// word builds a pdfWord with a width proportional to its rune count.
+func word(x, y float64, s string) pdfWord {
+	return pdfWord{X: x, Y: y, W: float64(len([]rune(s))) * 5, FontSize: 10, S: s}
+}
+
+// samplePageWords lays out a page with two side-by-side tables (八王子駅, 八王子みなみ野駅)
+// under a weekday heading, including a shuttle delimiter row in the first table.
+func samplePageWords() []pdfWord {
+	words := []pdfWord{
+		word(50, 800, "4月7日(月)～7月29日(水)"),
+		word(200, 800, "基本運行時刻表（2026年度）"),
+		word(50, 770, "平日（月～金）"),
+		word(50, 750, "八王子駅南口"),
+		word(300, 750, "八王子みなみ野駅"),
+	}
+	left := [][]string{
+		{"7:30", "7:50", "8:10"},
+		{"8:00", "8:20", "8:40"},
+		{"～", "～", "～"},
+		{"10:00", "10:20", "10:40"},
+		{"11:00", "11:20", "11:40"},
+	}
+	right := [][]string{
+		{"7:40", "7:50", "8:00"},
+		{"8:40", "8:50", "9:00"},
+		{"9:40", "9:50", "10:00"},
+		{"10:40", "10:50", "11:00"},
+	}
+	for i, row := range left {
+		y := 720 - float64(i)*15
+		for j, cell := range row {
+			words = append(words, word(50+float64(j)*50, y, cell))
+		}
+		if row[0] == "～" {
+			words = append(words, word(210, y, "約3〜5分間隔"))
+		}
+	}
+	for i, row := range right {
+		y := 720 - float64(i)*15
+		for j, cell := range row {
+			words = append(words, word(300+float64(j)*50, y, cell))
+		}
+	}
+	return words
+}
+
+func TestBuildTablesFromWords(t *testing.T) {
+	tables := buildTablesFromWords(samplePageWords())
+	if len(tables) != 2 {
+		t.Fatalf("expected 2 tables, got %d: %+v", len(tables), tables)
+	}
+
+	first := tables[0]
+	if first.StationName != "八王子駅南口" {
+		t.Errorf("first.StationName = %q, want 八王子駅南口", first.StationName)
+	}
+	if first.DayType != "weekday" {
+		t.Errorf("first.DayType = %q, want weekday", first.DayType)
+	}
+	if first.ValidFrom != "2026-04-07" || first.ValidTo != "2026-07-29" {
+		t.Errorf("validity = %s〜%s, want 2026-04-07〜2026-07-29", first.ValidFrom, first.ValidTo)
+	}
+	rows := first.Segments[0].Rows
+	if len(rows) != 5 {
+		t.Fatalf("first table rows = %d, want 5: %v", len(rows), rows)
+	}
+	if rows[0][0] != "7:30" || rows[0][1] != "7:50" || rows[0][2] != "8:10" {
+		t.Errorf("rows[0] = %v, want [7:30 7:50 8:10]", rows[0])
+	}
+	if !isShuttleRow(rows[2]) {
+		t.Errorf("rows[2] = %v, want shuttle row", rows[2])
+	}
+	if rowCol(rows[2], shuttleNoteCol) != "約3〜5分間隔" {
+		t.Errorf("shuttle note = %q, want 約3〜5分間隔", rowCol(rows[2], shuttleNoteCol))
+	}
+
+	second := tables[1]
+	if second.StationName != "八王子みなみ野駅" {
+		t.Errorf("second.StationName = %q, want 八王子みなみ野駅", second.StationName)
+	}
+	if len(second.Segments[0].Rows) != 4 {
+		t.Errorf("second table rows = %d, want 4", len(second.Segments[0].Rows))
+	}
+}
+
+func TestBuildTablesFromWords_MapsToServices(t *testing.T) {
+	tables := buildTablesFromWords(samplePageWords())
+	services, err := Map(&ExtractedData{Tables: tables}, nil)
+	if err != nil {
+		t.Fatalf("Map error: %v", err)
+	}
+	if len(services) != 4 {
+		t.Fatalf("expected 4 services, got %d", len(services))
+	}
+	if services[0].ID != "school-to-hachioji-weekday-20260407" {
+		t.Errorf("services[0].ID = %q", services[0].ID)
+	}
+	var shuttle *ServiceSegment
+	for i, seg := range services[0].Segments {
+		if seg.SegmentType == "shuttle" {
+			shuttle = &services[0].Segments[i]
+		}
+	}
+	if shuttle == nil || shuttle.StartTime != "8:00" || shuttle.EndTime != "10:00" {
+		t.Errorf("shuttle segment = %+v, want 8:00〜10:00", shuttle)
+	}
+}
+
+func TestParsePageDates_SpecificDate(t *testing.T) {
+	lines := groupLines([]pdfWord{word(50, 800, "2026年5月23日(土)　スポーツ大会　運行時刻表")})
+	from, to, specific := parsePageDates(lines)
+	if from != "" || to != "" || specific != "2026-05-23" {
+		t.Errorf("parsePageDates = (%q, %q, %q), want specific 2026-05-23", from, to, specific)
+	}
+}
+
+func TestParsePageDates_NoYear(t *testing.T) {
+	lines := groupLines([]pdfWord{word(50, 800, "6月27日運行　臨時運行時刻表")})
+	from, to, specific := parsePageDates(lines)
+	if from != "" || to != "" || specific != "" {
+		t.Errorf("parsePageDates = (%q, %q, %q), want all empty when year is missing", from, to, specific)
+	}
+}
+
+func TestParsePageDates_FiscalYearWrap(t *testing.T) {
+	lines := groupLines([]pdfWord{word(50, 800, "2026年度 1月8日～3月20日 運行時刻表")})
+	from, to, _ := parsePageDates(lines)
+	if from != "2027-01-08" || to != "2027-03-20" {
+		t.Errorf("parsePageDates = (%q, %q), want 2027-01-08〜2027-03-20", from, to)
+	}
+}
+
+func TestParsePageDates_CalendarYearWrap(t *testing.T) {
+	tests := []struct {
+		title    string
+		from, to string
+	}{
+		{"2026年12月21日(月)～1月9日(土) 冬季休業期間 運行時刻表", "2026-12-21", "2027-01-09"},
+		{"2026年度 3月23日～4月3日 春季休業期間 運行時刻表", "2027-03-23", "2027-04-03"},
+		{"2026年 12月1日～12月24日 運行時刻表", "2026-12-01", "2026-12-24"},
+	}
+	for _, tt := range tests {
+		from, to, _ := parsePageDates(groupLines([]pdfWord{word(50, 800, tt.title)}))
+		if from != tt.from || to != tt.to {
+			t.Errorf("parsePageDates(%q) = (%q, %q), want (%q, %q)", tt.title, from, to, tt.from, tt.to)
+		}
+	}
+}
+
+// TestTextLayerExtractor_FixturePDF parses testdata/textlayer/sample.pdf, a small PDF with a real
+// text layer (regenerate with `go run testdata/textlayer/gen.go`), so the PDF reading path is
+// covered in CI even when downloaded/ is empty.
+func TestTextLayerExtractor_FixturePDF(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "textlayer", "sample.pdf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	extracted, err := NewTextLayerExtractor().Extract(context.Background(), data)
+	if err != nil {
+		t.Fatalf("Extract error: %v", err)
+	}
+	want := buildTablesFromWords(samplePageWords())
+	if len(extracted.Tables) != len(want) {
+		t.Fatalf("got %d tables, want %d", len(extracted.Tables), len(want))
+	}
+	for i, table := range extracted.Tables {
+		if table.StationName != want[i].StationName || table.DayType != want[i].DayType ||
+			table.ValidFrom != want[i].ValidFrom || table.ValidTo != want[i].ValidTo {
+			t.Errorf("table %d = %s %s %s〜%s, want %s %s %s〜%s", i,
+				table.StationName, table.DayType, table.ValidFrom, table.ValidTo,
+				want[i].StationName, want[i].DayType, want[i].ValidFrom, want[i].ValidTo)
+		}
+		if got, w := fmt.Sprint(table.Segments[0].Rows), fmt.Sprint(want[i].Segments[0].Rows); got != w {
+			t.Errorf("table %d rows = %s, want %s", i, got, w)
+		}
+	}
+}
+
+// TestTextLayerExtractor_DownloadedPDFs runs the parser against PDFs fetched into downloaded/.
+// PDFs are gitignored, so the test is skipped on a fresh checkout (run `go run . fetch` first).
+func TestTextLayerExtractor_DownloadedPDFs(t *testing.T) {
+	pdfs, _ := filepath.Glob(filepath.Join("downloaded", "*.pdf"))
+	if len(pdfs) == 0 {
+		t.Skip("downloaded/ に PDF がありません")
+	}
+
+	ext := NewTextLayerExtractor()
+	for _, path := range pdfs {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			extracted, err := ext.Extract(context.Background(), data)
+			if err != nil {
+				t.Fatalf("Extract error: %v", err)
+			}
+			for _, table := range extracted.Tables {
+				if _, err := lookupStation(table.StationName); err != nil {
+					t.Errorf("table station %q: %v", table.StationName, err)
+				}
+				if len(table.Segments) == 0 || len(table.Segments[0].Rows) == 0 {
+					t.Errorf("table %q has no rows", table.StationName)
+				}
+			}
+		})
+	}
+}
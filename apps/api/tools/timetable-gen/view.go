@@ -5,16 +5,79 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+
+	"api/pkg/board"
+	"api/pkg/schedule"
 )
 
+// runView implements `timetable-gen view`:
+//
+//	view <file.json|directory>                         サービスを 1 件ずつ表示
+//	view --stop <id|name> [--date YYYY-MM-DD] [dir]    停留所・日付で API が返す便をまとめて表示
+//	view --compare <a.json> <b.json>                   2 つのサービスを並べて比較
+//
+// --stop と --compare は --format text|markdown|html で出力形式を選べます。
 func runView(args []string) {
-	if len(args) == 0 {
-		fmt.Fprintln(os.Stderr, "usage: go run . view <file.json|directory>")
+	stop, date, format := "", "", formatText
+	compare := false
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--stop":
+			if i+1 < len(args) {
+				stop = args[i+1]
+				i++
+			}
+		case "--date":
+			if i+1 < len(args) {
+				date = args[i+1]
+				i++
+			}
+		case "--format":
+			if i+1 < len(args) {
+				format = args[i+1]
+				i++
+			}
+		case "--compare":
+			compare = true
+		default:
+			positional = append(positional, args[i])
+		}
+	}
+
+	var err error
+	switch {
+	case compare:
+		if len(positional) != 2 {
+			fmt.Fprintln(os.Stderr, "usage: go run . view --compare <a.json> <b.json> [--format text|markdown|html]")
+			os.Exit(1)
+		}
+		err = viewCompare(positional[0], positional[1], format)
+	case stop != "":
+		dir := "../../data/services"
+		if len(positional) > 0 {
+			dir = positional[0]
+		}
+		err = viewStop(dir, stop, date, format)
+	default:
+		if len(positional) == 0 {
+			fmt.Fprintln(os.Stderr, "usage: go run . view <file.json|directory>")
+			os.Exit(1)
+		}
+		viewFiles(positional[0])
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
+}
 
-	target := args[0]
+func viewFiles(target string) {
 	info, err := os.Stat(target)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
@@ -93,7 +156,7 @@ func printHeader(svc ServiceData) {
 }
 
 func printSegment(idx int, seg ServiceSegment) int {
-	cond := formatCondition(seg.Condition)
+	cond := board.ConditionLabel(seg.Condition.scheduleCondition())
 
 	switch seg.SegmentType {
 	case "shuttle":
@@ -140,24 +203,316 @@ func printTimePairs(times []TimePair) {
 	}
 }
 
-func formatCondition(c SegmentCondition) string {
-	switch c.Type {
-	case "dayType":
-		labels := map[string]string{
-			"weekday":  "平日",
-			"saturday": "土曜",
-			"holiday":  "休日",
+// scheduleCondition converts c for the date resolution shared with the API.
+func (c SegmentCondition) scheduleCondition() schedule.Condition {
+	return schedule.Condition{Type: schedule.ConditionType(c.Type), Value: c.Value, From: c.From, To: c.To}
+}
+
+func schedulePeriods(periods []ValidityPeriod) []schedule.Period {
+	out := make([]schedule.Period, len(periods))
+	for i, p := range periods {
+		out[i] = schedule.Period{From: p.From, To: p.To}
+	}
+	return out
+}
+
+// loadServiceDir reads every service JSON directly under dir. A missing dir yields no services.
+func loadServiceDir(dir string) ([]ServiceData, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var services []ServiceData
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		var svc ServiceData
+		if err := json.Unmarshal(data, &svc); err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(f), err)
+		}
+		services = append(services, svc)
+	}
+	return services, nil
+}
+
+// withArchived appends the archived services whose ID is not shadowed by a current one.
+func withArchived(current, archived []ServiceData) []ServiceData {
+	ids := map[string]bool{}
+	for _, svc := range current {
+		ids[svc.ID] = true
+	}
+	services := append([]ServiceData(nil), current...)
+	for _, svc := range archived {
+		if !ids[svc.ID] {
+			services = append(services, svc)
 		}
-		if l, ok := labels[c.Value]; ok {
-			return l
+	}
+	return services
+}
+
+// loadServicesWithArchive reads dir and its archived/ subdirectory.
+func loadServicesWithArchive(dir string) (current, archived []ServiceData, err error) {
+	if current, err = loadServiceDir(dir); err != nil {
+		return nil, nil, err
+	}
+	if archived, err = loadServiceDir(filepath.Join(dir, archiveDirName)); err != nil {
+		return nil, nil, err
+	}
+	return current, archived, nil
+}
+
+// resolveStop returns the IDs of the departure stops matching stop (an ID or a display name)
+// and the stop's display name. A name shared by several stops covers all of them.
+func resolveStop(services []ServiceData, stop string) (map[int]bool, string, error) {
+	stopIDs := map[int]bool{}
+	stopName := stop
+	if id, err := strconv.Atoi(stop); err == nil {
+		stopIDs[id] = true
+	}
+	for _, svc := range services {
+		if stopIDs[svc.From.StopID] {
+			stopName = svc.From.DisplayName
 		}
-		return c.Value
-	case "specificPeriod":
-		if c.From == c.To {
-			return c.From
+		if svc.From.DisplayName == stop {
+			stopIDs[svc.From.StopID] = true
+		}
+	}
+	if len(stopIDs) == 0 {
+		return nil, "", fmt.Errorf("停留所 %q を出発するサービスがありません", stop)
+	}
+	return stopIDs, stopName, nil
+}
+
+// parseDateFlag parses a YYYY-MM-DD flag value; empty means today in Japan.
+func parseDateFlag(flag, value string) (time.Time, error) {
+	if value == "" {
+		return jstToday(), nil
+	}
+	d, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s は YYYY-MM-DD で指定してください: %q", flag, value)
+	}
+	return d, nil
+}
+
+// stopDeparture is one row of viewStop: a fixed trip, or a shuttle window when Interval is set.
+type stopDeparture struct {
+	Departure   string
+	Arrival     string // end of the window for shuttles
+	Interval    *Interval
+	Destination string
+	Condition   string
+	ServiceID   string
+	Archived    bool
+}
+
+// stopDepartures returns what the API serves for the stops on date: segments of services
+// departing from one of stopIDs that are valid on date, merged and sorted by departure.
+// Archived services are used only for past dates, as the API does by default.
+func stopDepartures(current, archived []ServiceData, stopIDs map[int]bool, date time.Time) []stopDeparture {
+	services := current
+	if date.Before(jstToday()) {
+		services = withArchived(current, archived)
+	}
+	isArchived := map[string]bool{}
+	for _, svc := range archived {
+		isArchived[svc.ID] = true
+	}
+
+	var deps []stopDeparture
+	for _, svc := range services {
+		if !stopIDs[svc.From.StopID] || !schedule.PeriodsInclude(schedulePeriods(svc.ValidityPeriods), date) {
+			continue
+		}
+		for _, seg := range svc.Segments {
+			if !seg.Condition.scheduleCondition().Applies(date) {
+				continue
+			}
+			base := stopDeparture{
+				Destination: svc.To.DisplayName,
+				Condition:   board.ConditionLabel(seg.Condition.scheduleCondition()),
+				ServiceID:   svc.ID,
+				Archived:    isArchived[svc.ID],
+			}
+			switch seg.SegmentType {
+			case "fixed":
+				for _, tp := range seg.Times {
+					d := base
+					d.Departure, d.Arrival = tp.Departure, tp.Arrival
+					deps = append(deps, d)
+				}
+			case "shuttle":
+				d := base
+				d.Departure, d.Arrival, d.Interval = seg.StartTime, seg.EndTime, seg.Interval
+				deps = append(deps, d)
+			}
+		}
+	}
+	sort.SliceStable(deps, func(i, j int) bool {
+		a, b := clockMinutes(deps[i].Departure), clockMinutes(deps[j].Departure)
+		if a != b {
+			return a < b
+		}
+		return deps[i].Destination < deps[j].Destination
+	})
+	return deps
+}
+
+// clockMinutes converts "H:MM" to minutes after midnight; unparsable times sort last.
+func clockMinutes(s string) int {
+	t, err := parseTimeStr(s)
+	if err != nil {
+		return 24 * 60
+	}
+	return t.Hour()*60 + t.Minute()
+}
+
+// dateLabel describes date as the API resolves it, e.g. "2026-04-29 (水・昭和の日)".
+func dateLabel(date time.Time) string {
+	weekdays := []string{"日", "月", "火", "水", "木", "金", "土"}
+	label := weekdays[date.Weekday()]
+	if name := schedule.HolidayName(date); name != "" {
+		label += "・" + name
+	}
+	return fmt.Sprintf("%s (%s)", date.Format("2006-01-02"), label)
+}
+
+// viewStop prints every departure from stop on date (today in Japan when empty).
+// stop is a stop ID or a display name; a name shared by several stops covers all of them.
+func viewStop(dir, stop, dateStr, format string) error {
+	date, err := parseDateFlag("--date", dateStr)
+	if err != nil {
+		return err
+	}
+	current, archived, err := loadServicesWithArchive(dir)
+	if err != nil {
+		return err
+	}
+	stopIDs, stopName, err := resolveStop(withArchived(current, archived), stop)
+	if err != nil {
+		return err
+	}
+
+	t := textTable{
+		Title:  fmt.Sprintf("%s発 %s", stopName, dateLabel(date)),
+		Header: []string{"発", "着", "行き先", "条件", "サービス"},
+	}
+	archivedCount := 0
+	for _, d := range stopDepartures(current, archived, stopIDs, date) {
+		id := d.ServiceID
+		if d.Archived {
+			id += " (archived)"
+			archivedCount++
+		}
+		if d.Interval != nil {
+			t.Rows = append(t.Rows, []string{d.Departure + "〜" + d.Arrival, "", d.Destination, d.Condition + " シャトル " + formatInterval(d.Interval) + "間隔", id})
+			continue
+		}
+		t.Rows = append(t.Rows, []string{d.Departure, d.Arrival, d.Destination, d.Condition, id})
+	}
+	t.Notes = append(t.Notes, fmt.Sprintf("合計: %d 件", len(t.Rows)))
+	if archivedCount > 0 {
+		t.Notes = append(t.Notes, fmt.Sprintf("うちアーカイブ済みサービス: %d 件", archivedCount))
+	}
+	return t.render(os.Stdout, format)
+}
+
+// compareTable lays out two services side by side: header fields, then every trip keyed by
+// condition and departure, marking rows that differ.
+func compareTable(a, b ServiceData) textTable {
+	t := textTable{
+		Title:  fmt.Sprintf("%s ⇔ %s", a.ID, b.ID),
+		Header: []string{"項目", "A", "B", ""},
+	}
+	field := func(name, va, vb string) {
+		mark := ""
+		if va != vb {
+			mark = "≠"
+		}
+		t.Rows = append(t.Rows, []string{name, va, vb, mark})
+	}
+	field("名前", a.Name, b.Name)
+	field("区間", a.From.DisplayName+" → "+a.To.DisplayName, b.From.DisplayName+" → "+b.To.DisplayName)
+	field("有効期間", formatPeriods(a.ValidityPeriods), formatPeriods(b.ValidityPeriods))
+
+	tripsA, tripsB := tripsByCondition(a), tripsByCondition(b)
+	for _, cond := range unionKeys(tripsA, tripsB) {
+		arrA, arrB := map[string]string{}, map[string]string{}
+		var deps []string
+		for _, tp := range tripsA[cond] {
+			arrA[tp.Departure] = tp.Arrival
+			deps = append(deps, tp.Departure)
+		}
+		for _, tp := range tripsB[cond] {
+			if _, ok := arrA[tp.Departure]; !ok {
+				deps = append(deps, tp.Departure)
+			}
+			arrB[tp.Departure] = tp.Arrival
+		}
+		sort.SliceStable(deps, func(i, j int) bool { return clockMinutes(deps[i]) < clockMinutes(deps[j]) })
+		for _, dep := range deps {
+			va, okA := arrA[dep]
+			vb, okB := arrB[dep]
+			cellA, cellB, mark := "", "", ""
+			if okA {
+				cellA = dep + "→" + va
+			}
+			if okB {
+				cellB = dep + "→" + vb
+			}
+			switch {
+			case !okA:
+				mark = "+"
+			case !okB:
+				mark = "-"
+			case va != vb:
+				mark = "≠"
+			}
+			t.Rows = append(t.Rows, []string{cond, cellA, cellB, mark})
+		}
+	}
+
+	shuttlesA, shuttlesB := shuttlesByCondition(a), shuttlesByCondition(b)
+	for _, cond := range unionKeys(shuttlesA, shuttlesB) {
+		sa, sb := shuttlesA[cond], shuttlesB[cond]
+		for i := 0; i < len(sa) || i < len(sb); i++ {
+			cellA, cellB := "", ""
+			if i < len(sa) {
+				cellA = sa[i]
+			}
+			if i < len(sb) {
+				cellB = sb[i]
+			}
+			mark := ""
+			if cellA != cellB {
+				mark = "≠"
+			}
+			t.Rows = append(t.Rows, []string{cond + " シャトル", cellA, cellB, mark})
+		}
+	}
+
+	changed := 0
+	for _, r := range t.Rows {
+		if r[3] != "" {
+			changed++
+		}
+	}
+	t.Notes = []string{fmt.Sprintf("差分: %d 行 (+ B のみ / - A のみ / ≠ 内容が異なる)", changed)}
+	return t
+}
+
+func viewCompare(pathA, pathB, format string) error {
+	var services [2]ServiceData
+	for i, path := range []string{pathA, pathB} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, &services[i]); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
 		}
-		return c.From + "〜" + c.To
-	default:
-		return c.Type
 	}
+	return compareTable(services[0], services[1]).render(os.Stdout, format)
 }
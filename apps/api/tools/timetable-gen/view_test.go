@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustDate(t *testing.T, s string) time.Time {
+	t.Helper()
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func departures(deps []stopDeparture) []string {
+	out := make([]string, len(deps))
+	for i, d := range deps {
+		out[i] = d.Departure
+	}
+	return out
+}
+
+func TestStopDepartures_MergesApplicableServices(t *testing.T) {
+	weekday := validService()
+
+	shuttle := validService()
+	shuttle.ID = "hachioji-to-school-shuttle-20260407"
+	shuttle.To = StopRef{StopID: 4, DisplayName: "大学 (図書館前)"}
+	shuttle.Segments = []ServiceSegment{
+		{SegmentType: "shuttle", Condition: SegmentCondition{Type: "dayType", Value: "weekday"},
+			StartTime: "8:10", EndTime: "8:25", Interval: &Interval{Min: 3, Max: 5}},
+		{SegmentType: "fixed", Condition: SegmentCondition{Type: "dayType", Value: "saturday"},
+			Times: []TimePair{{Departure: "7:45", Arrival: "8:05"}}},
+	}
+
+	other := validService()
+	other.ID = "minamino-to-school-weekday-20260407"
+	other.From = StopRef{StopID: 2, DisplayName: "南野"}
+
+	services := []ServiceData{weekday, shuttle, other}
+	stops := map[int]bool{1: true}
+
+	got := stopDepartures(services, nil, stops, mustDate(t, "2026-04-28")) // 火曜
+	want := []string{"7:30", "8:00", "8:10", "8:30", "9:00", "9:30"}
+	if strings.Join(departures(got), ",") != strings.Join(want, ",") {
+		t.Errorf("weekday departures = %v, want %v", departures(got), want)
+	}
+	if got[2].Interval == nil || got[2].Arrival != "8:25" {
+		t.Errorf("8:10 should be the shuttle window, got %+v", got[2])
+	}
+
+	// 昭和の日は平日ダイヤも土曜ダイヤも走らない
+	if got := stopDepartures(services, nil, stops, mustDate(t, "2026-04-29")); len(got) != 0 {
+		t.Errorf("holiday departures = %v, want none", departures(got))
+	}
+
+	// 有効期間外
+	if got := stopDepartures(services, nil, stops, mustDate(t, "2026-08-04")); len(got) != 0 {
+		t.Errorf("out-of-period departures = %v, want none", departures(got))
+	}
+}
+
+func TestStopDepartures_ArchivedOnlyForPastDates(t *testing.T) {
+	old := validService()
+	old.ID = "hachioji-to-school-weekday-20200407"
+	old.ValidityPeriods = []ValidityPeriod{{From: "2020-04-07", To: "2099-12-31"}}
+	old.Segments[0].Times = []TimePair{{Departure: "6:50", Arrival: "7:10"}}
+	stops := map[int]bool{1: true}
+
+	past := stopDepartures(nil, []ServiceData{old}, stops, mustDate(t, "2020-04-07"))
+	if len(past) != 1 || !past[0].Archived {
+		t.Errorf("past date should include the archived service, got %+v", past)
+	}
+
+	future := jstToday().AddDate(0, 0, 7)
+	if got := stopDepartures(nil, []ServiceData{old}, stops, future); len(got) != 0 {
+		t.Errorf("future date should not use archived services, got %v", departures(got))
+	}
+
+	// 同じ ID が現行にあればそちらを優先する
+	current := old
+	current.Segments = []ServiceSegment{{SegmentType: "fixed", Condition: old.Segments[0].Condition,
+		Times: []TimePair{{Departure: "7:00", Arrival: "7:20"}}}}
+	got := stopDepartures([]ServiceData{current}, []ServiceData{old}, stops, mustDate(t, "2020-04-07"))
+	if len(got) != 1 || got[0].Departure != "7:00" {
+		t.Errorf("current service should shadow the archived one, got %v", departures(got))
+	}
+}
+
+func TestCompareTable_Markers(t *testing.T) {
+	a := validService()
+	b := validService()
+	b.Segments = []ServiceSegment{{
+		SegmentType: "fixed",
+		Condition:   SegmentCondition{Type: "dayType", Value: "weekday"},
+		Times: []TimePair{
+			{Departure: "7:30", Arrival: "7:50"},
+			{Departure: "8:00", Arrival: "8:25"},
+			{Departure: "8:30", Arrival: "8:50"},
+			{Departure: "9:00", Arrival: "9:20"},
+			{Departure: "10:00", Arrival: "10:20"},
+		},
+	}}
+
+	marks := map[string]string{}
+	for _, r := range compareTable(a, b).Rows {
+		if r[0] == "平日" {
+			marks[r[1]+"|"+r[2]] = r[3]
+		}
+	}
+	want := map[string]string{
+		"7:30→7:50|7:30→7:50": "",
+		"8:00→8:20|8:00→8:25": "≠",
+		"9:30→9:50|":          "-",
+		"|10:00→10:20":        "+",
+	}
+	for k, m := range want {
+		if got, ok := marks[k]; !ok || got != m {
+			t.Errorf("row %q: mark = %q (present %v), want %q", k, got, ok, m)
+		}
+	}
+}
+
+func TestTextTable_Render(t *testing.T) {
+	tbl := textTable{
+		Title:  "八王子駅発",
+		Header: []string{"発", "行き先"},
+		Rows:   [][]string{{"7:30", "大学"}, {"8:00", "a|b <c>"}},
+		Notes:  []string{"合計: 2 件"},
+	}
+
+	var text bytes.Buffer
+	if err := tbl.render(&text, formatText); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(text.String(), "\n")
+	// "発" は 2 桁幅なので "7:30" (4 桁) に合わせて 2 つ空白を足す
+	if lines[1] != "発    行き先" || lines[3] != "7:30  大学" {
+		t.Errorf("text columns misaligned:\n%s", text.String())
+	}
+
+	var md bytes.Buffer
+	if err := tbl.render(&md, formatMarkdown); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(md.String(), `| 8:00 | a\|b <c> |`) {
+		t.Errorf("markdown should escape pipes:\n%s", md.String())
+	}
+
+	var h bytes.Buffer
+	if err := tbl.render(&h, formatHTML); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(h.String(), "<td>a|b &lt;c&gt;</td>") {
+		t.Errorf("html should escape cells:\n%s", h.String())
+	}
+
+	if err := tbl.render(&h, "pdf"); err == nil {
+		t.Error("unknown format should be an error")
+	}
+}
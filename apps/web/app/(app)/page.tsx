@@ -404,6 +404,7 @@ function HomeContent() {
               name: group.name,
               date: format(currentNow, 'yyyy-MM-dd'),
               segments: [],
+              includesArchived: false,
             },
             filtered: [],
             allBuses: [],
@@ -434,6 +435,7 @@ function HomeContent() {
             name: group.name,
             date: format(currentNow, 'yyyy-MM-dd'),
             segments: [],
+            includesArchived: false,
           },
           filtered: [],
           allBuses: [],
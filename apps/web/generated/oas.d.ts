@@ -72,6 +72,23 @@ export interface paths {
     patch?: never
     trace?: never
   }
+  '/api/bus-stops/groups/{id}/timetable/print': {
+    parameters: {
+      query?: never
+      header?: never
+      path?: never
+      cookie?: never
+    }
+    /** @description グループ内全停留所の掲示用時刻表を印刷用 HTML で取得します。 */
+    get: operations['BusStopGroupsService_getBusStopGroupPrintableTimetable']
+    put?: never
+    post?: never
+    delete?: never
+    options?: never
+    head?: never
+    patch?: never
+    trace?: never
+  }
   '/api/bus-stops/{id}': {
     parameters: {
       query?: never
@@ -106,64 +123,56 @@ export interface paths {
     patch?: never
     trace?: never
   }
+  '/api/bus-stops/{id}/timetable/print': {
+    parameters: {
+      query?: never
+      header?: never
+      path?: never
+      cookie?: never
+    }
+    /** @description バス停に掲示する形式（時を行・分を列に並べた表）の時刻表を印刷用 HTML で取得します。 */
+    get: operations['BusStopService_getBusStopPrintableTimetable']
+    put?: never
+    post?: never
+    delete?: never
+    options?: never
+    head?: never
+    patch?: never
+    trace?: never
+  }
 }
 export type webhooks = Record<string, never>
 export interface components {
   schemas: {
-    /** @description HTTP 409 Conflict - The request conflicts with the current state of the server. */
-    'Errors.Conflict': {
-      /** @enum {string} */
-      code: 'Conflict'
-      message: string
-    }
-    /** @description Validation error for a single field. */
-    'Errors.FieldError': {
-      field: string
-      message: string
-    }
-    /** @description HTTP 403 Forbidden - You do not have permission to access this resource. */
-    'Errors.Forbidden': {
-      /** @enum {string} */
-      code: 'Forbidden'
-      message: string
-    }
-    /** @description HTTP 500 Internal Server Error - Unexpected condition on the server. */
-    'Errors.InternalServerError': {
-      /** @enum {string} */
-      code: 'InternalServerError'
-      message: string
-      details?: string
-    }
-    'Errors.ServiceError':
-      | {
-          /** @enum {string} */
-          code: 'BadRequest'
-          message: string
-          detail: string
-        }
-      | components['schemas']['Errors.Unauthorized']
-      | components['schemas']['Errors.Forbidden']
-      | {
-          /** @enum {string} */
-          code: 'NotFound'
-          message: string
-          details?: string
-        }
-      | components['schemas']['Errors.Conflict']
-      | components['schemas']['Errors.ValidationError']
-      | components['schemas']['Errors.InternalServerError']
-    /** @description HTTP 401 Unauthorized - Authentication is required or has failed. */
-    'Errors.Unauthorized': {
-      /** @enum {string} */
-      code: 'Unauthorized'
-      message: string
-    }
-    /** @description HTTP 422 Unprocessable Entity - Validation failed on submitted data. */
-    'Errors.ValidationError': {
-      /** @enum {string} */
-      code: 'ValidationError'
+    /**
+     * @description 機械可読なエラーコード。値は変更しません。
+     * @enum {string}
+     */
+    'Errors.ErrorCode':
+      | 'BadRequest'
+      | 'InvalidParameter'
+      | 'InvalidDate'
+      | 'InvalidPeriod'
+      | 'NotFound'
+      | 'BusStopNotFound'
+      | 'BusStopGroupNotFound'
+      | 'Unauthorized'
+      | 'MethodNotAllowed'
+      | 'InternalServerError'
+    /** @description すべての API が返す共通のエラーレスポンス */
+    'Errors.ErrorResponse': {
+      /**
+       * Format: int32
+       * @description HTTP ステータスコード
+       */
+      status: number
+      code: components['schemas']['Errors.ErrorCode']
+      /** @description Accept-Language に応じた利用者向けのメッセージ（ja / en、既定は ja） */
       message: string
-      fieldErrors?: components['schemas']['Errors.FieldError'][]
+      /** @description リクエスト ID。X-Request-Id レスポンスヘッダーと同じ値です。 */
+      requestId: string
+      /** @description 原因となったパラメータ（例: query.date, path.id） */
+      field?: string
     }
     'Models.BusStop': {
       /** Format: int32 */
@@ -184,6 +193,8 @@ export interface components {
       name: string
       date: components['schemas']['Scalars.DateISO']
       segments: components['schemas']['Models.BusStopSegment'][]
+      /** @description アーカイブ済み（期限切れ）の時刻表を含む場合 true */
+      includesArchived: boolean
     }
     'Models.BusStopSegment':
       | components['schemas']['Models.FixedSegment']
@@ -196,12 +207,16 @@ export interface components {
       lon: components['schemas']['Scalars.Longitude']
       date: components['schemas']['Scalars.DateISO']
       segments: components['schemas']['Models.BusStopSegment'][]
+      /** @description アーカイブ済み（期限切れ）の時刻表を含む場合 true */
+      includesArchived: boolean
     }
     'Models.FixedSegment': {
       /** @enum {string} */
       segmentType: 'fixed'
       destination: components['schemas']['Models.StopRef']
       times: components['schemas']['Models.TimePair'][]
+      /** @description アーカイブ済み（期限切れ）の時刻表から取得したセグメントの場合 true */
+      archived?: boolean
     }
     'Models.ShuttleSegment': {
       /** @enum {string} */
@@ -215,6 +230,8 @@ export interface components {
         /** Format: int32 */
         max: number
       }
+      /** @description アーカイブ済み（期限切れ）の時刻表から取得したセグメントの場合 true */
+      archived?: boolean
     }
     'Models.StopRef': {
       /** Format: int32 */
@@ -227,15 +244,6 @@ export interface components {
       departure: components['schemas']['Scalars.TimeISO']
       arrival: components['schemas']['Scalars.TimeISO']
     }
-    /** @description HTTP 400 Bad Request - The request cannot be processed due to client error. */
-    'Routes.TimetableBadRequest': {
-      /** @enum {string} */
-      code: 'BadRequest'
-      /** @enum {string} */
-      message: 'InvalidDate'
-      /** @enum {string} */
-      detail: "The 'date' query must be in YYYY-MM-DD format."
-    }
     /** Format: date */
     'Scalars.DateISO': string
     /** Format: double */
@@ -272,6 +280,33 @@ export interface operations {
           'application/json': components['schemas']['Models.BusStop'][]
         }
       }
+      /** @description Bad Request - The request was invalid. */
+      400: {
+        headers: {
+          [name: string]: unknown
+        }
+        content: {
+          'application/json': components['schemas']['Errors.ErrorResponse']
+        }
+      }
+      /** @description Not Found - The requested bus stop group was not found. */
+      404: {
+        headers: {
+          [name: string]: unknown
+        }
+        content: {
+          'application/json': components['schemas']['Errors.ErrorResponse']
+        }
+      }
+      /** @description An unexpected error response. */
+      default: {
+        headers: {
+          [name: string]: unknown
+        }
+        content: {
+          'application/json': components['schemas']['Errors.ErrorResponse']
+        }
+      }
     }
   }
   BusStopGroupsService_getAllBusStopGroups: {
@@ -292,6 +327,15 @@ export interface operations {
           'application/json': components['schemas']['Models.BusStopGroup'][]
         }
       }
+      /** @description An unexpected error response. */
+      default: {
+        headers: {
+          [name: string]: unknown
+        }
+        content: {
+          'application/json': components['schemas']['Errors.ErrorResponse']
+        }
+      }
     }
   }
   BusStopGroupsService_getBusStopGroupDetails: {
@@ -320,14 +364,16 @@ export interface operations {
           [name: string]: unknown
         }
         content: {
-          'application/json': {
-            /** @enum {string} */
-            code: 'NotFound'
-            /** @enum {string} */
-            message: 'BusStopGroupNotFound'
-            /** @enum {string} */
-            details?: 'The requested bus stop group does not exist.'
-          }
+          'application/json': components['schemas']['Errors.ErrorResponse']
+        }
+      }
+      /** @description An unexpected error response. */
+      default: {
+        headers: {
+          [name: string]: unknown
+        }
+        content: {
+          'application/json': components['schemas']['Errors.ErrorResponse']
         }
       }
     }
@@ -336,8 +382,13 @@ export interface operations {
     parameters: {
       query?: {
         date?: components['schemas']['Scalars.DateISO']
+        /** @description 期限切れでアーカイブ済みの時刻表も対象にするかどうか。省略時は過去の日付のときのみ対象にします。 */
+        includeHistorical?: boolean
+      }
+      header?: {
+        /** @description 以前に受け取った ETag。一致した場合は 304 Not Modified を返します。 */
+        'If-None-Match'?: string
       }
-      header?: never
       path: {
         id: number
       }
@@ -348,19 +399,34 @@ export interface operations {
       /** @description 指定した日付の時刻表を取得します。 */
       200: {
         headers: {
+          /** @description 時刻表の ETag。データのバージョンとリクエストのパラメータから決まります。 */
+          ETag: string
+          /** @description キャッシュの有効期間。今日の時刻表は短く、それ以外の日付は長くなります。 */
+          'Cache-Control': string
           [name: string]: unknown
         }
         content: {
           'application/json': components['schemas']['Models.BusStopGroupTimetable']
         }
       }
+      /** @description Not Modified - The timetable has not changed since the given ETag. */
+      304: {
+        headers: {
+          /** @description 時刻表の ETag。データのバージョンとリクエストのパラメータから決まります。 */
+          ETag: string
+          /** @description キャッシュの有効期間。今日の時刻表は短く、それ以外の日付は長くなります。 */
+          'Cache-Control': string
+          [name: string]: unknown
+        }
+        content?: never
+      }
       /** @description 指定した日付の時刻表を取得します。 */
       400: {
         headers: {
           [name: string]: unknown
         }
         content: {
-          'application/json': components['schemas']['Routes.TimetableBadRequest']
+          'application/json': components['schemas']['Errors.ErrorResponse']
         }
       }
       /** @description 指定した日付の時刻表を取得します。 */
@@ -369,14 +435,74 @@ export interface operations {
           [name: string]: unknown
         }
         content: {
-          'application/json': {
-            /** @enum {string} */
-            code: 'NotFound'
-            /** @enum {string} */
-            message: 'BusStopGroupNotFound'
-            /** @enum {string} */
-            details?: 'The requested bus stop group does not exist.'
-          }
+          'application/json': components['schemas']['Errors.ErrorResponse']
+        }
+      }
+      /** @description An unexpected error response. */
+      default: {
+        headers: {
+          [name: string]: unknown
+        }
+        content: {
+          'application/json': components['schemas']['Errors.ErrorResponse']
+        }
+      }
+    }
+  }
+  BusStopGroupsService_getBusStopGroupPrintableTimetable: {
+    parameters: {
+      query?: {
+        /** @description 1 日分の時刻表を出力する日付。from/to と同時には指定できません。いずれも省略時は今日です。 */
+        date?: components['schemas']['Scalars.DateISO']
+        /** @description 期間の時刻表を出力するときの開始日。to と同時に指定します。 */
+        from?: components['schemas']['Scalars.DateISO']
+        /** @description 期間の時刻表を出力するときの終了日（366 日以内）。 */
+        to?: components['schemas']['Scalars.DateISO']
+        /** @description 期限切れでアーカイブ済みの時刻表も対象にするかどうか。省略時は過去の日付のときのみ対象にします。 */
+        includeHistorical?: boolean
+      }
+      header?: never
+      path: {
+        id: number
+      }
+      cookie?: never
+    }
+    requestBody?: never
+    responses: {
+      /** @description 印刷用の時刻表 HTML を返します。 */
+      200: {
+        headers: {
+          [name: string]: unknown
+        }
+        content: {
+          'text/html': string
+        }
+      }
+      /** @description 印刷用の時刻表 HTML を返します。 */
+      400: {
+        headers: {
+          [name: string]: unknown
+        }
+        content: {
+          'application/json': components['schemas']['Errors.ErrorResponse']
+        }
+      }
+      /** @description 印刷用の時刻表 HTML を返します。 */
+      404: {
+        headers: {
+          [name: string]: unknown
+        }
+        content: {
+          'application/json': components['schemas']['Errors.ErrorResponse']
+        }
+      }
+      /** @description An unexpected error response. */
+      default: {
+        headers: {
+          [name: string]: unknown
+        }
+        content: {
+          'application/json': components['schemas']['Errors.ErrorResponse']
         }
       }
     }
@@ -407,14 +533,16 @@ export interface operations {
           [name: string]: unknown
         }
         content: {
-          'application/json': {
-            /** @enum {string} */
-            code: 'NotFound'
-            /** @enum {string} */
-            message: 'BusStopNotFound'
-            /** @enum {string} */
-            details?: 'The requested bus stop does not exist.'
-          }
+          'application/json': components['schemas']['Errors.ErrorResponse']
+        }
+      }
+      /** @description An unexpected error response. */
+      default: {
+        headers: {
+          [name: string]: unknown
+        }
+        content: {
+          'application/json': components['schemas']['Errors.ErrorResponse']
         }
       }
     }
@@ -423,8 +551,13 @@ export interface operations {
     parameters: {
       query?: {
         date?: components['schemas']['Scalars.DateISO']
+        /** @description 期限切れでアーカイブ済みの時刻表も対象にするかどうか。省略時は過去の日付のときのみ対象にします。 */
+        includeHistorical?: boolean
+      }
+      header?: {
+        /** @description 以前に受け取った ETag。一致した場合は 304 Not Modified を返します。 */
+        'If-None-Match'?: string
       }
-      header?: never
       path: {
         id: number
       }
@@ -435,19 +568,34 @@ export interface operations {
       /** @description 指定日の時刻表リストを返します。 */
       200: {
         headers: {
+          /** @description 時刻表の ETag。データのバージョンとリクエストのパラメータから決まります。 */
+          ETag: string
+          /** @description キャッシュの有効期間。今日の時刻表は短く、それ以外の日付は長くなります。 */
+          'Cache-Control': string
           [name: string]: unknown
         }
         content: {
           'application/json': components['schemas']['Models.BusStopTimetable']
         }
       }
+      /** @description Not Modified - The timetable has not changed since the given ETag. */
+      304: {
+        headers: {
+          /** @description 時刻表の ETag。データのバージョンとリクエストのパラメータから決まります。 */
+          ETag: string
+          /** @description キャッシュの有効期間。今日の時刻表は短く、それ以外の日付は長くなります。 */
+          'Cache-Control': string
+          [name: string]: unknown
+        }
+        content?: never
+      }
       /** @description 指定日の時刻表リストを返します。 */
       400: {
         headers: {
           [name: string]: unknown
         }
         content: {
-          'application/json': components['schemas']['Routes.TimetableBadRequest']
+          'application/json': components['schemas']['Errors.ErrorResponse']
         }
       }
       /** @description 指定日の時刻表リストを返します。 */
@@ -456,14 +604,74 @@ export interface operations {
           [name: string]: unknown
         }
         content: {
-          'application/json': {
-            /** @enum {string} */
-            code: 'NotFound'
-            /** @enum {string} */
-            message: 'BusStopNotFound'
-            /** @enum {string} */
-            details?: 'The requested bus stop does not exist.'
-          }
+          'application/json': components['schemas']['Errors.ErrorResponse']
+        }
+      }
+      /** @description An unexpected error response. */
+      default: {
+        headers: {
+          [name: string]: unknown
+        }
+        content: {
+          'application/json': components['schemas']['Errors.ErrorResponse']
+        }
+      }
+    }
+  }
+  BusStopService_getBusStopPrintableTimetable: {
+    parameters: {
+      query?: {
+        /** @description 1 日分の時刻表を出力する日付。from/to と同時には指定できません。いずれも省略時は今日です。 */
+        date?: components['schemas']['Scalars.DateISO']
+        /** @description 期間の時刻表を出力するときの開始日。to と同時に指定します。 */
+        from?: components['schemas']['Scalars.DateISO']
+        /** @description 期間の時刻表を出力するときの終了日（366 日以内）。 */
+        to?: components['schemas']['Scalars.DateISO']
+        /** @description 期限切れでアーカイブ済みの時刻表も対象にするかどうか。省略時は過去の日付のときのみ対象にします。 */
+        includeHistorical?: boolean
+      }
+      header?: never
+      path: {
+        id: number
+      }
+      cookie?: never
+    }
+    requestBody?: never
+    responses: {
+      /** @description 印刷用の時刻表 HTML を返します。 */
+      200: {
+        headers: {
+          [name: string]: unknown
+        }
+        content: {
+          'text/html': string
+        }
+      }
+      /** @description 印刷用の時刻表 HTML を返します。 */
+      400: {
+        headers: {
+          [name: string]: unknown
+        }
+        content: {
+          'application/json': components['schemas']['Errors.ErrorResponse']
+        }
+      }
+      /** @description 印刷用の時刻表 HTML を返します。 */
+      404: {
+        headers: {
+          [name: string]: unknown
+        }
+        content: {
+          'application/json': components['schemas']['Errors.ErrorResponse']
+        }
+      }
+      /** @description An unexpected error response. */
+      default: {
+        headers: {
+          [name: string]: unknown
+        }
+        content: {
+          'application/json': components['schemas']['Errors.ErrorResponse']
         }
       }
     }